@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/auth"
+)
+
+// hashPassword prints the entry of http_server.users with the hashed
+// password read from stdin:
+//
+//	echo -n secret | main hash-password -algo argon2id admin
+func hashPassword(args []string) int {
+	fs := flag.NewFlagSet("hash-password", flag.ContinueOnError)
+	algorithm := fs.String("algo", auth.HashBcrypt, "hash algorithm: bcrypt or argon2id")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: main hash-password [-algo bcrypt|argon2id] [user] < password")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	fmt.Fprint(os.Stderr, "password: ")
+	password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && password == "" {
+		fmt.Fprintln(os.Stderr, "can't read password:", err)
+		return 1
+	}
+	password = strings.TrimRight(password, "\r\n")
+	if password == "" {
+		fmt.Fprintln(os.Stderr, "password must not be empty")
+		return 1
+	}
+
+	hash, err := auth.HashPassword(password, *algorithm)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if user := fs.Arg(0); user != "" {
+		fmt.Printf("%s:%s\n", user, hash)
+	} else {
+		fmt.Println(hash)
+	}
+	return 0
+}
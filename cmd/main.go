@@ -2,23 +2,34 @@ package main
 
 import (
 	"context"
+	"fmt"
+	stdlog "log"
+	"maps"
 	"os"
 	"os/signal"
+	"slices"
+	"syscall"
 	"time"
 
 	microservice "github.com/ikotiki/go-rest-api-service-subscriptions"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/auth"
 	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/config"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/metrics"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/ratelimit"
 	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/server/http/handler"
 
 	"net/http"
 
 	middleware_logger "github.com/ikotiki/go-rest-api-service-subscriptions/internal/server/http/middleware/logger"
 	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/service"
-	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/storage"
 	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/storage/postgresql"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/tenant"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/tracing"
 	"github.com/ikotiki/go-rest-api-service-subscriptions/logger"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pressly/goose/v3"
 
 	_ "github.com/joho/godotenv/autoload"
 )
@@ -31,10 +42,43 @@ import (
 // @BasePath  /api/v1
 
 // @securityDefinitions.basic  BasicAuth
+
+// @securityDefinitions.apikey  BearerAuth
+// @in                          header
+// @name                        Authorization
+
+// @securityDefinitions.apikey  ApiKeyAuth
+// @in                          header
+// @name                        Authorization
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "hash-password" {
+		os.Exit(hashPassword(os.Args[2:]))
+	}
+
 	cfg := config.MustLoad()
 
-	log := logger.InitLogger(cfg.Env, true)
+	// Env was the log level before log.level, other envs log warnings as before.
+	level := cfg.Log.Level
+	if level == "" {
+		level = cfg.Env
+		if !logger.IsLevel(level) {
+			level = "warn"
+		}
+	}
+	log, closeLogFile, err := logger.Init(logger.Options{
+		Level:          level,
+		Format:         cfg.Log.Format,
+		File:           cfg.Log.File,
+		FileMaxSizeMB:  cfg.Log.MaxSizeMB,
+		FileMaxBackups: cfg.Log.MaxBackups,
+		FileMaxAgeDays: cfg.Log.MaxAgeDays,
+		FileCompress:   cfg.Log.Compress,
+		RedactKeys:     cfg.Log.RedactKeys,
+	})
+	if err != nil {
+		stdlog.Fatalf("cannot init logger: %s", err)
+	}
+	defer closeLogFile()
 	log.Debug().Msg("logger initialized")
 
 	log.Info().Interface("config", cfg).Str("version", "1.0").Msg("starting microservice")
@@ -49,44 +93,151 @@ func main() {
 		Password: cfg.DB.Password,
 		DBName:   cfg.DB.DBname,
 		SSLMode:  cfg.DB.SSLMode,
+
+		IsolationLevel: cfg.DB.IsolationLevel,
+		TxMaxRetries:   cfg.DB.TxMaxRetries,
 	})
 	if err != nil {
 		log.Fatal().Err(err).Msg("error connecting to database")
 	}
 	log.Info().Msg("database connected")
 
-	store := storage.Storage{
-		Subscriptions: postgresql.NewSubscriptionsStore(pgdb),
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Exporter:    cfg.Tracing.Exporter,
+		Endpoint:    cfg.Tracing.Endpoint,
+		File:        cfg.Tracing.File,
+		ServiceName: cfg.Tracing.ServiceName,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("error setting up tracing")
 	}
-	srv := service.NewService(store)
+	log.Info().Str("exporter", cfg.Tracing.Exporter).Msg("tracing set up")
+
+	store := postgresql.NewStorage(pgdb)
+	policy := auth.DefaultPolicy()
+	if len(cfg.HTTPServer.Policy) > 0 {
+		policy.Roles = cfg.HTTPServer.Policy
+	}
+	policy.DefaultRole = cfg.HTTPServer.DefaultRole
+	if err = policy.Validate(); err != nil {
+		log.Fatal().Err(err).Msg("invalid access policy")
+	}
+	srv := service.NewService(store, policy)
 
 	if !cfg.HTTPServer.Debug {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
 	router := gin.New()
+	if err = router.SetTrustedProxies(cfg.HTTPServer.TrustedProxies); err != nil {
+		log.Fatal().Err(err).Msg("invalid trusted proxies")
+	}
+	router.Use(middleware_logger.RequestID())
+	router.Use(handler.ErrorFormatMiddleware(handler.ErrorFormat(cfg.HTTPServer.ErrorFormat)))
+	if cfg.HTTPServer.Metrics {
+		if err = metrics.RegisterDB(pgdb.SQLInstance(), cfg.DB.DBname); err != nil {
+			log.Fatal().Err(err).Msg("error registering database metrics")
+		}
+		router.Use(metrics.Middleware())
+		router.GET("/metrics", gin.WrapH(metrics.Handler()))
+		log.Info().Msg("metrics enabled")
+	}
+	router.Use(tracing.Middleware())
+	router.Use(middleware_logger.Context(log))
 
-	router.NoRoute(func(c *gin.Context) {
-		c.JSON(404, gin.H{"code": "PAGE_NOT_FOUND", "message": "Page not found"})
-	})
+	health := handler.NewHealthHandler(router, handler.DefaultHealthCheckTimeout)
+	health.AddCheck("database", pgdb.Ping)
+	migrations, err := migrationsCheck(pgdb, cfg.DB.MigrationDir)
+	if err != nil {
+		log.Fatal().Err(err).Str("dir", cfg.DB.MigrationDir).Msg("error collecting migrations")
+	}
+	health.AddCheck("migrations", migrations)
+
+	router.NoRoute(handler.NoRoute)
 	middlewares := []gin.HandlerFunc{}
 
+	var authenticators []auth.Authenticator
 	if cfg.HTTPServer.Auth {
 		if len(cfg.HTTPServer.Users) == 0 {
 			log.Fatal().Msg("basic auth enabled but no users provided")
 		}
 		log.Info().Msg("basic auth enabled")
-		middlewares = append(middlewares, gin.BasicAuth(cfg.HTTPServer.GetUsers()))
+		principals, err := makePrincipals(cfg.HTTPServer.Principals)
+		if err != nil {
+			log.Fatal().Err(err).Msg("invalid principals")
+		}
+		users, err := cfg.HTTPServer.GetUsers()
+		if err != nil {
+			log.Fatal().Err(err).Msg("invalid users")
+		}
+		if err = checkPrincipals(users, principals); err != nil {
+			log.Fatal().Err(err).Msg("invalid principals")
+		}
+		authenticators = append(authenticators, auth.NewBasicAuthenticator(users, principals))
+	}
+	if cfg.HTTPServer.JWT.Enabled {
+		jwtAuth, err := makeJWTAuthenticator(cfg.HTTPServer.JWT)
+		if err != nil {
+			log.Fatal().Err(err).Msg("invalid jwt configuration")
+		}
+		log.Info().Str("algorithm", cfg.HTTPServer.JWT.Algorithm).Msg("jwt auth enabled")
+		authenticators = append(authenticators, jwtAuth)
+	}
+	if cfg.HTTPServer.APIKeys {
+		log.Info().Msg("api keys enabled")
+		authenticators = append(authenticators, auth.NewAPIKeyAuthenticator(srv.APIKeys))
+	}
+	if len(authenticators) > 0 {
+		middlewares = append(middlewares, handler.AuthMiddleware(authenticators...))
 	}
 
+	middlewares = append(middlewares, handler.PolicyMiddleware(policy))
+
 	group := router.Group("/api/v1", middlewares...)
 	group.Use(middleware_logger.New(log))
+	group.Use(handler.TenantMiddleware(cfg.HTTPServer.TenantHeader))
+	group.Use(handler.IdempotencyMiddleware(store.IdempotencyKeys, cfg.HTTPServer.IdempotencyTTL, cfg.HTTPServer.IdempotencyLease,
+		cfg.HTTPServer.IdempotencyMaxBodyMB<<20))
 
 	handlers := handler.New(srv)
+	if cfg.HTTPServer.RateLimit.Enabled {
+		limits, err := makeRateLimits(cfg.HTTPServer.RateLimit)
+		if err != nil {
+			log.Fatal().Err(err).Msg("invalid rate limits")
+		}
+		limiter := ratelimit.NewMemoryStore()
+		handlers.WithRateLimits(limiter, limits)
+		log.Info().Int("groups", len(limits)).Msg("rate limits enabled")
+
+		// Drop buckets of clients gone quiet.
+		go func() {
+			ticker := time.NewTicker(10 * time.Minute)
+			defer ticker.Stop()
+			for range ticker.C {
+				n := limiter.Cleanup(time.Hour)
+				log.Debug().Int("deleted", n).Msg("idle rate limit buckets deleted")
+			}
+		}()
+	}
 	handlers.InitRoutes(group)
 
 	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, os.Interrupt)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+
+	// Drop expired idempotency keys.
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for range ticker.C {
+			n, err := store.IdempotencyKeys.DeleteExpired(context.Background())
+			if err != nil {
+				log.Error().Err(err).Msg("error deleting expired idempotency keys")
+				continue
+			}
+			log.Debug().Int64("deleted", n).Msg("expired idempotency keys deleted")
+		}
+	}()
 
 	server := microservice.NewServer(
 		&http.Server{
@@ -108,6 +259,7 @@ func main() {
 	<-quit
 
 	log.Info().Msg("stopping server...")
+	health.Shutdown()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -115,6 +267,122 @@ func main() {
 	if err = server.Shutdown(ctx); err != nil {
 		log.Fatal().Err(err).Msg("can't gracefully shutdown server")
 	}
+	if err = shutdownTracing(ctx); err != nil {
+		log.Error().Err(err).Msg("error flushing spans")
+	}
 
 	log.Info().Msg("server stopped")
 }
+
+// checkPrincipals requires every user to be bound to a principal, once
+// principals are configured, otherwise the user would silently lose access
+// to all data. Without principals every user acts on any user.
+func checkPrincipals(users map[string]string, principals map[string]*auth.Principal) error {
+	if len(principals) == 0 {
+		return nil
+	}
+	for _, name := range slices.Sorted(maps.Keys(users)) {
+		if _, ok := principals[name]; !ok {
+			return fmt.Errorf("user %s has no principal", name)
+		}
+	}
+	return nil
+}
+
+// makePrincipals indexes configured principals by name.
+func makePrincipals(cfgs []config.Principal) (map[string]*auth.Principal, error) {
+	principals := make(map[string]*auth.Principal, len(cfgs))
+	for _, cfg := range cfgs {
+		p := &auth.Principal{Name: cfg.Name, Admin: cfg.Admin || auth.HasAdminRole(cfg.Roles), Roles: cfg.Roles, Tenant: cfg.Tenant}
+		if cfg.UserID != "" {
+			userID, err := uuid.Parse(cfg.UserID)
+			if err != nil {
+				return nil, fmt.Errorf("principal %s: invalid user id: %w", cfg.Name, err)
+			}
+			p.UserID = userID
+		}
+		if cfg.Tenant != "" {
+			if err := tenant.Validate(cfg.Tenant); err != nil {
+				return nil, fmt.Errorf("principal %s: %w", cfg.Name, err)
+			}
+		}
+		principals[cfg.Name] = p
+	}
+	return principals, nil
+}
+
+// migrationsCheck checks, that the database is migrated to the last
+// version of migrations in the dir.
+func migrationsCheck(store *postgresql.SQLStorage, dir string) (handler.HealthCheck, error) {
+	migrations, err := goose.CollectMigrations(dir, 0, goose.MaxVersion)
+	if err != nil {
+		return nil, err
+	}
+	last, err := migrations.Last()
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context) error {
+		version, err := store.MigrationVersion(ctx)
+		if err != nil {
+			return err
+		}
+		if version != last.Version {
+			return fmt.Errorf("database is at version %d, expected %d", version, last.Version)
+		}
+		return nil
+	}, nil
+}
+
+// makeRateLimits checks configured limits of route groups.
+func makeRateLimits(cfg config.RateLimit) (map[string]ratelimit.Limit, error) {
+	limits := make(map[string]ratelimit.Limit, len(cfg.Groups))
+	for group, rule := range cfg.Groups {
+		if !slices.Contains(handler.RouteGroups, group) {
+			return nil, fmt.Errorf("unknown route group %s, expected one of %v", group, handler.RouteGroups)
+		}
+		limit := ratelimit.Limit{Rate: rule.Rate, Burst: rule.Burst}
+		if err := limit.Validate(); err != nil {
+			return nil, fmt.Errorf("route group %s: %w", group, err)
+		}
+		limits[group] = limit
+	}
+	return limits, nil
+}
+
+// makeJWTAuthenticator loads keys of the configured algorithm.
+func makeJWTAuthenticator(cfg config.JWT) (*auth.JWTAuthenticator, error) {
+	keys := auth.NewKeySet()
+	switch {
+	case cfg.Algorithm == auth.AlgHS256:
+		if cfg.Secret == "" {
+			return nil, fmt.Errorf("no secret for %s", cfg.Algorithm)
+		}
+		keys.Add("", []byte(cfg.Secret))
+	case cfg.JWKSFile != "":
+		set, err := auth.LoadJWKSFile(cfg.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		keys = set
+	case cfg.PublicKeyFile != "":
+		key, err := auth.LoadPublicKeyFile(cfg.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		keys.Add("", key)
+	default:
+		return nil, fmt.Errorf("no public key or jwks file for %s", cfg.Algorithm)
+	}
+
+	return auth.NewJWTAuthenticator(auth.JWTConfig{
+		Algorithm:   cfg.Algorithm,
+		Issuer:      cfg.Issuer,
+		Audience:    cfg.Audience,
+		Leeway:      cfg.Leeway,
+		RolesClaim:  cfg.RolesClaim,
+		AdminRole:   cfg.AdminRole,
+		TenantClaim: cfg.TenantClaim,
+	}, keys)
+}
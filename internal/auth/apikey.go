@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// APIKeyVerifier finds the principal of the API key. It returns
+// ErrInvalidCredentials for unknown and revoked keys.
+type APIKeyVerifier interface {
+	Verify(ctx context.Context, key string) (p *Principal, err error)
+}
+
+// APIKeyAuthenticator checks keys passed as "Authorization: ApiKey <key>".
+type APIKeyAuthenticator struct {
+	verifier APIKeyVerifier
+}
+
+func NewAPIKeyAuthenticator(verifier APIKeyVerifier) *APIKeyAuthenticator {
+	return &APIKeyAuthenticator{verifier: verifier}
+}
+
+func (a *APIKeyAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	scheme, key, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "ApiKey") {
+		return nil, ErrNoCredentials
+	}
+	return a.verifier.Verify(r.Context(), strings.TrimSpace(key))
+}
+
+func (a *APIKeyAuthenticator) Challenge() string {
+	return `ApiKey realm="Authorization Required"`
+}
@@ -0,0 +1,87 @@
+// Package auth authenticates requests and describes who makes them.
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrNoCredentials is returned by authenticators, when the request has
+	// no credentials of their kind, so others may try.
+	ErrNoCredentials      = errors.New("no credentials")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrForbidden          = errors.New("access denied")
+)
+
+// Scopes of API keys.
+const (
+	ScopeSubscriptionsRead  = "subscriptions:read"
+	ScopeSubscriptionsWrite = "subscriptions:write"
+	ScopeReportsRead        = "reports:read"
+	ScopeCatalogWrite       = "catalog:write"
+)
+
+// Scopes lists all known scopes.
+var Scopes = []string{ScopeSubscriptionsRead, ScopeSubscriptionsWrite, ScopeReportsRead, ScopeCatalogWrite}
+
+// Principal is the authenticated client. Admins act on behalf of any user,
+// auditors read data of any user, others only act on behalf of the bound
+// user. Scopes limit principals of API keys, other principals have nil
+// scopes and aren't limited.
+type Principal struct {
+	Name   string    `json:"name"`
+	UserID uuid.UUID `json:"user_id"`
+	Admin  bool      `json:"admin"`
+	Roles  []string  `json:"roles,omitempty"`
+	Scopes []string  `json:"scopes,omitempty"`
+	// Tenant binds the principal to the tenant, others choose it per request.
+	Tenant string `json:"tenant,omitempty"`
+}
+
+// CanActAs reports whether the principal may access data of the user.
+func (p *Principal) CanActAs(userID uuid.UUID) bool {
+	return p.Admin || (p.UserID != uuid.Nil && p.UserID == userID)
+}
+
+// ReadsAnyUser reports whether the principal may read data of all users.
+func (p *Principal) ReadsAnyUser() bool {
+	return p.Admin || slices.Contains(p.Roles, RoleAuditor)
+}
+
+// CanReadAs reports whether the principal may read data of the user.
+func (p *Principal) CanReadAs(userID uuid.UUID) bool {
+	return p.ReadsAnyUser() || p.CanActAs(userID)
+}
+
+// HasScope reports whether the principal is allowed the scope.
+func (p *Principal) HasScope(scope string) bool {
+	return p.Scopes == nil || slices.Contains(p.Scopes, scope)
+}
+
+// Authenticator finds out the principal of the request.
+type Authenticator interface {
+	// Authenticate returns ErrNoCredentials, if there are no credentials
+	// of the supported kind, and ErrInvalidCredentials, if they are wrong.
+	Authenticate(r *http.Request) (p *Principal, err error)
+	// Challenge is a value of WWW-Authenticate header for the scheme.
+	Challenge() string
+}
+
+type ctxKey struct{}
+
+// WithPrincipal returns the context carrying the principal.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, ctxKey{}, p)
+}
+
+// FromContext returns the principal of the context. There is none, when
+// authentication is disabled.
+func FromContext(ctx context.Context) (p *Principal, ok bool) {
+	p, ok = ctx.Value(ctxKey{}).(*Principal)
+	return p, ok && p != nil
+}
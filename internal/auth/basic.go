@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"net/http"
+)
+
+// BasicAuthenticator checks HTTP Basic credentials against configured users.
+type BasicAuthenticator struct {
+	users      map[string]string
+	principals map[string]*Principal
+}
+
+// NewBasicAuthenticator returns the authenticator of users given as
+// name-password pairs, passwords are bcrypt or argon2id hashes or plain
+// text. Users are bound to principals by name, users without principal are
+// authenticated, but have access to no user's data. Without principals
+// ownership is off and every user is an admin, as before users were bound
+// to principals.
+func NewBasicAuthenticator(users map[string]string, principals map[string]*Principal) *BasicAuthenticator {
+	return &BasicAuthenticator{users: users, principals: principals}
+}
+
+func (a *BasicAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	name, password, ok := r.BasicAuth()
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+
+	stored, found := a.users[name]
+	if !found {
+		// Check anyway, so unknown users take the same time.
+		CheckPassword(dummyHash(), password)
+		return nil, ErrInvalidCredentials
+	}
+	if !CheckPassword(stored, password) {
+		return nil, ErrInvalidCredentials
+	}
+
+	if len(a.principals) == 0 {
+		return &Principal{Name: name, Admin: true}, nil
+	}
+	if p, ok := a.principals[name]; ok {
+		return p, nil
+	}
+	return &Principal{Name: name}, nil
+}
+
+func (a *BasicAuthenticator) Challenge() string {
+	return `Basic realm="Authorization Required"`
+}
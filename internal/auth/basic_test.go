@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBasicAuthenticator_Authenticate(t *testing.T) {
+	userID := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
+	hash, err := HashPassword("hashed", HashBcrypt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := NewBasicAuthenticator(
+		map[string]string{"admin": "secret", "alice": "pass", "bob": "pass", "carol": hash},
+		map[string]*Principal{
+			"admin": {Name: "admin", Admin: true},
+			"alice": {Name: "alice", UserID: userID},
+		},
+	)
+
+	tests := []struct {
+		name     string
+		user     string
+		password string
+		noAuth   bool
+		want     *Principal
+		wantErr  error
+	}{
+		{name: "Ok (admin)", user: "admin", password: "secret", want: &Principal{Name: "admin", Admin: true}},
+		{name: "Ok (user)", user: "alice", password: "pass", want: &Principal{Name: "alice", UserID: userID}},
+		{name: "Ok (no principal)", user: "bob", password: "pass", want: &Principal{Name: "bob"}},
+		{name: "Ok (hashed)", user: "carol", password: "hashed", want: &Principal{Name: "carol"}},
+		{name: "Error (password)", user: "alice", password: "secret", wantErr: ErrInvalidCredentials},
+		{name: "Error (hashed password)", user: "carol", password: hash, wantErr: ErrInvalidCredentials},
+		{name: "Error (unknown)", user: "eve", password: "", wantErr: ErrInvalidCredentials},
+		{name: "Error (no credentials)", noAuth: true, wantErr: ErrNoCredentials},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			if !tt.noAuth {
+				r.SetBasicAuth(tt.user, tt.password)
+			}
+
+			got, err := a.Authenticate(r)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestBasicAuthenticator_withoutPrincipals(t *testing.T) {
+	a := NewBasicAuthenticator(map[string]string{"bob": "pass"}, nil)
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.SetBasicAuth("bob", "pass")
+	got, err := a.Authenticate(r)
+	assert.NoError(t, err)
+	assert.Equal(t, &Principal{Name: "bob", Admin: true}, got)
+}
+
+func TestPrincipal_CanActAs(t *testing.T) {
+	userID := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
+	other := uuid.MustParse("123e4567-e89b-12d3-a456-426614174001")
+
+	assert.True(t, (&Principal{Admin: true}).CanActAs(other))
+	assert.True(t, (&Principal{UserID: userID}).CanActAs(userID))
+	assert.False(t, (&Principal{UserID: userID}).CanActAs(other))
+	assert.False(t, (&Principal{}).CanActAs(uuid.Nil))
+	assert.False(t, (&Principal{Roles: []string{RoleAuditor}}).CanActAs(other))
+}
+
+func TestPrincipal_CanReadAs(t *testing.T) {
+	userID := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
+	other := uuid.MustParse("123e4567-e89b-12d3-a456-426614174001")
+
+	assert.True(t, (&Principal{Admin: true}).CanReadAs(other))
+	assert.True(t, (&Principal{Roles: []string{RoleAuditor}}).CanReadAs(other))
+	assert.True(t, (&Principal{UserID: userID}).CanReadAs(userID))
+	assert.False(t, (&Principal{UserID: userID, Roles: []string{RoleEditor}}).CanReadAs(other))
+}
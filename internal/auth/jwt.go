@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// Supported signing algorithms of JWT.
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// JWTConfig describes accepted tokens.
+type JWTConfig struct {
+	// Algorithm is the only accepted signing algorithm.
+	Algorithm string
+	// Issuer and Audience are checked, if they are set.
+	Issuer   string
+	Audience string
+	// Leeway allows clock skew in checks of expiration.
+	Leeway time.Duration
+	// RolesClaim holds roles of the subject, as a list or a space separated string.
+	RolesClaim string
+	// AdminRole makes the subject an admin.
+	AdminRole string
+	// TenantClaim binds the subject to the tenant.
+	TenantClaim string
+}
+
+// JWTAuthenticator checks bearer tokens signed by locally known keys.
+// The subject of the token is the principal name, and it's bound to
+// the user, if it's a UUID.
+type JWTAuthenticator struct {
+	cfg    JWTConfig
+	keys   *KeySet
+	parser *jwt.Parser
+}
+
+func NewJWTAuthenticator(cfg JWTConfig, keys *KeySet) (*JWTAuthenticator, error) {
+	switch cfg.Algorithm {
+	case AlgHS256, AlgRS256, AlgEdDSA:
+	default:
+		return nil, fmt.Errorf("unsupported jwt algorithm %q", cfg.Algorithm)
+	}
+	if keys == nil || keys.Len() == 0 {
+		return nil, fmt.Errorf("no keys to verify jwt")
+	}
+	if cfg.RolesClaim == "" {
+		cfg.RolesClaim = "roles"
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{cfg.Algorithm}),
+		jwt.WithLeeway(cfg.Leeway),
+		jwt.WithExpirationRequired(),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+
+	return &JWTAuthenticator{cfg: cfg, keys: keys, parser: jwt.NewParser(opts...)}, nil
+}
+
+func (a *JWTAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return nil, ErrNoCredentials
+	}
+
+	claims := jwt.MapClaims{}
+	if _, err := a.parser.ParseWithClaims(strings.TrimSpace(token), claims, a.key); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCredentials, err)
+	}
+
+	subject, err := claims.GetSubject()
+	if err != nil || subject == "" {
+		return nil, fmt.Errorf("%w: no subject", ErrInvalidCredentials)
+	}
+
+	p := &Principal{Name: subject, Roles: a.roles(claims)}
+	p.Admin = (a.cfg.AdminRole != "" && slices.Contains(p.Roles, a.cfg.AdminRole)) || HasAdminRole(p.Roles)
+	if a.cfg.TenantClaim != "" {
+		p.Tenant, _ = claims[a.cfg.TenantClaim].(string)
+	}
+	if userID, err := uuid.Parse(subject); err == nil {
+		p.UserID = userID
+	}
+	return p, nil
+}
+
+func (a *JWTAuthenticator) Challenge() string {
+	return `Bearer realm="Authorization Required"`
+}
+
+// key finds the key of the token by its kid header.
+func (a *JWTAuthenticator) key(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	return a.keys.Get(kid)
+}
+
+func (a *JWTAuthenticator) roles(claims jwt.MapClaims) []string {
+	switch v := claims[a.cfg.RolesClaim].(type) {
+	case string:
+		return strings.Fields(v)
+	case []any:
+		roles := make([]string, 0, len(v))
+		for _, role := range v {
+			if s, ok := role.(string); ok {
+				roles = append(roles, s)
+			}
+		}
+		return roles
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWTAuthenticator_Authenticate(t *testing.T) {
+	userID := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
+	secret := []byte("secret")
+
+	keys := NewKeySet()
+	keys.Add("", secret)
+	a, err := NewJWTAuthenticator(JWTConfig{
+		Algorithm: AlgHS256,
+		Issuer:    "issuer",
+		AdminRole: "admin",
+	}, keys)
+	require.NoError(t, err)
+
+	sign := func(method jwt.SigningMethod, key any, claims jwt.MapClaims) string {
+		s, err := jwt.NewWithClaims(method, claims).SignedString(key)
+		require.NoError(t, err)
+		return s
+	}
+	claims := func(sub string, roles any) jwt.MapClaims {
+		return jwt.MapClaims{
+			"sub":   sub,
+			"iss":   "issuer",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"roles": roles,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		header  string
+		want    *Principal
+		wantErr error
+	}{
+		{
+			name:   "Ok (user)",
+			header: "Bearer " + sign(jwt.SigningMethodHS256, secret, claims(userID.String(), []string{"user"})),
+			want:   &Principal{Name: userID.String(), UserID: userID, Roles: []string{"user"}},
+		},
+		{
+			name:   "Ok (admin)",
+			header: "Bearer " + sign(jwt.SigningMethodHS256, secret, claims("ops", "user admin")),
+			want:   &Principal{Name: "ops", Admin: true, Roles: []string{"user", "admin"}},
+		},
+		{
+			name:    "Error (signature)",
+			header:  "Bearer " + sign(jwt.SigningMethodHS256, []byte("other"), claims("ops", nil)),
+			wantErr: ErrInvalidCredentials,
+		},
+		{
+			name:    "Error (algorithm)",
+			header:  "Bearer " + sign(jwt.SigningMethodHS384, secret, claims("ops", nil)),
+			wantErr: ErrInvalidCredentials,
+		},
+		{
+			name: "Error (expired)",
+			header: "Bearer " + sign(jwt.SigningMethodHS256, secret, jwt.MapClaims{
+				"sub": "ops", "iss": "issuer", "exp": time.Now().Add(-time.Hour).Unix(),
+			}),
+			wantErr: ErrInvalidCredentials,
+		},
+		{
+			name: "Error (issuer)",
+			header: "Bearer " + sign(jwt.SigningMethodHS256, secret, jwt.MapClaims{
+				"sub": "ops", "iss": "other", "exp": time.Now().Add(time.Hour).Unix(),
+			}),
+			wantErr: ErrInvalidCredentials,
+		},
+		{name: "Error (no credentials)", wantErr: ErrNoCredentials},
+		{name: "Error (basic)", header: "Basic YWRtaW46c2VjcmV0", wantErr: ErrNoCredentials},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+
+			got, err := a.Authenticate(r)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestJWTAuthenticator_JWKS(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	jwks := `{"keys": [{"kty": "OKP", "crv": "Ed25519", "kid": "k1", "x": "` + base64.RawURLEncoding.EncodeToString(pub) + `"}]}`
+	require.NoError(t, os.WriteFile(path, []byte(jwks), 0o600))
+
+	keys, err := LoadJWKSFile(path)
+	require.NoError(t, err)
+	a, err := NewJWTAuthenticator(JWTConfig{Algorithm: AlgEdDSA}, keys)
+	require.NoError(t, err)
+
+	sign := func(kid string) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.MapClaims{
+			"sub": "ops",
+			"exp": time.Now().Add(time.Hour).Unix(),
+		})
+		token.Header["kid"] = kid
+		s, err := token.SignedString(priv)
+		require.NoError(t, err)
+		return s
+	}
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Authorization", "Bearer "+sign("k1"))
+	got, err := a.Authenticate(r)
+	require.NoError(t, err)
+	assert.Equal(t, "ops", got.Name)
+
+	r.Header.Set("Authorization", "Bearer "+sign("k2"))
+	_, err = a.Authenticate(r)
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+}
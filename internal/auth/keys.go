@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+var ErrNoSuchKey = errors.New("no key to verify the token")
+
+// KeySet holds keys verifying tokens by their ids. The key without id is
+// used for tokens without kid header.
+type KeySet struct {
+	keys map[string]any
+}
+
+func NewKeySet() *KeySet {
+	return &KeySet{keys: map[string]any{}}
+}
+
+// Add adds the key: []byte for HS256, *rsa.PublicKey or ed25519.PublicKey.
+func (s *KeySet) Add(kid string, key any) {
+	s.keys[kid] = key
+}
+
+func (s *KeySet) Len() int {
+	return len(s.keys)
+}
+
+// Get returns the key by id. If there is a single key, it's used for
+// tokens without id.
+func (s *KeySet) Get(kid string) (any, error) {
+	if key, ok := s.keys[kid]; ok {
+		return key, nil
+	}
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, nil
+		}
+	}
+	return nil, ErrNoSuchKey
+}
+
+// LoadPublicKeyFile reads a PEM encoded RSA or Ed25519 public key.
+func LoadPublicKeyFile(path string) (any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data", path)
+	}
+
+	if block.Type == "RSA PUBLIC KEY" {
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	switch key.(type) {
+	case *rsa.PublicKey, ed25519.PublicKey:
+		return key, nil
+	}
+	return nil, fmt.Errorf("%s: unsupported key type %T", path, key)
+}
+
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	K   string `json:"k"`
+}
+
+// LoadJWKSFile reads RSA, Ed25519 and symmetric keys of the JSON Web Key Set.
+func LoadJWKSFile(path string) (*KeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var jwks struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err = json.Unmarshal(data, &jwks); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	set := NewKeySet()
+	for _, k := range jwks.Keys {
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("%s: key %q: %w", path, k.Kid, err)
+		}
+		set.Add(k.Kid, key)
+	}
+	return set, nil
+}
+
+func (k *jwk) publicKey() (any, error) {
+	decode := base64.RawURLEncoding.DecodeString
+
+	switch k.Kty {
+	case "RSA":
+		n, err := decode(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key size")
+		}
+		return ed25519.PublicKey(x), nil
+	case "oct":
+		return decode(k.K)
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
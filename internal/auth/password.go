@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Algorithms of password hashes.
+const (
+	HashBcrypt   = "bcrypt"
+	HashArgon2id = "argon2id"
+)
+
+// Parameters of new argon2id hashes, as recommended by RFC 9106.
+const (
+	argon2Time    = 1
+	argon2Memory  = 64 * 1024
+	argon2Threads = 4
+	argon2KeyLen  = 32
+	argon2SaltLen = 16
+)
+
+var ErrInvalidHash = errors.New("invalid password hash")
+
+// HashPassword hashes the password by the algorithm. Argon2id hashes are
+// encoded in PHC string format: $argon2id$v=19$m=65536,t=1,p=4$salt$key.
+func HashPassword(password, algorithm string) (string, error) {
+	switch algorithm {
+	case HashBcrypt:
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		return string(hash), err
+	case HashArgon2id:
+		salt := make([]byte, argon2SaltLen)
+		if _, err := rand.Read(salt); err != nil {
+			return "", err
+		}
+		key := argon2.IDKey([]byte(password), salt, argon2Time, argon2Memory, argon2Threads, argon2KeyLen)
+		return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, argon2Memory, argon2Time, argon2Threads,
+			base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+	}
+	return "", fmt.Errorf("unsupported hash algorithm %q", algorithm)
+}
+
+// IsPasswordHash reports whether the stored password is a bcrypt or argon2id
+// hash rather than a plain text.
+func IsPasswordHash(stored string) bool {
+	return isBcrypt(stored) || strings.HasPrefix(stored, "$argon2id$")
+}
+
+// ValidatePasswordHash checks the format of the hash, plain text passwords
+// are valid.
+func ValidatePasswordHash(stored string) error {
+	switch {
+	case isBcrypt(stored):
+		if _, err := bcrypt.Cost([]byte(stored)); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidHash, err)
+		}
+	case strings.HasPrefix(stored, "$argon2id$"):
+		if _, err := parseArgon2id(stored); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckPassword compares the password with the stored hash or plain text
+// in constant time.
+func CheckPassword(stored, password string) bool {
+	switch {
+	case isBcrypt(stored):
+		return bcrypt.CompareHashAndPassword([]byte(stored), []byte(password)) == nil
+	case strings.HasPrefix(stored, "$argon2id$"):
+		h, err := parseArgon2id(stored)
+		if err != nil {
+			return false
+		}
+		key := argon2.IDKey([]byte(password), h.salt, h.time, h.memory, h.threads, uint32(len(h.key)))
+		return subtle.ConstantTimeCompare(key, h.key) == 1
+	}
+	return subtle.ConstantTimeCompare([]byte(password), []byte(stored)) == 1
+}
+
+// dummyHash is checked for unknown users, so they take the same time as known ones.
+var dummyHash = sync.OnceValue(func() string {
+	hash, _ := HashPassword("", HashBcrypt)
+	return hash
+})
+
+func isBcrypt(stored string) bool {
+	return strings.HasPrefix(stored, "$2a$") || strings.HasPrefix(stored, "$2b$") || strings.HasPrefix(stored, "$2y$")
+}
+
+type argon2Hash struct {
+	time, memory uint32
+	threads      uint8
+	salt, key    []byte
+}
+
+func parseArgon2id(stored string) (*argon2Hash, error) {
+	// "", "argon2id", "v=19", "m=65536,t=1,p=4", salt, key
+	parts := strings.Split(stored, "$")
+	if len(parts) != 6 {
+		return nil, fmt.Errorf("%w: argon2id hash should have 6 parts", ErrInvalidHash)
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, fmt.Errorf("%w: unsupported argon2 version %q", ErrInvalidHash, parts[2])
+	}
+
+	h := &argon2Hash{}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &h.memory, &h.time, &h.threads); err != nil {
+		return nil, fmt.Errorf("%w: invalid argon2 parameters %q", ErrInvalidHash, parts[3])
+	}
+	if h.time == 0 || h.memory == 0 || h.threads == 0 {
+		return nil, fmt.Errorf("%w: argon2 parameters must be positive", ErrInvalidHash)
+	}
+
+	var err error
+	if h.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return nil, fmt.Errorf("%w: invalid argon2 salt: %w", ErrInvalidHash, err)
+	}
+	if h.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(h.key) == 0 {
+		return nil, fmt.Errorf("%w: invalid argon2 key", ErrInvalidHash)
+	}
+	return h, nil
+}
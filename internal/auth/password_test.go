@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckPassword(t *testing.T) {
+	for _, algorithm := range []string{HashBcrypt, HashArgon2id} {
+		t.Run(algorithm, func(t *testing.T) {
+			hash, err := HashPassword("secret", algorithm)
+			require.NoError(t, err)
+
+			assert.True(t, IsPasswordHash(hash))
+			assert.NoError(t, ValidatePasswordHash(hash))
+			assert.True(t, CheckPassword(hash, "secret"))
+			assert.False(t, CheckPassword(hash, "wrong"))
+		})
+	}
+
+	t.Run("plain", func(t *testing.T) {
+		assert.False(t, IsPasswordHash("secret"))
+		assert.True(t, CheckPassword("secret", "secret"))
+		assert.False(t, CheckPassword("secret", "wrong"))
+	})
+}
+
+func TestValidatePasswordHash(t *testing.T) {
+	tests := []struct {
+		name    string
+		stored  string
+		wantErr bool
+	}{
+		{name: "Ok (plain)", stored: "secret"},
+		{name: "Error (bcrypt)", stored: "$2a$10$short", wantErr: true},
+		{name: "Error (argon2id parts)", stored: "$argon2id$v=19$m=65536,t=1,p=4$c2FsdA", wantErr: true},
+		{name: "Error (argon2id version)", stored: "$argon2id$v=16$m=65536,t=1,p=4$c2FsdA$a2V5", wantErr: true},
+		{name: "Error (argon2id params)", stored: "$argon2id$v=19$m=0,t=1,p=4$c2FsdA$a2V5", wantErr: true},
+		{name: "Error (argon2id salt)", stored: "$argon2id$v=19$m=65536,t=1,p=4$!!$a2V5", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePasswordHash(tt.stored)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidHash)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
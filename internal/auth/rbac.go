@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"fmt"
+	"path"
+	"slices"
+)
+
+// Roles of principals.
+const (
+	RoleViewer  = "viewer"
+	RoleEditor  = "editor"
+	RoleAuditor = "auditor"
+	RoleAdmin   = "admin"
+)
+
+// Operations allowed to roles by the policy.
+const (
+	OpSubscriptionRead  = "subscription.read"
+	OpSubscriptionWrite = "subscription.write"
+	OpReportRead        = "report.read"
+	OpCatalogRead       = "catalog.read"
+	OpCatalogWrite      = "catalog.write"
+	OpCategoryRead      = "category.read"
+	OpCategoryWrite     = "category.write"
+	OpAPIKeysRead       = "admin.apikeys.read"
+	OpAPIKeysWrite      = "admin.apikeys.write"
+	OpLogLevelRead      = "admin.loglevel.read"
+	OpLogLevelWrite     = "admin.loglevel.write"
+)
+
+// Policy maps roles to operations. Operations are matched by patterns,
+// where "*" stands for any part of the name, e.g. "admin.*".
+type Policy struct {
+	Roles map[string][]string
+	// DefaultRole is given to principals without roles, admins get RoleAdmin.
+	DefaultRole string
+}
+
+// DefaultPolicy lets viewers read, editors also change subscriptions,
+// auditors read everything including admin data, and admins do anything.
+// The catalog and categories are shared by all users, so only admins
+// change them.
+func DefaultPolicy() *Policy {
+	return &Policy{
+		Roles: map[string][]string{
+			RoleViewer:  {OpSubscriptionRead, OpReportRead, OpCatalogRead, OpCategoryRead},
+			RoleEditor:  {OpSubscriptionRead, OpSubscriptionWrite, OpReportRead, OpCatalogRead, OpCategoryRead},
+			RoleAuditor: {OpSubscriptionRead, OpReportRead, OpCatalogRead, OpCategoryRead, "admin.*.read"},
+			RoleAdmin:   {"subscription.*", "report.*", "catalog.*", "category.*", "admin.*"},
+		},
+		DefaultRole: RoleEditor,
+	}
+}
+
+// HasRole reports whether the policy defines the role.
+func (p *Policy) HasRole(role string) bool {
+	_, ok := p.Roles[role]
+	return ok
+}
+
+// Validate checks patterns of operations.
+func (p *Policy) Validate() error {
+	for role, patterns := range p.Roles {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("role %s: operation %q: %w", role, pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Allows reports whether any role of the principal allows the operation.
+func (p *Policy) Allows(pr *Principal, op string) bool {
+	for _, role := range p.roles(pr) {
+		for _, pattern := range p.Roles[role] {
+			if ok, _ := path.Match(pattern, op); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (p *Policy) roles(pr *Principal) []string {
+	switch {
+	case len(pr.Roles) > 0:
+		return pr.Roles
+	case pr.Admin:
+		return []string{RoleAdmin}
+	case p.DefaultRole != "":
+		return []string{p.DefaultRole}
+	}
+	return nil
+}
+
+// HasAdminRole reports whether the roles make the principal an admin.
+// Auditors read data of all users, but aren't admins, see
+// Principal.ReadsAnyUser.
+func HasAdminRole(roles []string) bool {
+	return slices.Contains(roles, RoleAdmin)
+}
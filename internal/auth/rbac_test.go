@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicy_Allows(t *testing.T) {
+	policy := DefaultPolicy()
+
+	tests := []struct {
+		name      string
+		principal *Principal
+		op        string
+		want      bool
+	}{
+		{name: "Ok (viewer reads)", principal: &Principal{Roles: []string{RoleViewer}}, op: OpSubscriptionRead, want: true},
+		{name: "Ok (editor writes)", principal: &Principal{Roles: []string{RoleEditor}}, op: OpSubscriptionWrite, want: true},
+		{name: "Ok (auditor reads keys)", principal: &Principal{Roles: []string{RoleAuditor}}, op: OpAPIKeysRead, want: true},
+		{name: "Ok (admin)", principal: &Principal{Roles: []string{RoleAdmin}}, op: OpAPIKeysWrite, want: true},
+		{name: "Ok (admin without roles)", principal: &Principal{Admin: true}, op: OpAPIKeysWrite, want: true},
+		{name: "Ok (default role)", principal: &Principal{}, op: OpSubscriptionWrite, want: true},
+		{name: "Ok (any of roles)", principal: &Principal{Roles: []string{"unknown", RoleViewer}}, op: OpReportRead, want: true},
+		{name: "Ok (editor reads catalog)", principal: &Principal{Roles: []string{RoleEditor}}, op: OpCatalogRead, want: true},
+		{name: "Ok (admin writes categories)", principal: &Principal{Admin: true}, op: OpCategoryWrite, want: true},
+		{name: "Denied (viewer writes)", principal: &Principal{Roles: []string{RoleViewer}}, op: OpSubscriptionWrite},
+		{name: "Denied (auditor writes keys)", principal: &Principal{Roles: []string{RoleAuditor}}, op: OpAPIKeysWrite},
+		{name: "Denied (default role)", principal: &Principal{}, op: OpAPIKeysRead},
+		{name: "Denied (editor writes catalog)", principal: &Principal{Roles: []string{RoleEditor}}, op: OpCatalogWrite},
+		{name: "Denied (auditor writes categories)", principal: &Principal{Roles: []string{RoleAuditor}}, op: OpCategoryWrite},
+		{name: "Denied (unknown role)", principal: &Principal{Roles: []string{"unknown"}}, op: OpSubscriptionRead},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, policy.Allows(tt.principal, tt.op))
+		})
+	}
+}
+
+func TestPolicy_Validate(t *testing.T) {
+	assert.NoError(t, DefaultPolicy().Validate())
+	assert.Error(t, (&Policy{Roles: map[string][]string{RoleViewer: {"subscription.["}}}).Validate())
+}
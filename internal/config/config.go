@@ -1,12 +1,15 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"strings"
 	"time"
 
 	"log"
 
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/auth"
+
 	"github.com/ilyakaznacheev/cleanenv"
 )
 
@@ -14,6 +17,34 @@ type Config struct {
 	Env        string     `yaml:"env" env-default:"local"`
 	DB         DB         `yaml:"db" env-required:"true"`
 	HTTPServer HTTPServer `yaml:"http_server" env-required:"true"`
+	Tracing    Tracing    `yaml:"tracing"`
+	Log        Log        `yaml:"log"`
+}
+
+// Log configures the global logger. Level falls back to Env, format is
+// "console" for people or "json" for collectors. With File logs are also
+// written as JSON to the file rotated by size and age. Values of fields
+// named by RedactKeys, along with built-in ones like password, are masked.
+type Log struct {
+	Level      string   `yaml:"level" env:"LOG_LEVEL"`
+	Format     string   `yaml:"format" env:"LOG_FORMAT" env-default:"console"`
+	File       string   `yaml:"file" env:"LOG_FILE"`
+	MaxSizeMB  int      `yaml:"max_size_mb" env-default:"100"`
+	MaxBackups int      `yaml:"max_backups" env-default:"5"`
+	MaxAgeDays int      `yaml:"max_age_days" env-default:"30"`
+	Compress   bool     `yaml:"compress" env-default:"false"`
+	RedactKeys []string `yaml:"redact_keys"`
+}
+
+// Tracing chooses the exporter of spans: "none", "otlp" to the collector
+// at the endpoint URL (OTEL_EXPORTER_OTLP_* variables by default),
+// "stdout" or "file" writing spans as JSON lines.
+type Tracing struct {
+	Exporter    string  `yaml:"exporter" env:"TRACING_EXPORTER" env-default:"none"`
+	Endpoint    string  `yaml:"endpoint" env:"TRACING_ENDPOINT"`
+	File        string  `yaml:"file" env-default:"traces.json"`
+	ServiceName string  `yaml:"service_name" env-default:"subscriptions"`
+	SampleRatio float64 `yaml:"sample_ratio" env-default:"1"`
 }
 
 type DB struct {
@@ -25,6 +56,10 @@ type DB struct {
 	Port         string `yaml:"port" env-required:"true"`
 	DBname       string `yaml:"dbname" env-required:"true"`
 	SSLMode      string `yaml:"sslmode" env-default:"disable"`
+	// Isolation level of multi-statement transactions and limit of their
+	// reruns after serialization failures.
+	IsolationLevel string `yaml:"isolation_level" env-default:"read committed"`
+	TxMaxRetries   int    `yaml:"tx_max_retries" env-default:"3"`
 }
 
 type HTTPServer struct {
@@ -35,15 +70,103 @@ type HTTPServer struct {
 	Timeout     time.Duration `yaml:"timeout" env-default:"4s"`
 	IdleTimeout time.Duration `yaml:"idle_timeout" env-default:"30s"`
 	Users       []string      `yaml:"users"`
+	// TrustedProxies lists addresses or CIDRs of proxies, whose
+	// X-Forwarded-For headers give client IPs. None are trusted by default,
+	// so clients can't choose their IPs seen by rate limits and logs.
+	TrustedProxies []string `yaml:"trusted_proxies"`
+	// Principals bind authenticated users to user ids of subscriptions.
+	Principals []Principal `yaml:"principals"`
+	// ErrorFormat is a default shape of error responses: "legacy" envelope
+	// or RFC 7807 "problem". Clients may ask for problems by Accept header.
+	ErrorFormat string `yaml:"error_format" env:"HTTP_ERROR_FORMAT" env-default:"legacy"`
+	// IdempotencyTTL is how long responses to requests with Idempotency-Key are kept.
+	IdempotencyTTL time.Duration `yaml:"idempotency_ttl" env-default:"24h"`
+	// IdempotencyLease is how long a request in progress holds its key, it
+	// should exceed the longest request.
+	IdempotencyLease time.Duration `yaml:"idempotency_lease" env-default:"1m"`
+	// IdempotencyMaxBodyMB limits bodies of requests with Idempotency-Key,
+	// which are read to memory.
+	IdempotencyMaxBodyMB int64 `yaml:"idempotency_max_body_mb" env-default:"16"`
+	// Policy maps roles to patterns of allowed operations, the built-in
+	// policy is used, if it's empty. DefaultRole is given to principals
+	// without roles, admins get the admin role.
+	Policy      map[string][]string `yaml:"policy"`
+	DefaultRole string              `yaml:"default_role" env-default:"editor"`
+	// TenantHeader chooses the tenant of principals not bound to one.
+	TenantHeader string `yaml:"tenant_header" env-default:"X-Tenant-ID"`
+	// APIKeys enables keys issued by admins alongside Basic Auth.
+	APIKeys bool `yaml:"api_keys" env-default:"false"`
+	// JWT enables bearer tokens alongside Basic Auth.
+	JWT JWT `yaml:"jwt"`
+	// Metrics exposes Prometheus metrics at /metrics without authentication.
+	Metrics bool `yaml:"metrics" env-default:"false"`
+	// RateLimit limits requests of clients to route groups.
+	RateLimit RateLimit `yaml:"rate_limit"`
+}
+
+// RateLimit maps route groups ("subscriptions", "reports", "admin") to
+// token buckets of every client: burst requests at once refilled by rate
+// requests per second. Groups missing here aren't limited.
+type RateLimit struct {
+	Enabled bool                     `yaml:"enabled" env:"RATE_LIMIT_ENABLED" env-default:"false"`
+	Groups  map[string]RateLimitRule `yaml:"groups"`
 }
 
-func (s HTTPServer) GetUsers() map[string]string {
+type RateLimitRule struct {
+	Rate  float64 `yaml:"rate"`
+	Burst int     `yaml:"burst"`
+}
+
+// JWT describes accepted bearer tokens and keys verifying them. Keys are
+// loaded from disk: a PEM public key for RS256 and EdDSA, or a JWKS file
+// selecting keys by kid. HS256 uses the shared secret.
+type JWT struct {
+	Enabled       bool          `yaml:"enabled" env:"JWT_ENABLED" env-default:"false"`
+	Algorithm     string        `yaml:"algorithm" env-default:"RS256"`
+	Secret        string        `yaml:"secret" env:"JWT_SECRET"`
+	PublicKeyFile string        `yaml:"public_key_file"`
+	JWKSFile      string        `yaml:"jwks_file"`
+	Issuer        string        `yaml:"issuer"`
+	Audience      string        `yaml:"audience"`
+	Leeway        time.Duration `yaml:"leeway" env-default:"30s"`
+	RolesClaim    string        `yaml:"roles_claim" env-default:"roles"`
+	AdminRole     string        `yaml:"admin_role" env-default:"admin"`
+	TenantClaim   string        `yaml:"tenant_claim" env-default:"tenant"`
+}
+
+// Principal is an authenticated client acting on behalf of the user
+// with UserID, or on behalf of any user, if it's an admin. Roles limit
+// operations of the principal by the policy.
+type Principal struct {
+	Name   string   `yaml:"name"`
+	UserID string   `yaml:"user_id"`
+	Admin  bool     `yaml:"admin"`
+	Roles  []string `yaml:"roles"`
+	Tenant string   `yaml:"tenant"`
+}
+
+// GetUsers parses "name:password" entries of users. Passwords are bcrypt
+// ("admin:$2a$...") or argon2id ("admin:$argon2id$...") hashes, generated
+// by "main hash-password", or plain text.
+func (s HTTPServer) GetUsers() (map[string]string, error) {
 	users := make(map[string]string, len(s.Users))
-	for _, user := range s.Users {
-		pair := strings.Split(user, ":")
-		users[pair[0]] = pair[1]
+	for i, user := range s.Users {
+		name, password, ok := strings.Cut(user, ":")
+		if !ok {
+			return nil, fmt.Errorf("user #%d: expected name:password", i+1)
+		}
+		if name == "" || password == "" {
+			return nil, fmt.Errorf("user #%d: name and password must not be empty", i+1)
+		}
+		if _, ok := users[name]; ok {
+			return nil, fmt.Errorf("user %s: duplicate", name)
+		}
+		if err := auth.ValidatePasswordHash(password); err != nil {
+			return nil, fmt.Errorf("user %s: %w", name, err)
+		}
+		users[name] = password
 	}
-	return users
+	return users, nil
 }
 
 // type ServiceUser struct {
@@ -74,5 +197,9 @@ func MustLoad() *Config {
 		log.Fatalf("cannot read env: %s", err)
 	}
 
+	if _, err := cfg.HTTPServer.GetUsers(); err != nil {
+		log.Fatalf("invalid http_server.users: %s", err)
+	}
+
 	return &cfg
 }
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPServer_GetUsers(t *testing.T) {
+	tests := []struct {
+		name    string
+		users   []string
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name:  "Ok",
+			users: []string{"admin:secret", "alice:pass:word", "bob:$2a$10$Eyqm0/qCSkaq4OxJb06e0ei5gKRvCjNud/HVkQ5ksdxTgPxmoAOxS"},
+			want: map[string]string{
+				"admin": "secret",
+				"alice": "pass:word",
+				"bob":   "$2a$10$Eyqm0/qCSkaq4OxJb06e0ei5gKRvCjNud/HVkQ5ksdxTgPxmoAOxS",
+			},
+		},
+		{name: "Error (no colon)", users: []string{"admin"}, wantErr: "user #1: expected name:password"},
+		{name: "Error (empty password)", users: []string{"admin:"}, wantErr: "user #1: name and password must not be empty"},
+		{name: "Error (duplicate)", users: []string{"admin:a", "admin:b"}, wantErr: "user admin: duplicate"},
+		{name: "Error (hash)", users: []string{"admin:$2a$10$short"}, wantErr: "user admin: invalid password hash"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := HTTPServer{Users: tt.users}.GetUsers()
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(Middleware())
+	router.GET("/subscription/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/metrics", gin.WrapH(Handler()))
+
+	for _, path := range []string{"/subscription/1", "/subscription/2", "/unknown"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(httpRequests.WithLabelValues("/subscription/:id", "GET", "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(httpRequests.WithLabelValues(unmatchedRoute, "GET", "404")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(httpInFlight))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `subscriptions_http_request_duration_seconds_count{method="GET",route="/subscription/:id",status="200"} 2`)
+}
+
+func TestObserveStorage(t *testing.T) {
+	errMissing := errors.New("missing")
+
+	ObserveStorage("test.ok", time.Now(), nil)
+	ObserveStorage("test.missing", time.Now(), errMissing, errMissing)
+	ObserveStorage("test.failed", time.Now(), errors.New("db is down"), errMissing)
+
+	assert.Equal(t, 0.0, testutil.ToFloat64(storageErrors.WithLabelValues("test.ok")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(storageErrors.WithLabelValues("test.missing")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(storageErrors.WithLabelValues("test.failed")))
+	assert.Equal(t, 3, testutil.CollectAndCount(storageDuration))
+}
@@ -0,0 +1,74 @@
+package problem
+
+// ContentType of the RFC 7807 error responses.
+const ContentType = "application/problem+json"
+
+// Code is a stable machine-readable error code. Clients should rely on it
+// instead of the human-readable messages, which may change.
+type Code string
+
+const (
+	CodeBadRequest       Code = "BAD_REQUEST"
+	CodeValidationFailed Code = "VALIDATION_FAILED"
+	CodeUnauthorized     Code = "UNAUTHORIZED"
+	CodeForbidden        Code = "FORBIDDEN"
+	CodeNotFound         Code = "NOT_FOUND"
+	CodePageNotFound     Code = "PAGE_NOT_FOUND"
+	CodeConflict         Code = "CONFLICT"
+	CodeUnprocessable    Code = "UNPROCESSABLE_ENTITY"
+	CodeInternal         Code = "INTERNAL_ERROR"
+	CodeRetry            Code = "RETRY"
+	CodeTooManyRequests  Code = "TOO_MANY_REQUESTS"
+	CodePayloadTooLarge  Code = "PAYLOAD_TOO_LARGE"
+	CodeTimeout          Code = "TIMEOUT"
+
+	CodeInvalidUserID            Code = "INVALID_USER_ID"
+	CodeSubscriptionNotFound     Code = "SUBSCRIPTION_NOT_FOUND"
+	CodeSubscriptionOverlap      Code = "SUBSCRIPTION_PERIOD_OVERLAP"
+	CodeServiceNotFound          Code = "SERVICE_NOT_FOUND"
+	CodeUnknownService           Code = "UNKNOWN_SERVICE"
+	CodeServiceExists            Code = "SERVICE_ALREADY_EXISTS"
+	CodeInvalidServiceName       Code = "INVALID_SERVICE_NAME"
+	CodeCategoryNotFound         Code = "CATEGORY_NOT_FOUND"
+	CodeCategoryExists           Code = "CATEGORY_ALREADY_EXISTS"
+	CodeInvalidCategoryName      Code = "INVALID_CATEGORY_NAME"
+	CodeTagNotFound              Code = "TAG_NOT_FOUND"
+	CodeInvalidTagName           Code = "INVALID_TAG_NAME"
+	CodeMemberNotFound           Code = "MEMBER_NOT_FOUND"
+	CodeInvalidShare             Code = "INVALID_SHARE"
+	CodeSharesExceedPrice        Code = "SHARES_EXCEED_PRICE"
+	CodeInvalidPrice             Code = "INVALID_PRICE"
+	CodeReferenceNotFound        Code = "REFERENCE_NOT_FOUND"
+	CodeInvalidValue             Code = "INVALID_VALUE"
+	CodeBulkSize                 Code = "BULK_SIZE"
+	CodeInvalidImport            Code = "INVALID_IMPORT"
+	CodeUnsupportedMediaType     Code = "UNSUPPORTED_MEDIA_TYPE"
+	CodeIdempotencyKeyReused     Code = "IDEMPOTENCY_KEY_REUSED"
+	CodeIdempotencyKeyInProgress Code = "IDEMPOTENCY_KEY_IN_PROGRESS"
+	CodeAPIKeyNotFound           Code = "API_KEY_NOT_FOUND"
+	CodeInvalidAPIKeyName        Code = "INVALID_API_KEY_NAME"
+	CodeInvalidScope             Code = "INVALID_SCOPE"
+	CodeInvalidLogLevel          Code = "INVALID_LOG_LEVEL"
+	CodeInvalidRole              Code = "INVALID_ROLE"
+	CodeInvalidTenant            Code = "INVALID_TENANT"
+	CodeInsufficientScope        Code = "INSUFFICIENT_SCOPE"
+)
+
+// FieldError describes an invalid field of the request.
+type FieldError struct {
+	Field   string `json:"field" example:"end_date"`
+	Message string `json:"message" example:"must be after start_date"`
+}
+
+// Problem is an RFC 7807 error response extended with the error code,
+// the request id and the invalid fields.
+type Problem struct {
+	Type      string       `json:"type" example:"about:blank"`
+	Title     string       `json:"title" example:"Not Found"`
+	Status    int          `json:"status" example:"404"`
+	Detail    string       `json:"detail,omitempty" example:"no such subscription"`
+	Instance  string       `json:"instance,omitempty" example:"/api/v1/subscription/1"`
+	Code      Code         `json:"code" example:"SUBSCRIPTION_NOT_FOUND"`
+	RequestID string       `json:"request_id,omitempty" example:"3d6e2e6c-0d8a-4c1d-9b6f-3b1f9c2b1f9c"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
@@ -0,0 +1,112 @@
+// Package ratelimit limits requests of clients by token buckets. Buckets
+// are kept by a Store: in process by MemoryStore or shared by replicas in
+// an external store implementing the interface.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+var ErrInvalidLimit = errors.New("rate limit must have positive rate and burst")
+
+// Limit is a bucket of Burst tokens refilled by Rate tokens per second.
+// Every request takes a token.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Validate checks, that the bucket is ever refilled and holds a request.
+func (l Limit) Validate() error {
+	if l.Rate <= 0 || l.Burst < 1 {
+		return ErrInvalidLimit
+	}
+	return nil
+}
+
+// Result is the state of the bucket after the request.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is the time until the next request is allowed, if this one isn't.
+	RetryAfter time.Duration
+}
+
+// Store takes tokens from buckets of clients.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// MemoryStore keeps buckets in process, so each replica limits clients
+// on its own.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket), now: time.Now}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit) (Result, error) {
+	if err := limit.Validate(); err != nil {
+		return Result{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	burst := float64(limit.Burst)
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: burst, last: now}
+		s.buckets[key] = b
+	}
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*limit.Rate)
+	b.last = now
+
+	res := Result{Limit: limit.Burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = seconds((1 - b.tokens) / limit.Rate)
+	}
+	res.Remaining = int(b.tokens)
+	res.Reset = seconds((burst - b.tokens) / limit.Rate)
+	return res, nil
+}
+
+// Cleanup drops buckets unused for the age, they are full again unless
+// the rate is very low.
+func (s *MemoryStore) Cleanup(age time.Duration) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := 0
+	for key, b := range s.buckets {
+		if s.now().Sub(b.last) >= age {
+			delete(s.buckets, key)
+			n++
+		}
+	}
+	return n
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,54 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore_Take(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+	limit := Limit{Rate: 1, Burst: 2}
+
+	res, err := s.Take(t.Context(), "a", limit)
+	assert.NoError(t, err)
+	assert.Equal(t, Result{Allowed: true, Limit: 2, Remaining: 1, Reset: time.Second}, res)
+
+	res, _ = s.Take(t.Context(), "a", limit)
+	assert.Equal(t, Result{Allowed: true, Limit: 2, Remaining: 0, Reset: 2 * time.Second}, res)
+
+	res, _ = s.Take(t.Context(), "a", limit)
+	assert.Equal(t, Result{Allowed: false, Limit: 2, Remaining: 0, Reset: 2 * time.Second, RetryAfter: time.Second}, res)
+
+	// Other clients have their own buckets.
+	res, _ = s.Take(t.Context(), "b", limit)
+	assert.True(t, res.Allowed)
+
+	now = now.Add(500 * time.Millisecond)
+	res, _ = s.Take(t.Context(), "a", limit)
+	assert.Equal(t, Result{Allowed: false, Limit: 2, Remaining: 0, Reset: 1500 * time.Millisecond, RetryAfter: 500 * time.Millisecond}, res)
+
+	now = now.Add(500 * time.Millisecond)
+	res, _ = s.Take(t.Context(), "a", limit)
+	assert.True(t, res.Allowed)
+
+	_, err = s.Take(t.Context(), "a", Limit{Rate: 0, Burst: 1})
+	assert.ErrorIs(t, err, ErrInvalidLimit)
+}
+
+func TestMemoryStore_Cleanup(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+	limit := Limit{Rate: 1, Burst: 1}
+
+	_, _ = s.Take(t.Context(), "a", limit)
+	now = now.Add(time.Minute)
+	_, _ = s.Take(t.Context(), "b", limit)
+
+	assert.Equal(t, 1, s.Cleanup(time.Minute))
+	assert.Len(t, s.buckets, 1)
+}
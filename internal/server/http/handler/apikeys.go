@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"net/http"
+
+	microservice "github.com/ikotiki/go-rest-api-service-subscriptions"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/auth"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyHandler struct {
+	key service.APIKeys
+}
+
+type apiKeyRequest struct {
+	Name   string               `json:"name" binding:"required" example:"billing"`
+	UserID *microservice.UserID `json:"user_id,omitempty"`
+	Scopes []string             `json:"scopes" binding:"required" example:"subscriptions:read,reports:read"`
+	Roles  []string             `json:"roles,omitempty" example:"viewer"`
+}
+
+// apiKeyResponse holds the created key. The key is shown only once.
+type apiKeyResponse struct {
+	*microservice.APIKey
+	Key string `json:"key" example:"sk_Zm9vYmFyYmF6..."`
+}
+
+func NewAPIKeyHandler(g *gin.RouterGroup, service service.APIKeys) *APIKeyHandler {
+	a := &APIKeyHandler{
+		key: service,
+	}
+	a.registerRoutes(g)
+	return a
+}
+
+func (a *APIKeyHandler) registerRoutes(g *gin.RouterGroup) {
+	key := g.Group("/apikey")
+	{
+		key.GET("/", Authorize(auth.OpAPIKeysRead), a.listAPIKeys)
+		key.POST("/", Authorize(auth.OpAPIKeysWrite), a.createAPIKey)
+		key.DELETE("/:id", Authorize(auth.OpAPIKeysWrite), a.revokeAPIKey)
+	}
+}
+
+// listAPIKeys godoc
+// @Summary      API Keys
+// @Description  Get all API keys including revoked ones, admins only
+// @Tags         apikeys
+// @Produce      json
+// @Success      200  {object}  respSuc{obj=[]microservice.APIKey}
+// @Failure      403  {object}  respErr
+// @Failure      500  {object}  respErr
+// @Router       /apikey/	 [get]
+func (a *APIKeyHandler) listAPIKeys(c *gin.Context) {
+	const op = "handler.listAPIKeys"
+	log, ctx := prepareTools(c, op)
+
+	keys, err := a.key.List(ctx)
+	if err != nil {
+		writeError(c, log, err, "error getting api keys on the server")
+		return
+	}
+
+	writeObj(c, keys)
+}
+
+// createAPIKey godoc
+// @Summary      Create API Key
+// @Description  Create a key with scopes subscriptions:read, subscriptions:write and reports:read, admins only.
+// @Description  Keys bound to a user act on its behalf, others act on any user. Roles are checked by the access policy,
+// @Description  keys without roles get the default role. The key is returned only once.
+// @Tags         apikeys
+// @Accept       json
+// @Produce      json
+// @Param        key  body     apiKeyRequest  true  "api key"
+// @Success      201  {object}  respSuc{obj=apiKeyResponse}
+// @Failure      400  {object}  respErr
+// @Failure      403  {object}  respErr
+// @Failure      500  {object}  respErr
+// @Router       /apikey/	 [post]
+func (a *APIKeyHandler) createAPIKey(c *gin.Context) {
+	const op = "handler.createAPIKey"
+	log, ctx := prepareTools(c, op)
+
+	req := &apiKeyRequest{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		writeBadRequest(c, "error binding json: "+err.Error())
+		return
+	}
+
+	key := &microservice.APIKey{Name: req.Name, UserID: req.UserID, Scopes: req.Scopes, Roles: req.Roles}
+	secret, err := a.key.Create(ctx, key)
+	if err != nil {
+		writeError(c, log, err, "error creating api key on the server")
+		return
+	}
+
+	log.Info().Int64("id", key.ID).Str("prefix", key.Prefix).Msg("api key created")
+
+	writeSuccess(c, http.StatusCreated, msgSuccess, apiKeyResponse{APIKey: key, Key: secret})
+}
+
+// revokeAPIKey godoc
+// @Summary      Revoke API Key
+// @Description  Revoke the key, it's kept in the list, admins only
+// @Tags         apikeys
+// @Produce      json
+// @Param        id    path     microservice.APIKeyID  true  "id of the api key"  minimum(1)
+// @Success      204  {object}  respSucNoObj
+// @Failure      400  {object}  respErr
+// @Failure      403  {object}  respErr
+// @Failure      404  {object}  respErr
+// @Failure      500  {object}  respErr
+// @Router       /apikey/{id}	 [delete]
+func (a *APIKeyHandler) revokeAPIKey(c *gin.Context) {
+	const op = "handler.revokeAPIKey"
+	log, ctx := prepareTools(c, op)
+
+	id, err := parseID(c, "id")
+	if err != nil {
+		log.Debug().Err(err).Str("id", c.Param("id")).Msg("can't parse api key id")
+		writeBadRequest(c, err.Error())
+		return
+	}
+
+	if err = a.key.Revoke(ctx, id); err != nil {
+		writeError(c, log, err, "error revoking api key on the server")
+		return
+	}
+
+	log.Info().Int64("id", id).Msg("api key revoked")
+
+	writeSuccess(c, http.StatusNoContent, msgSuccess, nil)
+}
@@ -1,5 +1,15 @@
 package handler
 
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/auth"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/pkg/api/problem"
+
+	"github.com/gin-gonic/gin"
+)
+
 type AuthHandler struct {
 	users map[string]string
 }
@@ -8,6 +18,98 @@ func NewAuthHandler(users map[string]string) *AuthHandler {
 	return &AuthHandler{users: users}
 }
 
+// AuthRolesKey is a gin context key of roles of the authenticated principal.
+const AuthRolesKey = "roles"
+
+const ctxKeyPolicy = "handler.policy"
+
+// AuthMiddleware authenticates requests by the first authenticator, which
+// finds its credentials in the request, and puts the principal to the
+// request context. Requests without valid credentials are rejected.
+func AuthMiddleware(authenticators ...auth.Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, a := range authenticators {
+			p, err := a.Authenticate(c.Request)
+			if errors.Is(err, auth.ErrNoCredentials) {
+				continue
+			}
+			if err != nil {
+				unauthorized(c, authenticators, err.Error())
+				return
+			}
+
+			c.Set(gin.AuthUserKey, p.Name)
+			c.Set(AuthRolesKey, p.Roles)
+			c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), p))
+			c.Next()
+			return
+		}
+		unauthorized(c, authenticators, "authentication required")
+	}
+}
+
+// RequireScope rejects requests of principals without the scope: read for
+// GET and HEAD, write for other methods. Read-only groups pass empty write,
+// so no scope allows other methods. Principals not limited by scopes and
+// requests without principal pass.
+func RequireScope(read, write string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p, ok := auth.FromContext(c.Request.Context())
+		if !ok {
+			c.Next()
+			return
+		}
+
+		scope := write
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			scope = read
+		}
+		if scope == "" && p.Scopes != nil {
+			writeErrorResponse(c, http.StatusForbidden, problem.CodeInsufficientScope, "method not allowed by scopes", nil)
+			c.Abort()
+			return
+		}
+		if scope != "" && !p.HasScope(scope) {
+			writeErrorResponse(c, http.StatusForbidden, problem.CodeInsufficientScope, "scope "+scope+" required", nil)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// PolicyMiddleware sets the policy checked by Authorize on routes.
+func PolicyMiddleware(policy *auth.Policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(ctxKeyPolicy, policy)
+		c.Next()
+	}
+}
+
+// Authorize rejects requests of principals, whose roles don't allow the
+// operation. Every route is registered with its operation. Requests pass
+// without principal or policy, e.g. with authentication disabled.
+func Authorize(op string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		policy, _ := c.Value(ctxKeyPolicy).(*auth.Policy)
+		p, ok := auth.FromContext(c.Request.Context())
+		if policy == nil || !ok || policy.Allows(p, op) {
+			c.Next()
+			return
+		}
+		writeErrorResponse(c, http.StatusForbidden, problem.CodeForbidden, "operation "+op+" is not allowed", nil)
+		c.Abort()
+	}
+}
+
+func unauthorized(c *gin.Context, authenticators []auth.Authenticator, msg string) {
+	for _, a := range authenticators {
+		c.Writer.Header().Add("WWW-Authenticate", a.Challenge())
+	}
+	writeErrorResponse(c, http.StatusUnauthorized, problem.CodeUnauthorized, msg, nil)
+	c.Abort()
+}
+
 // func (a *AuthHandler) registerRoutes(g *gin.RouterGroup) {
 // 	g.GET("/secret", a.handleSectret, gin.BasicAuth(a.users))
 // }
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/auth"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthMiddleware(t *testing.T) {
+	logger.InitLoggerByFlag(defaultLogLevel, true)
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(AuthMiddleware(auth.NewBasicAuthenticator(
+		map[string]string{"admin": "secret"},
+		map[string]*auth.Principal{"admin": {Name: "admin", Admin: true}},
+	)))
+	router.GET("/whoami", func(c *gin.Context) {
+		p, _ := auth.FromContext(c.Request.Context())
+		c.String(http.StatusOK, p.Name)
+	})
+
+	tests := []struct {
+		name       string
+		user       string
+		password   string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "Ok", user: "admin", password: "secret", wantStatus: http.StatusOK, wantBody: "admin"},
+		{name: "Error (password)", user: "admin", password: "wrong", wantStatus: http.StatusUnauthorized},
+		{name: "Error (no credentials)", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+			if tt.user != "" {
+				req.SetBasicAuth(tt.user, tt.password)
+			}
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusUnauthorized {
+				assert.Equal(t, `Basic realm="Authorization Required"`, w.Header().Get("WWW-Authenticate"))
+			} else {
+				assert.Equal(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+type apiKeyVerifierFunc func(key string) (*auth.Principal, error)
+
+func (f apiKeyVerifierFunc) Verify(_ context.Context, key string) (*auth.Principal, error) {
+	return f(key)
+}
+
+func TestRequireScope(t *testing.T) {
+	logger.InitLoggerByFlag(defaultLogLevel, true)
+	gin.SetMode(gin.TestMode)
+
+	keys := map[string]*auth.Principal{
+		"sk_read": {Name: "apikey:1", Admin: true, Scopes: []string{auth.ScopeSubscriptionsRead}},
+		"sk_none": {Name: "apikey:2", Admin: true, Scopes: []string{}},
+	}
+	router := gin.New()
+	router.Use(AuthMiddleware(
+		auth.NewBasicAuthenticator(map[string]string{"admin": "secret"}, nil),
+		auth.NewAPIKeyAuthenticator(apiKeyVerifierFunc(func(key string) (*auth.Principal, error) {
+			if p, ok := keys[key]; ok {
+				return p, nil
+			}
+			return nil, auth.ErrInvalidCredentials
+		})),
+	))
+	g := router.Group("", RequireScope(auth.ScopeSubscriptionsRead, auth.ScopeSubscriptionsWrite))
+	g.GET("/subscription", func(c *gin.Context) { c.Status(http.StatusOK) })
+	g.POST("/subscription", func(c *gin.Context) { c.Status(http.StatusOK) })
+	reports := router.Group("", RequireScope(auth.ScopeSubscriptionsRead, ""))
+	reports.GET("/report", func(c *gin.Context) { c.Status(http.StatusOK) })
+	reports.POST("/report", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		auth       string
+		basic      bool
+		wantStatus int
+	}{
+		{name: "Ok (read)", method: http.MethodGet, auth: "ApiKey sk_read", wantStatus: http.StatusOK},
+		{name: "Ok (basic)", method: http.MethodPost, basic: true, wantStatus: http.StatusOK},
+		{name: "Error (write)", method: http.MethodPost, auth: "ApiKey sk_read", wantStatus: http.StatusForbidden},
+		{name: "Error (no scopes)", method: http.MethodGet, auth: "ApiKey sk_none", wantStatus: http.StatusForbidden},
+		{name: "Error (unknown key)", method: http.MethodGet, auth: "ApiKey sk_other", wantStatus: http.StatusUnauthorized},
+		{name: "Ok (read-only group)", method: http.MethodGet, path: "/report", auth: "ApiKey sk_read", wantStatus: http.StatusOK},
+		{name: "Error (read-only group)", method: http.MethodPost, path: "/report", auth: "ApiKey sk_read", wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			path := tt.path
+			if path == "" {
+				path = "/subscription"
+			}
+			req := httptest.NewRequest(tt.method, path, nil)
+			if tt.basic {
+				req.SetBasicAuth("admin", "secret")
+			} else {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	logger.InitLoggerByFlag(defaultLogLevel, true)
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(AuthMiddleware(auth.NewBasicAuthenticator(
+		map[string]string{"viewer": "secret", "editor": "secret"},
+		map[string]*auth.Principal{
+			"viewer": {Name: "viewer", Roles: []string{auth.RoleViewer}},
+			"editor": {Name: "editor", Roles: []string{auth.RoleEditor}},
+		},
+	)), PolicyMiddleware(auth.DefaultPolicy()))
+	router.GET("/subscription", Authorize(auth.OpSubscriptionRead), func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.POST("/subscription", Authorize(auth.OpSubscriptionWrite), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	tests := []struct {
+		name       string
+		method     string
+		user       string
+		wantStatus int
+	}{
+		{name: "Ok (viewer reads)", method: http.MethodGet, user: "viewer", wantStatus: http.StatusOK},
+		{name: "Ok (editor writes)", method: http.MethodPost, user: "editor", wantStatus: http.StatusOK},
+		{name: "Error (viewer writes)", method: http.MethodPost, user: "viewer", wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, "/subscription", nil)
+			req.SetBasicAuth(tt.user, "secret")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	microservice "github.com/ikotiki/go-rest-api-service-subscriptions"
+
+	"github.com/gin-gonic/gin"
+)
+
+type bulkRequest struct {
+	// AllOrNothing rejects the whole request, if any item is rejected.
+	AllOrNothing bool                         `json:"all_or_nothing" example:"false"`
+	Items        []*microservice.Subscription `json:"items" binding:"required"`
+}
+
+type bulkDeleteRequest struct {
+	AllOrNothing bool                          `json:"all_or_nothing" example:"false"`
+	IDs          []microservice.SubscriptionID `json:"ids" binding:"required" example:"1,2"`
+}
+
+// createSubscriptionsBulk godoc
+// @Summary      Create Subscriptions
+// @Description  Create many subscriptions within a single transaction.
+// @Description  Rejected items are reported in results, while the rest are created,
+// @Description  unless all_or_nothing is set, then nothing is created and 422 lists the rejected items.
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        request  body     bulkRequest  true  "subscriptions to create"
+// @Success      200  {object}  respSuc{obj=service.BulkResults}
+// @Failure      400  {object}  respErr
+// @Failure      422  {object}  respErr{obj=[]service.FieldError}
+// @Failure      500  {object}  respErr
+// @Router       /subscription/bulk	 [post]
+func (a *SubscriptionHandler) createSubscriptionsBulk(c *gin.Context) {
+	const op = "handler.createSubscriptionsBulk"
+	log, ctx := prepareTools(c, op)
+
+	req := &bulkRequest{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		writeBadRequest(c, "error binding json: "+err.Error())
+		return
+	}
+
+	res, err := a.sub.CreateBulk(ctx, req.Items, req.AllOrNothing)
+	if err != nil {
+		writeError(c, log, err, "error creating subscriptions on the server")
+		return
+	}
+
+	log.Info().Int("succeeded", res.Succeeded).Int("failed", res.Failed).Msg("subscriptions created")
+
+	writeObj(c, res)
+}
+
+// updateSubscriptionsBulk godoc
+// @Summary      Update Subscriptions
+// @Description  Update many subscriptions given with ids within a single transaction.
+// @Description  Rejected items are reported in results, while the rest are updated,
+// @Description  unless all_or_nothing is set, then nothing is updated and 422 lists the rejected items.
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        request  body     bulkRequest  true  "subscriptions to update"
+// @Success      200  {object}  respSuc{obj=service.BulkResults}
+// @Failure      400  {object}  respErr
+// @Failure      422  {object}  respErr{obj=[]service.FieldError}
+// @Failure      500  {object}  respErr
+// @Router       /subscription/bulk	 [put]
+func (a *SubscriptionHandler) updateSubscriptionsBulk(c *gin.Context) {
+	const op = "handler.updateSubscriptionsBulk"
+	log, ctx := prepareTools(c, op)
+
+	req := &bulkRequest{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		writeBadRequest(c, "error binding json: "+err.Error())
+		return
+	}
+
+	res, err := a.sub.UpdateBulk(ctx, req.Items, req.AllOrNothing)
+	if err != nil {
+		writeError(c, log, err, "error updating subscriptions on the server")
+		return
+	}
+
+	log.Info().Int("succeeded", res.Succeeded).Int("failed", res.Failed).Msg("subscriptions updated")
+
+	writeObj(c, res)
+}
+
+// deleteSubscriptionsBulk godoc
+// @Summary      Delete Subscriptions
+// @Description  Delete many subscriptions by ids within a single transaction.
+// @Description  Missing subscriptions are reported in results, unless all_or_nothing is set,
+// @Description  then nothing is deleted and 422 lists the missing ones.
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        request  body     bulkDeleteRequest  true  "ids of subscriptions"
+// @Success      200  {object}  respSuc{obj=service.BulkResults}
+// @Failure      400  {object}  respErr
+// @Failure      422  {object}  respErr{obj=[]service.FieldError}
+// @Failure      500  {object}  respErr
+// @Router       /subscription/bulk	 [delete]
+func (a *SubscriptionHandler) deleteSubscriptionsBulk(c *gin.Context) {
+	const op = "handler.deleteSubscriptionsBulk"
+	log, ctx := prepareTools(c, op)
+
+	req := &bulkDeleteRequest{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		writeBadRequest(c, "error binding json: "+err.Error())
+		return
+	}
+
+	res, err := a.sub.DeleteBulk(ctx, req.IDs, req.AllOrNothing)
+	if err != nil {
+		writeError(c, log, err, "error deleting subscriptions on the server")
+		return
+	}
+
+	log.Info().Int("succeeded", res.Succeeded).Int("failed", res.Failed).Msg("subscriptions deleted")
+
+	writeObj(c, res)
+}
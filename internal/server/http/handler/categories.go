@@ -0,0 +1,209 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	microservice "github.com/ikotiki/go-rest-api-service-subscriptions"
+
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/auth"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/service"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/pkg/e"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CategoryHandler struct {
+	cat service.Categories
+}
+
+func NewCategoryHandler(g *gin.RouterGroup, service service.Categories) *CategoryHandler {
+	a := &CategoryHandler{
+		cat: service,
+	}
+	a.registerRoutes(g)
+	return a
+}
+
+func (a *CategoryHandler) registerRoutes(g *gin.RouterGroup) {
+	cat := g.Group("/category")
+	{
+		cat.GET("/", Authorize(auth.OpCategoryRead), a.listCategories)
+		cat.GET("/:id", Authorize(auth.OpCategoryRead), a.getCategoryByID)
+		cat.POST("/", Authorize(auth.OpCategoryWrite), a.createCategory)
+		cat.PUT("/:id", Authorize(auth.OpCategoryWrite), a.updateCategory)
+		cat.DELETE("/:id", Authorize(auth.OpCategoryWrite), a.deleteCategory)
+	}
+}
+
+// listCategories godoc
+// @Summary      Categories
+// @Description  Get all categories
+// @Tags         categories
+// @Produce      json
+// @Success      200  {object}  respSuc{obj=[]microservice.Category}
+// @Failure      500  {object}  respErr
+// @Router       /category/	 [get]
+func (a *CategoryHandler) listCategories(c *gin.Context) {
+	const op = "handler.listCategories"
+	log, ctx := prepareTools(c, op)
+
+	cats, err := a.cat.List(ctx)
+	if err != nil {
+		writeError(c, log, err, "error getting categories on the server")
+		return
+	}
+
+	writeObj(c, cats)
+}
+
+// getCategoryByID godoc
+// @Summary      Category By ID
+// @Description  Get category by its id
+// @Tags         categories
+// @Produce      json
+// @Param        id    path     microservice.CategoryID true  "id of the category"  minimum(1)
+// @Success      200  {object}  respSuc{obj=microservice.Category}
+// @Failure      400  {object}  respErr
+// @Failure      404  {object}  respErr
+// @Failure      500  {object}  respErr
+// @Router       /category/{id}	 [get]
+func (a *CategoryHandler) getCategoryByID(c *gin.Context) {
+	const op = "handler.getCategoryByID"
+	log, ctx := prepareTools(c, op)
+
+	id, err := a.parseCategoryID(c)
+	if err != nil {
+		log.Debug().Err(err).Str("id", c.Param("id")).Msg("can't parse category id")
+		writeBadRequest(c, err.Error())
+		return
+	}
+
+	cat, err := a.cat.GetByID(ctx, id)
+	if err != nil {
+		writeError(c, log, err, "error getting category on the server")
+		return
+	}
+
+	writeObj(c, cat)
+}
+
+// createCategory godoc
+// @Summary      Create Category
+// @Description  Create a new category
+// @Tags         categories
+// @Accept       json
+// @Produce      json
+// @Param        category  body     microservice.Category  true  "category object"
+// @Success      201  {object}  respSuc{obj=microservice.CategoryID}
+// @Failure      400  {object}  respErr
+// @Failure      422  {object}  respErr
+// @Failure      500  {object}  respErr
+// @Router       /category/	 [post]
+func (a *CategoryHandler) createCategory(c *gin.Context) {
+	const op = "handler.createCategory"
+	log, ctx := prepareTools(c, op)
+
+	cat := &microservice.Category{}
+	if err := c.ShouldBindJSON(cat); err != nil {
+		writeBadRequest(c, "error binding json: "+err.Error())
+		return
+	}
+
+	id, err := a.cat.Create(ctx, cat)
+	if err != nil {
+		writeError(c, log, err, "error creating category on the server")
+		return
+	}
+
+	log.Info().Int("id", int(id)).Msg("category created")
+
+	writeSuccess(c, http.StatusCreated, msgSuccess, id)
+}
+
+// updateCategory godoc
+// @Summary      Update Category
+// @Description  Rename the category
+// @Tags         categories
+// @Accept       json
+// @Produce      json
+// @Param        id    path     microservice.CategoryID  true  "id of the category"  minimum(1)
+// @Param        category  body     microservice.Category  true  "category object"
+// @Success      200  {object}  respSucNoObj
+// @Failure      400  {object}  respErr
+// @Failure      404  {object}  respErr
+// @Failure      422  {object}  respErr
+// @Failure      500  {object}  respErr
+// @Router       /category/{id}	 [put]
+func (a *CategoryHandler) updateCategory(c *gin.Context) {
+	const op = "handler.updateCategory"
+	log, ctx := prepareTools(c, op)
+
+	id, err := a.parseCategoryID(c)
+	if err != nil {
+		log.Debug().Err(err).Str("id", c.Param("id")).Msg("can't parse category id")
+		writeBadRequest(c, err.Error())
+		return
+	}
+
+	cat := &microservice.Category{}
+	if err := c.ShouldBindJSON(cat); err != nil {
+		writeBadRequest(c, "error binding json: "+err.Error())
+		return
+	}
+	cat.ID = id
+
+	if err = a.cat.Update(ctx, cat); err != nil {
+		writeError(c, log, err, "error updating category")
+		return
+	}
+
+	writeOK(c)
+}
+
+// deleteCategory godoc
+// @Summary      Delete Category
+// @Description  Delete the category, subscriptions and services become uncategorized
+// @Tags         categories
+// @Produce      json
+// @Param        id    path     microservice.CategoryID  true  "id of the category"  minimum(1)
+// @Success      204  {object}  respSucNoObj
+// @Failure      400  {object}  respErr
+// @Failure      404  {object}  respErr
+// @Failure      500  {object}  respErr
+// @Router       /category/{id}	 [delete]
+func (a *CategoryHandler) deleteCategory(c *gin.Context) {
+	const op = "handler.deleteCategory"
+	log, ctx := prepareTools(c, op)
+
+	id, err := a.parseCategoryID(c)
+	if err != nil {
+		log.Debug().Err(err).Str("id", c.Param("id")).Msg("can't parse category id")
+		writeBadRequest(c, err.Error())
+		return
+	}
+
+	if err = a.cat.DeleteByID(ctx, id); err != nil {
+		writeError(c, log, err, "error deleting category on the server")
+		return
+	}
+
+	log.Info().Int("id", int(id)).Msg("category deleted")
+
+	writeSuccess(c, http.StatusNoContent, msgSuccess, nil)
+}
+
+func (a *CategoryHandler) parseCategoryID(c *gin.Context) (int64, error) {
+	idStr := c.Param("id")
+	if idStr == "" {
+		return 0, errors.New("can't parse category id: empty")
+	}
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return 0, e.Wrap(fmt.Sprintf("can't parse category id by string %s", idStr), err)
+	}
+
+	return id, nil
+}
@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/pkg/api/problem"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/service"
+
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+// ErrorFormat is a shape of the error responses.
+type ErrorFormat string
+
+const (
+	// ErrorFormatLegacy is the {success, msg, obj} envelope used by existing clients.
+	ErrorFormatLegacy ErrorFormat = "legacy"
+	// ErrorFormatProblem is RFC 7807 application/problem+json.
+	ErrorFormatProblem ErrorFormat = "problem"
+)
+
+const ctxKeyErrorFormat = "handler.error_format"
+
+// ErrorFormatMiddleware selects the format of error responses. Clients
+// accepting application/problem+json get problems regardless of the default.
+func ErrorFormatMiddleware(def ErrorFormat) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		format := def
+		if strings.Contains(c.GetHeader("Accept"), problem.ContentType) {
+			format = ErrorFormatProblem
+		}
+		c.Set(ctxKeyErrorFormat, format)
+		c.Next()
+	}
+}
+
+// NoRoute responds to requests of unknown pages.
+func NoRoute(c *gin.Context) {
+	writeErrorResponse(c, http.StatusNotFound, problem.CodePageNotFound, "page not found", nil)
+}
+
+type errorMapping struct {
+	err    error
+	status int
+	code   problem.Code
+}
+
+// errorMappings translates service errors to responses. More specific
+// errors go first, as wrapped errors match the errors they wrap.
+var errorMappings = []errorMapping{
+	{service.ErrNoUserID, http.StatusBadRequest, problem.CodeInvalidUserID},
+	{service.ErrNoSubscriptionID, http.StatusBadRequest, problem.CodeBadRequest},
+	{service.ErrNoSuchSubscription, http.StatusNotFound, problem.CodeSubscriptionNotFound},
+	{service.ErrUserSubscriptionPairAlreadyExists, http.StatusUnprocessableEntity, problem.CodeSubscriptionOverlap},
+	{service.ErrUnknownService, http.StatusUnprocessableEntity, problem.CodeUnknownService},
+	{service.ErrNoSuchService, http.StatusNotFound, problem.CodeServiceNotFound},
+	{service.ErrServiceAlreadyExists, http.StatusUnprocessableEntity, problem.CodeServiceExists},
+	{service.ErrInvalidServiceName, http.StatusBadRequest, problem.CodeInvalidServiceName},
+	{service.ErrNoSuchCategory, http.StatusNotFound, problem.CodeCategoryNotFound},
+	{service.ErrCategoryAlreadyExists, http.StatusUnprocessableEntity, problem.CodeCategoryExists},
+	{service.ErrInvalidCategoryName, http.StatusBadRequest, problem.CodeInvalidCategoryName},
+	{service.ErrNoSuchTag, http.StatusNotFound, problem.CodeTagNotFound},
+	{service.ErrInvalidTagName, http.StatusBadRequest, problem.CodeInvalidTagName},
+	{service.ErrNoSuchMember, http.StatusNotFound, problem.CodeMemberNotFound},
+	{service.ErrInvalidShare, http.StatusBadRequest, problem.CodeInvalidShare},
+	{service.ErrSharesExceedPrice, http.StatusUnprocessableEntity, problem.CodeSharesExceedPrice},
+	{service.ErrForbidden, http.StatusForbidden, problem.CodeForbidden},
+	{service.ErrNoSuchAPIKey, http.StatusNotFound, problem.CodeAPIKeyNotFound},
+	{service.ErrInvalidAPIKeyName, http.StatusBadRequest, problem.CodeInvalidAPIKeyName},
+	{service.ErrInvalidScope, http.StatusBadRequest, problem.CodeInvalidScope},
+	{service.ErrInvalidRole, http.StatusBadRequest, problem.CodeInvalidRole},
+	{service.ErrBulkSize, http.StatusBadRequest, problem.CodeBulkSize},
+	{service.ErrInvalidImport, http.StatusBadRequest, problem.CodeInvalidImport},
+	{service.ErrInvalidPrice, http.StatusUnprocessableEntity, problem.CodeInvalidPrice},
+	{service.ErrAlreadyExists, http.StatusConflict, problem.CodeConflict},
+	{service.ErrConflict, http.StatusConflict, problem.CodeConflict},
+	{service.ErrReferenceNotFound, http.StatusUnprocessableEntity, problem.CodeReferenceNotFound},
+	{service.ErrInvalidValue, http.StatusUnprocessableEntity, problem.CodeInvalidValue},
+	{service.ErrSerialization, http.StatusServiceUnavailable, problem.CodeRetry},
+	{service.ErrQueryCanceled, http.StatusServiceUnavailable, problem.CodeTimeout},
+}
+
+// writeError writes the response for the error returned by the service.
+// Unexpected errors are logged and hidden behind the internal message.
+func writeError(c *gin.Context, log zerolog.Logger, err error, internalMsg string) {
+	var verr *service.ValidationError
+	if errors.As(err, &verr) {
+		writeValidationFailed(c, verr)
+		return
+	}
+
+	for _, m := range errorMappings {
+		if errors.Is(err, m.err) {
+			writeErrorResponse(c, m.status, m.code, m.err.Error(), nil)
+			return
+		}
+	}
+
+	log.Error().Err(err).Msg(internalMsg)
+	writeErrorResponse(c, http.StatusInternalServerError, problem.CodeInternal, internalMsg, nil)
+}
+
+// writeValidationFailed lists every invalid field of the input.
+func writeValidationFailed(c *gin.Context, verr *service.ValidationError) {
+	fields := make([]problem.FieldError, 0, len(verr.Fields))
+	for _, f := range verr.Fields {
+		fields = append(fields, problem.FieldError{Field: f.Field, Message: f.Message})
+	}
+	writeErrorResponse(c, http.StatusUnprocessableEntity, problem.CodeValidationFailed, verr.Error(), fields)
+}
+
+// writeErrorResponse writes the error in the format chosen for the request.
+// Legacy envelope keeps the invalid fields in obj.
+func writeErrorResponse(c *gin.Context, status int, code problem.Code, msg string, fields []problem.FieldError) {
+	if format, _ := c.Get(ctxKeyErrorFormat); format != ErrorFormatProblem {
+		var obj any
+		if fields != nil {
+			obj = fields
+		}
+		c.JSON(status, resp{Success: false, Msg: msg, Obj: obj})
+		return
+	}
+
+	instance := ""
+	if c.Request != nil {
+		instance = c.Request.URL.Path
+	}
+	c.Render(status, problemRender{problem.Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    msg,
+		Instance:  instance,
+		Code:      code,
+		RequestID: requestid.Get(c),
+		Errors:    fields,
+	}})
+}
+
+// codeByStatus gives a generic code for the errors written by status only.
+func codeByStatus(status int) problem.Code {
+	switch status {
+	case http.StatusBadRequest:
+		return problem.CodeBadRequest
+	case http.StatusUnauthorized:
+		return problem.CodeUnauthorized
+	case http.StatusForbidden:
+		return problem.CodeForbidden
+	case http.StatusNotFound:
+		return problem.CodeNotFound
+	case http.StatusConflict:
+		return problem.CodeConflict
+	case http.StatusUnsupportedMediaType:
+		return problem.CodeUnsupportedMediaType
+	case http.StatusUnprocessableEntity:
+		return problem.CodeUnprocessable
+	default:
+		return problem.CodeInternal
+	}
+}
+
+// problemRender renders the problem with its own content type,
+// which gin's JSON render would override.
+type problemRender struct {
+	problem.Problem
+}
+
+func (r problemRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	return json.NewEncoder(w).Encode(r.Problem)
+}
+
+func (r problemRender) WriteContentType(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", problem.ContentType)
+}
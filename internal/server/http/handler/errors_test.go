@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/pkg/api/problem"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/service"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/logger"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_writeError(t *testing.T) {
+	logger.InitLoggerByFlag(defaultLogLevel, true)
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		format     ErrorFormat
+		accept     string
+		err        error
+		wantStatus int
+		wantType   string
+		wantCode   problem.Code
+		wantMsg    string
+		wantFields int
+	}{
+		{
+			name:       "Legacy",
+			format:     ErrorFormatLegacy,
+			err:        service.ErrNoSuchSubscription,
+			wantStatus: http.StatusNotFound,
+			wantType:   "application/json; charset=utf-8",
+			wantMsg:    "no such subscription",
+		},
+		{
+			name:       "Problem",
+			format:     ErrorFormatProblem,
+			err:        service.ErrNoSuchSubscription,
+			wantStatus: http.StatusNotFound,
+			wantType:   problem.ContentType,
+			wantCode:   problem.CodeSubscriptionNotFound,
+			wantMsg:    "no such subscription",
+		},
+		{
+			name:       "Problem (accept header)",
+			format:     ErrorFormatLegacy,
+			accept:     problem.ContentType,
+			err:        service.ErrUnknownService,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantType:   problem.ContentType,
+			wantCode:   problem.CodeUnknownService,
+			wantMsg:    "no such service in the catalog",
+		},
+		{
+			name:   "Problem (validation)",
+			format: ErrorFormatProblem,
+			err: &service.ValidationError{Fields: []service.FieldError{
+				{Field: "user_id", Message: "must be a non-nil UUID"},
+				{Field: "start_date", Message: "is required"},
+			}},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantType:   problem.ContentType,
+			wantCode:   problem.CodeValidationFailed,
+			wantFields: 2,
+		},
+		{
+			name:       "Problem (unknown error)",
+			format:     ErrorFormatProblem,
+			err:        errors.New("connection refused"),
+			wantStatus: http.StatusInternalServerError,
+			wantType:   problem.ContentType,
+			wantCode:   problem.CodeInternal,
+			wantMsg:    "internal",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(ErrorFormatMiddleware(tt.format))
+			router.GET("/test", func(c *gin.Context) {
+				writeError(c, log.Logger, tt.err, "internal")
+			})
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantType, w.Header().Get("Content-Type"))
+
+			if tt.wantType != problem.ContentType {
+				got := &resp{}
+				require.NoError(t, json.NewDecoder(w.Body).Decode(got))
+				assert.False(t, got.Success)
+				assert.Equal(t, tt.wantMsg, got.Msg)
+				return
+			}
+
+			got := &problem.Problem{}
+			require.NoError(t, json.NewDecoder(w.Body).Decode(got))
+			assert.Equal(t, tt.wantStatus, got.Status)
+			assert.Equal(t, tt.wantCode, got.Code)
+			assert.Equal(t, "/test", got.Instance)
+			assert.Len(t, got.Errors, tt.wantFields)
+			if tt.wantMsg != "" {
+				assert.Equal(t, tt.wantMsg, got.Detail)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	microservice "github.com/ikotiki/go-rest-api-service-subscriptions"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+const contentTypeNDJSON = "application/x-ndjson"
+
+// exportFlushRows is a number of rows written between flushes of the stream.
+const exportFlushRows = 100
+
+var exportColumns = []string{"id", "user_id", "service_id", "service_name", "category_id", "monthly_price", "start_date", "end_date"}
+
+// subscriptionEncoder writes subscriptions to the stream one by one.
+type subscriptionEncoder interface {
+	begin() error
+	encode(sub *microservice.Subscription) error
+	flush() error
+}
+
+func newSubscriptionEncoder(format string, c *gin.Context) subscriptionEncoder {
+	if format == contentTypeCSV {
+		return &csvEncoder{w: csv.NewWriter(c.Writer)}
+	}
+	return &ndjsonEncoder{enc: json.NewEncoder(c.Writer)}
+}
+
+type csvEncoder struct {
+	w *csv.Writer
+}
+
+func (e *csvEncoder) begin() error {
+	return e.w.Write(exportColumns)
+}
+
+func (e *csvEncoder) encode(sub *microservice.Subscription) error {
+	return e.w.Write([]string{
+		strconv.FormatInt(sub.ID, 10),
+		sub.UserID.String(),
+		formatOptionalID(sub.ServiceID),
+		sub.ServiceName,
+		formatOptionalID(sub.CategoryID),
+		strconv.Itoa(int(sub.MonthlyPrice)),
+		formatDate(sub.StartDate),
+		formatDate(sub.EndDate),
+	})
+}
+
+func (e *csvEncoder) flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+type ndjsonEncoder struct {
+	enc *json.Encoder
+}
+
+func (e *ndjsonEncoder) begin() error { return nil }
+
+func (e *ndjsonEncoder) encode(sub *microservice.Subscription) error { return e.enc.Encode(sub) }
+
+func (e *ndjsonEncoder) flush() error { return nil }
+
+func formatOptionalID(id *int64) string {
+	if id == nil {
+		return ""
+	}
+	return strconv.FormatInt(*id, 10)
+}
+
+func formatDate(d microservice.Date) string {
+	if !d.Valid {
+		return ""
+	}
+	return d.Format("2006-01-02")
+}
+
+// streamSubscriptions writes results of the query row by row, as they are
+// read from the database. Errors are reported as usual until the first row
+// is written, later the stream is cut and the error is only logged.
+func (a *SubscriptionHandler) streamSubscriptions(ctx context.Context, c *gin.Context, log zerolog.Logger, args *service.SubscriptionQueryArgs, format string) {
+	// Exports may outlive the server write timeout.
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		log.Debug().Err(err).Msg("can't reset write deadline")
+	}
+
+	enc := newSubscriptionEncoder(format, c)
+	started := false
+	start := func() error {
+		started = true
+		c.Header("Content-Type", format)
+		c.Status(http.StatusOK)
+		return enc.begin()
+	}
+
+	rows := 0
+	err := a.sub.QueryEach(ctx, args, func(sub *microservice.Subscription) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		if err := enc.encode(sub); err != nil {
+			return err
+		}
+		rows++
+		if rows%exportFlushRows == 0 {
+			if err := enc.flush(); err != nil {
+				return err
+			}
+			c.Writer.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		if !started {
+			writeError(c, log, err, "error getting subscriptions")
+			return
+		}
+		log.Error().Err(err).Int("rows", rows).Msg("export interrupted")
+		c.Abort()
+		return
+	}
+
+	// An empty export still has the header.
+	if !started {
+		if err = start(); err != nil {
+			log.Error().Err(err).Msg("error writing export")
+			return
+		}
+	}
+	if err = enc.flush(); err != nil {
+		log.Error().Err(err).Msg("error writing export")
+		return
+	}
+
+	log.Info().Str("format", format).Int("rows", rows).Msg("subscriptions exported")
+}
@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/auth"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/ratelimit"
 	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -11,7 +13,16 @@ type Handler struct {
 
 	service *service.Service
 
+	limiter ratelimit.Store
+	limits  map[string]ratelimit.Limit
+
 	subscription *SubscriptionHandler
+	catalog      *ServiceHandler
+	category     *CategoryHandler
+	tag          *TagHandler
+	member       *MemberHandler
+	apiKey       *APIKeyHandler
+	logLevel     *LogLevelHandler
 	swagger      *SwaggerController
 }
 
@@ -20,7 +31,40 @@ func New(service *service.Service) *Handler {
 
 }
 
+// WithRateLimits limits requests of clients to route groups, groups
+// without limits aren't limited.
+func (h *Handler) WithRateLimits(store ratelimit.Store, limits map[string]ratelimit.Limit) *Handler {
+	h.limiter = store
+	h.limits = limits
+	return h
+}
+
+// group returns the route group limited by its rate limit.
+func (h *Handler) group(g *gin.RouterGroup, name string, handlers ...gin.HandlerFunc) *gin.RouterGroup {
+	if limit, ok := h.limits[name]; ok && h.limiter != nil {
+		handlers = append([]gin.HandlerFunc{RateLimitMiddleware(h.limiter, name, limit)}, handlers...)
+	}
+	return g.Group("", handlers...)
+}
+
+// InitRoutes registers routes in groups by scopes of API keys and rate
+// limits. The catalog and categories are shared by all users, so changing
+// them needs its own scope, while they are limited with subscriptions.
+// API keys are managed by admins only, so their routes need no scope.
 func (h *Handler) InitRoutes(g *gin.RouterGroup) {
-	h.subscription = NewSubscriptionHandler(g, h.service.Subscriptions)
+	subscriptions := h.group(g, RouteGroupSubscriptions, RequireScope(auth.ScopeSubscriptionsRead, auth.ScopeSubscriptionsWrite))
+	catalog := h.group(g, RouteGroupSubscriptions, RequireScope(auth.ScopeSubscriptionsRead, auth.ScopeCatalogWrite))
+	reports := h.group(g, RouteGroupReports, RequireScope(auth.ScopeReportsRead, ""))
+	admin := h.group(g, RouteGroupAdmin)
+
+	h.subscription = &SubscriptionHandler{sub: h.service.Subscriptions}
+	h.subscription.registerRoutes(subscriptions)
+	h.subscription.registerReportRoutes(reports)
+	h.catalog = NewServiceHandler(catalog, h.service.Services)
+	h.category = NewCategoryHandler(catalog, h.service.Categories)
+	h.tag = NewTagHandler(subscriptions, h.service.Tags)
+	h.member = NewMemberHandler(subscriptions, h.service.Members)
+	h.apiKey = NewAPIKeyHandler(admin, h.service.APIKeys)
+	h.logLevel = NewLogLevelHandler(admin)
 	h.swagger = NewSwaggerController(g)
 }
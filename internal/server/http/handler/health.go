@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Statuses of the service and its dependencies in health responses.
+const (
+	HealthStatusOK   = "ok"
+	HealthStatusFail = "fail"
+)
+
+const (
+	healthCheckShutdown = "shutdown"
+	// DefaultHealthCheckTimeout limits every readiness check.
+	DefaultHealthCheckTimeout = 2 * time.Second
+)
+
+var ErrShuttingDown = errors.New("server is shutting down")
+
+// HealthCheck reports an error, if the dependency isn't ready.
+type HealthCheck func(ctx context.Context) error
+
+type namedHealthCheck struct {
+	name  string
+	check HealthCheck
+}
+
+// HealthResult is a status of the dependency.
+type HealthResult struct {
+	Status string `json:"status" example:"ok"`
+	Error  string `json:"error,omitempty"`
+}
+
+// HealthResponse is a status of the service with details per dependency.
+type HealthResponse struct {
+	Status string                  `json:"status" example:"ok"`
+	Checks map[string]HealthResult `json:"checks,omitempty"`
+}
+
+// HealthHandler answers liveness and readiness probes. The service is
+// live while it answers, it's ready, while all checks pass and it isn't
+// shutting down.
+type HealthHandler struct {
+	mu           sync.RWMutex
+	checks       []namedHealthCheck
+	timeout      time.Duration
+	shuttingDown atomic.Bool
+}
+
+func NewHealthHandler(g gin.IRoutes, timeout time.Duration) *HealthHandler {
+	if timeout <= 0 {
+		timeout = DefaultHealthCheckTimeout
+	}
+	h := &HealthHandler{timeout: timeout}
+	h.registerRoutes(g)
+	return h
+}
+
+func (h *HealthHandler) registerRoutes(g gin.IRoutes) {
+	g.GET("/healthz", h.live)
+	g.GET("/readyz", h.ready)
+}
+
+// AddCheck adds the readiness check of the dependency.
+func (h *HealthHandler) AddCheck(name string, check HealthCheck) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks = append(h.checks, namedHealthCheck{name: name, check: check})
+}
+
+// Shutdown fails readiness, so no new requests are routed to the server
+// while it finishes the current ones.
+func (h *HealthHandler) Shutdown() {
+	h.shuttingDown.Store(true)
+}
+
+// live godoc
+// @Summary      Liveness
+// @Description  Answers while the process is alive
+// @Tags         health
+// @Produce      json
+// @Success      200  {object}  HealthResponse
+// @Router       /healthz [get]
+func (h *HealthHandler) live(c *gin.Context) {
+	c.JSON(http.StatusOK, HealthResponse{Status: HealthStatusOK})
+}
+
+// ready godoc
+// @Summary      Readiness
+// @Description  Checks the database, migrations and whether the server is shutting down
+// @Tags         health
+// @Produce      json
+// @Success      200  {object}  HealthResponse
+// @Failure      503  {object}  HealthResponse
+// @Router       /readyz [get]
+func (h *HealthHandler) ready(c *gin.Context) {
+	const op = "handler.ready"
+	log, ctx := prepareTools(c, op)
+
+	h.mu.RLock()
+	checks := h.checks
+	h.mu.RUnlock()
+
+	resp := HealthResponse{Status: HealthStatusOK, Checks: make(map[string]HealthResult, len(checks)+1)}
+	results := make([]HealthResult, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, h.timeout)
+			defer cancel()
+			results[i] = healthResult(check.check(ctx))
+		}()
+	}
+	wg.Wait()
+
+	for i, check := range checks {
+		resp.Checks[check.name] = results[i]
+	}
+	if h.shuttingDown.Load() {
+		resp.Checks[healthCheckShutdown] = healthResult(ErrShuttingDown)
+	} else {
+		resp.Checks[healthCheckShutdown] = healthResult(nil)
+	}
+
+	status := http.StatusOK
+	for name, res := range resp.Checks {
+		if res.Status != HealthStatusOK {
+			resp.Status = HealthStatusFail
+			status = http.StatusServiceUnavailable
+			log.Warn().Str("check", name).Str("error", res.Error).Msg("not ready")
+		}
+	}
+	c.JSON(status, resp)
+}
+
+func healthResult(err error) HealthResult {
+	if err != nil {
+		return HealthResult{Status: HealthStatusFail, Error: err.Error()}
+	}
+	return HealthResult{Status: HealthStatusOK}
+}
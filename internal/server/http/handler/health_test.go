@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthHandler(t *testing.T) {
+	logger.InitLoggerByFlag(defaultLogLevel, true)
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	h := NewHealthHandler(router, 0)
+	var dbErr error
+	h.AddCheck("database", func(context.Context) error { return dbErr })
+
+	get := func(path string) (int, HealthResponse) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		var resp HealthResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return w.Code, resp
+	}
+
+	code, resp := get("/healthz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, HealthResponse{Status: HealthStatusOK}, resp)
+
+	code, resp = get("/readyz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, HealthResponse{Status: HealthStatusOK, Checks: map[string]HealthResult{
+		"database": {Status: HealthStatusOK},
+		"shutdown": {Status: HealthStatusOK},
+	}}, resp)
+
+	dbErr = errors.New("connection refused")
+	code, resp = get("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, HealthStatusFail, resp.Status)
+	assert.Equal(t, HealthResult{Status: HealthStatusFail, Error: "connection refused"}, resp.Checks["database"])
+
+	dbErr = nil
+	h.Shutdown()
+	code, resp = get("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, HealthResult{Status: HealthStatusFail, Error: ErrShuttingDown.Error()}, resp.Checks["shutdown"])
+
+	// The process is still alive while shutting down.
+	code, _ = get("/healthz")
+	assert.Equal(t, http.StatusOK, code)
+}
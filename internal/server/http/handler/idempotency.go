@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/pkg/api/problem"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/storage"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/tenant"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+const (
+	HeaderIdempotencyKey      = "Idempotency-Key"
+	HeaderIdempotentReplayed  = "Idempotent-Replayed"
+	maxIdempotencyKeyLen      = 255
+	DefaultIdempotencyKeysTTL = 24 * time.Hour
+	// DefaultIdempotencyLease is how long a request in progress holds its key.
+	DefaultIdempotencyLease = time.Minute
+	// DefaultIdempotencyMaxBody limits bodies of requests read to hash them.
+	DefaultIdempotencyMaxBody = 16 << 20
+)
+
+// idempotencyWriter keeps a copy of the response body to store it.
+type idempotencyWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *idempotencyWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *idempotencyWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// IdempotencyMiddleware makes mutating requests with the Idempotency-Key
+// header safe to retry. The response of the first request is stored for
+// ttl and replayed for repeats of the key, while reuse of the key for
+// another request is rejected. Keys are scoped by the tenant and the
+// authenticated user. Bodies of such requests are read to memory, so ones
+// larger than maxBody bytes are rejected with 413.
+// Server errors are not stored, so such requests can be retried. The request
+// in progress holds the key for lease only, so the key is freed for retries
+// soon, if the process dies before the response is stored.
+func IdempotencyMiddleware(store storage.IdempotencyKeys, ttl, lease time.Duration, maxBody int64) gin.HandlerFunc {
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyKeysTTL
+	}
+	if lease <= 0 {
+		lease = DefaultIdempotencyLease
+	}
+	if maxBody <= 0 {
+		maxBody = DefaultIdempotencyMaxBody
+	}
+
+	return func(c *gin.Context) {
+		const op = "handler.idempotency"
+
+		key := c.GetHeader(HeaderIdempotencyKey)
+		if key == "" || !isMutatingMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLen {
+			writeBadRequest(c, "Idempotency-Key must be at most 255 characters")
+			c.Abort()
+			return
+		}
+
+		log := zerolog.Ctx(c.Request.Context()).With().Str("op", op).Str("idempotency_key", key).Logger()
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBody))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeErrorResponse(c, http.StatusRequestEntityTooLarge, problem.CodePayloadTooLarge,
+				fmt.Sprintf("request body must be at most %d bytes", tooLarge.Limit), nil)
+			c.Abort()
+			return
+		}
+		if err != nil {
+			writeBadRequest(c, "can't read request body: "+err.Error())
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		rec := &storage.IdempotencyRecord{
+			Scope:       tenant.FromContext(c.Request.Context()) + "/" + c.GetString(gin.AuthUserKey),
+			Key:         key,
+			Method:      c.Request.Method,
+			Path:        c.Request.URL.RequestURI(),
+			RequestHash: requestHash(c.Request, body),
+			ExpiresAt:   time.Now().Add(lease),
+		}
+
+		existing, err := store.Acquire(c.Request.Context(), rec)
+		if err != nil {
+			writeError(c, log, err, "error checking idempotency key")
+			c.Abort()
+			return
+		}
+		if existing != nil {
+			replayIdempotent(c, rec, existing)
+			c.Abort()
+			return
+		}
+
+		w := &idempotencyWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+
+		// The response is sent already, so it's stored even if the client is gone.
+		ctx := context.WithoutCancel(c.Request.Context())
+		if w.Status() >= http.StatusInternalServerError || w.Status() == http.StatusTooManyRequests {
+			if err := store.Release(ctx, rec.Scope, rec.Key); err != nil {
+				log.Error().Err(err).Msg("error releasing idempotency key")
+			}
+			return
+		}
+
+		rec.Status = w.Status()
+		rec.ContentType = w.Header().Get("Content-Type")
+		rec.Body = w.body.Bytes()
+		rec.ExpiresAt = time.Now().Add(ttl)
+		if err := store.Complete(ctx, rec); err != nil {
+			log.Error().Err(err).Msg("error storing idempotent response")
+		}
+	}
+}
+
+// replayIdempotent answers to the repeat of the request with the stored response.
+func replayIdempotent(c *gin.Context, rec, existing *storage.IdempotencyRecord) {
+	switch {
+	case existing.RequestHash != rec.RequestHash:
+		writeErrorResponse(c, http.StatusUnprocessableEntity, problem.CodeIdempotencyKeyReused,
+			"Idempotency-Key is already used for another request", nil)
+	case existing.Status == 0:
+		writeErrorResponse(c, http.StatusConflict, problem.CodeIdempotencyKeyInProgress,
+			"request with the Idempotency-Key is in progress", nil)
+	default:
+		c.Header(HeaderIdempotentReplayed, "true")
+		c.Data(existing.Status, existing.ContentType, existing.Body)
+	}
+}
+
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// requestHash identifies the request by method, URI and body.
+func requestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/storage"
+	mock_storage "github.com/ikotiki/go-rest-api-service-subscriptions/internal/storage/mocks"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	logger.InitLoggerByFlag(defaultLogLevel, true)
+	gin.SetMode(gin.TestMode)
+
+	const body = `{"service_name":"Netflix"}`
+	hash := requestHash(httptest.NewRequest(http.MethodPost, "/subscription/", nil), []byte(body))
+
+	tests := []struct {
+		name       string
+		key        string
+		maxBody    int64
+		mock       func(store *mock_storage.MockIdempotencyKeys)
+		status     int
+		wantStatus int
+		wantCalls  int
+		wantBody   string
+	}{
+		{
+			name:       "Ok (no key)",
+			mock:       func(store *mock_storage.MockIdempotencyKeys) {},
+			status:     http.StatusCreated,
+			wantStatus: http.StatusCreated,
+			wantCalls:  1,
+			wantBody:   "created",
+		},
+		{
+			name: "Ok (first)",
+			key:  "k1",
+			mock: func(store *mock_storage.MockIdempotencyKeys) {
+				store.EXPECT().Acquire(mock.Anything, mock.MatchedBy(func(rec *storage.IdempotencyRecord) bool {
+					return rec.Key == "k1" && rec.RequestHash == hash && time.Until(rec.ExpiresAt) <= DefaultIdempotencyLease
+				})).Return(nil, nil).Once()
+				store.EXPECT().Complete(mock.Anything, mock.MatchedBy(func(rec *storage.IdempotencyRecord) bool {
+					return rec.Status == http.StatusCreated && string(rec.Body) == "created" &&
+						time.Until(rec.ExpiresAt) > DefaultIdempotencyKeysTTL-time.Minute
+				})).Return(nil).Once()
+			},
+			status:     http.StatusCreated,
+			wantStatus: http.StatusCreated,
+			wantCalls:  1,
+			wantBody:   "created",
+		},
+		{
+			name: "Ok (replay)",
+			key:  "k1",
+			mock: func(store *mock_storage.MockIdempotencyKeys) {
+				store.EXPECT().Acquire(mock.Anything, mock.Anything).Return(&storage.IdempotencyRecord{
+					RequestHash: hash, Status: http.StatusCreated, ContentType: "text/plain", Body: []byte("created"),
+				}, nil).Once()
+			},
+			wantStatus: http.StatusCreated,
+			wantBody:   "created",
+		},
+		{
+			name: "Error (reused)",
+			key:  "k1",
+			mock: func(store *mock_storage.MockIdempotencyKeys) {
+				store.EXPECT().Acquire(mock.Anything, mock.Anything).Return(&storage.IdempotencyRecord{
+					RequestHash: "other", Status: http.StatusCreated,
+				}, nil).Once()
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "Error (in progress)",
+			key:  "k1",
+			mock: func(store *mock_storage.MockIdempotencyKeys) {
+				store.EXPECT().Acquire(mock.Anything, mock.Anything).Return(&storage.IdempotencyRecord{RequestHash: hash}, nil).Once()
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name: "Ok (server error released)",
+			key:  "k2",
+			mock: func(store *mock_storage.MockIdempotencyKeys) {
+				store.EXPECT().Acquire(mock.Anything, mock.Anything).Return(nil, nil).Once()
+				store.EXPECT().Release(mock.Anything, "default/", "k2").Return(nil).Once()
+			},
+			status:     http.StatusInternalServerError,
+			wantStatus: http.StatusInternalServerError,
+			wantCalls:  1,
+			wantBody:   "created",
+		},
+		{
+			name:       "Error (too large)",
+			key:        "k3",
+			maxBody:    8,
+			mock:       func(store *mock_storage.MockIdempotencyKeys) {},
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := mock_storage.NewMockIdempotencyKeys(t)
+			tt.mock(store)
+
+			calls := 0
+			router := gin.New()
+			router.Use(IdempotencyMiddleware(store, 0, 0, tt.maxBody))
+			router.POST("/subscription/", func(c *gin.Context) {
+				calls++
+				c.String(tt.status, "created")
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/subscription/", strings.NewReader(body))
+			if tt.key != "" {
+				req.Header.Set(HeaderIdempotencyKey, tt.key)
+			}
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantCalls, calls)
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const contentTypeCSV = "text/csv"
+
+type importQuery struct {
+	// Map holds "field=column" pairs of the column mapping.
+	Map        []string `form:"map" example:"user_id=User"`
+	DateFormat string   `form:"date_format" example:"02.01.2006"`
+	DryRun     bool     `form:"dry_run" example:"false"`
+}
+
+// importSubscriptions godoc
+// @Summary      Import Subscriptions
+// @Description  Import subscriptions from a CSV file with a header row.
+// @Description  Columns are matched to fields by name, unless mapped by map=field=column.
+// @Description  Duplicates of existing subscriptions are skipped, invalid rows are reported.
+// @Tags         subscriptions
+// @Accept       text/csv
+// @Produce      json
+// @Param        map          query    []string  false  "column mapping, e.g. user_id=User"  collectionFormat(multi)
+// @Param        date_format  query    string    false  "Go layout of dates"  default(2006-01-02)
+// @Param        dry_run      query    bool      false  "check rows without creating subscriptions"
+// @Success      200  {object}  respSuc{obj=service.ImportReport}
+// @Failure      400  {object}  respErr
+// @Failure      415  {object}  respErr
+// @Failure      500  {object}  respErr
+// @Router       /subscription/import	 [post]
+func (a *SubscriptionHandler) importSubscriptions(c *gin.Context) {
+	const op = "handler.importSubscriptions"
+	log, ctx := prepareTools(c, op)
+
+	if c.ContentType() != contentTypeCSV {
+		writeFailure(c, http.StatusUnsupportedMediaType, "content type should be "+contentTypeCSV)
+		return
+	}
+
+	query := &importQuery{}
+	if err := c.ShouldBindQuery(query); err != nil {
+		writeBadRequest(c, "error binding query: "+err.Error())
+		return
+	}
+	mapping, err := parseImportMapping(query.Map)
+	if err != nil {
+		writeBadRequest(c, err.Error())
+		return
+	}
+
+	report, err := a.sub.Import(ctx, c.Request.Body, &service.ImportOptions{
+		Mapping:    mapping,
+		DateFormat: query.DateFormat,
+		DryRun:     query.DryRun,
+	})
+	if err != nil {
+		writeError(c, log, err, "error importing subscriptions on the server")
+		return
+	}
+
+	log.Info().Bool("dry_run", report.DryRun).Int("created", report.Created).
+		Int("skipped", report.Skipped).Int("invalid", report.Invalid).Msg("subscriptions imported")
+
+	writeObj(c, report)
+}
+
+// parseImportMapping parses "field=column" pairs.
+func parseImportMapping(pairs []string) (map[string]string, error) {
+	mapping := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		field, column, ok := strings.Cut(pair, "=")
+		if !ok || field == "" || column == "" {
+			return nil, fmt.Errorf("invalid mapping %q, should be field=column", pair)
+		}
+		mapping[strings.TrimSpace(field)] = strings.TrimSpace(column)
+	}
+	return mapping, nil
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/auth"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/pkg/api/problem"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LogLevelHandler reads and changes the level of the global logger at
+// runtime, e.g. to debug a live issue without a restart.
+type LogLevelHandler struct{}
+
+type logLevel struct {
+	Level string `json:"level" binding:"required" example:"debug"`
+}
+
+func NewLogLevelHandler(g *gin.RouterGroup) *LogLevelHandler {
+	a := &LogLevelHandler{}
+	a.registerRoutes(g)
+	return a
+}
+
+func (a *LogLevelHandler) registerRoutes(g *gin.RouterGroup) {
+	lvl := g.Group("/admin/log-level")
+	{
+		lvl.GET("", Authorize(auth.OpLogLevelRead), a.getLogLevel)
+		lvl.PUT("", Authorize(auth.OpLogLevelWrite), a.setLogLevel)
+	}
+}
+
+// getLogLevel godoc
+// @Summary      Log level
+// @Description  Get the current level of logs
+// @Tags         admin
+// @Produce      json
+// @Success      200  {object}  respSuc{obj=logLevel}
+// @Failure      403  {object}  respErr
+// @Router       /admin/log-level [get]
+func (a *LogLevelHandler) getLogLevel(c *gin.Context) {
+	writeObj(c, logLevel{Level: logger.Level()})
+}
+
+// setLogLevel godoc
+// @Summary      Set log level
+// @Description  Change the level of logs until the restart: fatal, panic, error, warn, info, debug or trace
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        input  body      logLevel  true  "Log level"
+// @Success      200    {object}  respSuc{obj=logLevel}
+// @Failure      400    {object}  respErr
+// @Failure      403    {object}  respErr
+// @Router       /admin/log-level [put]
+func (a *LogLevelHandler) setLogLevel(c *gin.Context) {
+	const op = "handler.setLogLevel"
+	log, _ := prepareTools(c, op)
+
+	var req logLevel
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeBadRequest(c, "error binding json: "+err.Error())
+		return
+	}
+
+	prev := logger.Level()
+	if err := logger.SetLevel(req.Level); err != nil {
+		writeErrorResponse(c, http.StatusBadRequest, problem.CodeInvalidLogLevel, err.Error(), nil)
+		return
+	}
+
+	log.Warn().Str("from", prev).Str("to", req.Level).Str("by", c.GetString(gin.AuthUserKey)).Msg("log level changed")
+	writeObj(c, logLevel{Level: logger.Level()})
+}
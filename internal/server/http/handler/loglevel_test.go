@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/auth"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/logger"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogLevelHandler(t *testing.T) {
+	logger.InitLoggerByFlag(defaultLogLevel, true)
+	gin.SetMode(gin.TestMode)
+	prev := zerolog.GlobalLevel()
+	defer zerolog.SetGlobalLevel(prev)
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+
+	router := gin.New()
+	router.Use(AuthMiddleware(auth.NewBasicAuthenticator(
+		map[string]string{"admin": "secret", "auditor": "secret"},
+		map[string]*auth.Principal{
+			"admin":   {Name: "admin", Admin: true},
+			"auditor": {Name: "auditor", Roles: []string{auth.RoleAuditor}},
+		},
+	)), PolicyMiddleware(auth.DefaultPolicy()))
+	NewLogLevelHandler(router.Group(""))
+
+	tests := []struct {
+		name       string
+		method     string
+		user       string
+		body       string
+		wantStatus int
+		wantBody   string
+		wantLevel  string
+	}{
+		{name: "Ok (get)", method: http.MethodGet, user: "auditor", wantStatus: http.StatusOK, wantBody: `"level":"info"`, wantLevel: "info"},
+		{name: "Ok (set)", method: http.MethodPut, user: "admin", body: `{"level":"debug"}`, wantStatus: http.StatusOK, wantBody: `"level":"debug"`, wantLevel: "debug"},
+		{name: "Error (invalid)", method: http.MethodPut, user: "admin", body: `{"level":"verbose"}`, wantStatus: http.StatusBadRequest, wantLevel: "debug"},
+		{name: "Error (forbidden)", method: http.MethodPut, user: "auditor", body: `{"level":"trace"}`, wantStatus: http.StatusForbidden, wantLevel: "debug"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, "/admin/log-level", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.SetBasicAuth(tt.user, "secret")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantBody != "" {
+				assert.Contains(t, w.Body.String(), tt.wantBody)
+			}
+			assert.Equal(t, tt.wantLevel, logger.Level())
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	microservice "github.com/ikotiki/go-rest-api-service-subscriptions"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/auth"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type MemberHandler struct {
+	member service.Members
+}
+
+func NewMemberHandler(g *gin.RouterGroup, service service.Members) *MemberHandler {
+	a := &MemberHandler{
+		member: service,
+	}
+	a.registerRoutes(g)
+	return a
+}
+
+func (a *MemberHandler) registerRoutes(g *gin.RouterGroup) {
+	sub := g.Group("/subscription/:id/members")
+	{
+		sub.GET("/", Authorize(auth.OpSubscriptionRead), a.getMembers)
+		sub.POST("/", Authorize(auth.OpSubscriptionWrite), a.addMember)
+		sub.DELETE("/:user_id", Authorize(auth.OpSubscriptionWrite), a.removeMember)
+	}
+}
+
+// getMembers godoc
+// @Summary      Subscription Members
+// @Description  Get users sharing the cost of the subscription
+// @Tags         members
+// @Produce      json
+// @Param        id    path     microservice.SubscriptionID true  "id of the subscription"  minimum(1)
+// @Success      200  {object}  respSuc{obj=[]microservice.Member}
+// @Failure      400  {object}  respErr
+// @Failure      404  {object}  respErr
+// @Failure      500  {object}  respErr
+// @Router       /subscription/{id}/members/	 [get]
+func (a *MemberHandler) getMembers(c *gin.Context) {
+	const op = "handler.getMembers"
+	log, ctx := prepareTools(c, op)
+
+	id, err := parseID(c, "id")
+	if err != nil {
+		log.Debug().Err(err).Str("id", c.Param("id")).Msg("can't parse subscription id")
+		writeBadRequest(c, err.Error())
+		return
+	}
+
+	members, err := a.member.ListBySubscription(ctx, id)
+	if err != nil {
+		writeError(c, log, err, "error getting members on the server")
+		return
+	}
+
+	writeObj(c, members)
+}
+
+// addMember godoc
+// @Summary      Share Subscription
+// @Description  Add the user to the subscription members or change the user's share.
+// @Description  Exactly one of share_percent and share_amount should be set, the owner pays the rest of the price.
+// @Tags         members
+// @Accept       json
+// @Produce      json
+// @Param        id      path     microservice.SubscriptionID true  "id of the subscription"  minimum(1)
+// @Param        member  body     microservice.Member  true  "member and the share"
+// @Success      200  {object}  respSuc{obj=[]microservice.Member}
+// @Failure      400  {object}  respErr
+// @Failure      404  {object}  respErr
+// @Failure      422  {object}  respErr
+// @Failure      500  {object}  respErr
+// @Router       /subscription/{id}/members/	 [post]
+func (a *MemberHandler) addMember(c *gin.Context) {
+	const op = "handler.addMember"
+	log, ctx := prepareTools(c, op)
+
+	id, err := parseID(c, "id")
+	if err != nil {
+		log.Debug().Err(err).Str("id", c.Param("id")).Msg("can't parse subscription id")
+		writeBadRequest(c, err.Error())
+		return
+	}
+
+	m := &microservice.Member{}
+	if err := c.ShouldBindJSON(m); err != nil {
+		writeBadRequest(c, "error binding json: "+err.Error())
+		return
+	}
+	m.SubscriptionID = id
+
+	members, err := a.member.Add(ctx, m)
+	if err != nil {
+		writeError(c, log, err, "error adding member on the server")
+		return
+	}
+
+	writeObj(c, members)
+}
+
+// removeMember godoc
+// @Summary      Unshare Subscription
+// @Description  Remove the user from the subscription members
+// @Tags         members
+// @Produce      json
+// @Param        id       path     microservice.SubscriptionID true  "id of the subscription"  minimum(1)
+// @Param        user_id  path     string true  "id of the member"
+// @Success      200  {object}  respSucNoObj
+// @Failure      400  {object}  respErr
+// @Failure      404  {object}  respErr
+// @Failure      500  {object}  respErr
+// @Router       /subscription/{id}/members/{user_id}	 [delete]
+func (a *MemberHandler) removeMember(c *gin.Context) {
+	const op = "handler.removeMember"
+	log, ctx := prepareTools(c, op)
+
+	id, err := parseID(c, "id")
+	if err != nil {
+		log.Debug().Err(err).Str("id", c.Param("id")).Msg("can't parse subscription id")
+		writeBadRequest(c, err.Error())
+		return
+	}
+
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil || userID == uuid.Nil {
+		writeBadRequest(c, service.ErrNoUserID.Error())
+		return
+	}
+
+	if err = a.member.Remove(ctx, id, userID); err != nil {
+		writeError(c, log, err, "error removing member on the server")
+		return
+	}
+
+	writeOK(c)
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/auth"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/pkg/api/problem"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Route groups limited separately by RateLimitMiddleware.
+const (
+	RouteGroupSubscriptions = "subscriptions"
+	RouteGroupReports       = "reports"
+	RouteGroupAdmin         = "admin"
+)
+
+// RouteGroups are names of route groups registered by InitRoutes.
+var RouteGroups = []string{RouteGroupSubscriptions, RouteGroupReports, RouteGroupAdmin}
+
+const (
+	HeaderRateLimitLimit     = "RateLimit-Limit"
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	HeaderRateLimitReset     = "RateLimit-Reset"
+	HeaderRetryAfter         = "Retry-After"
+)
+
+// RateLimitMiddleware limits requests of every client to the route group:
+// authenticated principals (users and API keys) by name, others by IP.
+// Requests pass, if the store fails, so it doesn't take the API down.
+func RateLimitMiddleware(store ratelimit.Store, group string, limit ratelimit.Limit) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const op = "handler.rateLimit"
+		log, ctx := prepareTools(c, op)
+
+		res, err := store.Take(ctx, group+"|"+rateLimitClient(c), limit)
+		if err != nil {
+			log.Error().Err(err).Str("group", group).Msg("error taking rate limit token")
+			c.Next()
+			return
+		}
+
+		c.Header(HeaderRateLimitLimit, strconv.Itoa(res.Limit))
+		c.Header(HeaderRateLimitRemaining, strconv.Itoa(res.Remaining))
+		c.Header(HeaderRateLimitReset, ceilSeconds(res.Reset))
+		if !res.Allowed {
+			c.Header(HeaderRetryAfter, ceilSeconds(res.RetryAfter))
+			writeErrorResponse(c, http.StatusTooManyRequests, problem.CodeTooManyRequests, "rate limit exceeded", nil)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// rateLimitClient identifies the client of the request.
+func rateLimitClient(c *gin.Context) string {
+	if p, ok := auth.FromContext(c.Request.Context()); ok {
+		return "principal:" + p.Name
+	}
+	return "ip:" + c.ClientIP()
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
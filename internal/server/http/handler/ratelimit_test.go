@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/auth"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/ratelimit"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+type rateLimitStoreFunc func(key string) (ratelimit.Result, error)
+
+func (f rateLimitStoreFunc) Take(_ context.Context, key string, _ ratelimit.Limit) (ratelimit.Result, error) {
+	return f(key)
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	logger.InitLoggerByFlag(defaultLogLevel, true)
+	gin.SetMode(gin.TestMode)
+
+	store := ratelimit.NewMemoryStore()
+	router := gin.New()
+	router.Use(AuthMiddleware(
+		auth.NewBasicAuthenticator(map[string]string{"alice": "secret", "bob": "secret"}, nil),
+	))
+	router.GET("/sum", RateLimitMiddleware(store, RouteGroupReports, ratelimit.Limit{Rate: 0.1, Burst: 1}),
+		func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	get := func(user string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/sum", nil)
+		req.SetBasicAuth(user, "secret")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("alice")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1", w.Header().Get(HeaderRateLimitLimit))
+	assert.Equal(t, "0", w.Header().Get(HeaderRateLimitRemaining))
+	assert.Equal(t, "10", w.Header().Get(HeaderRateLimitReset))
+	assert.Empty(t, w.Header().Get(HeaderRetryAfter))
+
+	w = get("alice")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "10", w.Header().Get(HeaderRetryAfter))
+	assert.Contains(t, w.Body.String(), "rate limit exceeded")
+
+	// Users are limited separately.
+	assert.Equal(t, http.StatusOK, get("bob").Code)
+}
+
+func TestRateLimitMiddleware_StoreError(t *testing.T) {
+	logger.InitLoggerByFlag(defaultLogLevel, true)
+	gin.SetMode(gin.TestMode)
+
+	var key string
+	store := rateLimitStoreFunc(func(k string) (ratelimit.Result, error) {
+		key = k
+		return ratelimit.Result{}, errors.New("store is down")
+	})
+	router := gin.New()
+	router.GET("/sum", RateLimitMiddleware(store, RouteGroupReports, ratelimit.Limit{Rate: 1, Burst: 1}),
+		func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/sum", nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "reports|ip:192.0.2.1", key)
+}
+
+func TestRateLimitMiddleware_ForwardedFor(t *testing.T) {
+	logger.InitLoggerByFlag(defaultLogLevel, true)
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name    string
+		proxies []string
+		want    string
+	}{
+		{name: "Spoofed", proxies: nil, want: "reports|ip:192.0.2.1"},
+		{name: "Trusted proxy", proxies: []string{"192.0.2.0/24"}, want: "reports|ip:198.51.100.7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var key string
+			store := rateLimitStoreFunc(func(k string) (ratelimit.Result, error) {
+				key = k
+				return ratelimit.Result{Allowed: true}, nil
+			})
+			router := gin.New()
+			assert.NoError(t, router.SetTrustedProxies(tt.proxies))
+			router.GET("/sum", RateLimitMiddleware(store, RouteGroupReports, ratelimit.Limit{Rate: 1, Burst: 1}),
+				func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/sum", nil)
+			req.RemoteAddr = "192.0.2.1:1234"
+			req.Header.Set("X-Forwarded-For", "198.51.100.7")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.want, key)
+		})
+	}
+}
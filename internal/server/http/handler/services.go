@@ -0,0 +1,233 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	microservice "github.com/ikotiki/go-rest-api-service-subscriptions"
+
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/auth"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/service"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/pkg/e"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ServiceHandler struct {
+	svc service.Services
+}
+
+func NewServiceHandler(g *gin.RouterGroup, service service.Services) *ServiceHandler {
+	a := &ServiceHandler{
+		svc: service,
+	}
+	a.registerRoutes(g)
+	return a
+}
+
+func (a *ServiceHandler) registerRoutes(g *gin.RouterGroup) {
+	svc := g.Group("/service")
+	{
+		svc.GET("/", Authorize(auth.OpCatalogRead), a.listServices)
+		svc.GET("/:id", Authorize(auth.OpCatalogRead), a.getServiceByID)
+		svc.GET("/by-name/:name", Authorize(auth.OpCatalogRead), a.getServiceByName)
+		svc.POST("/", Authorize(auth.OpCatalogWrite), a.createService)
+		svc.PUT("/:id", Authorize(auth.OpCatalogWrite), a.updateService)
+		svc.DELETE("/:id", Authorize(auth.OpCatalogWrite), a.deleteService)
+	}
+}
+
+// listServices godoc
+// @Summary      Services catalog
+// @Description  Get all services of the catalog
+// @Tags         services
+// @Produce      json
+// @Success      200  {object}  respSuc{obj=[]microservice.Service}
+// @Failure      500  {object}  respErr
+// @Router       /service/	 [get]
+func (a *ServiceHandler) listServices(c *gin.Context) {
+	const op = "handler.listServices"
+	log, ctx := prepareTools(c, op)
+
+	svcs, err := a.svc.List(ctx)
+	if err != nil {
+		writeError(c, log, err, "error getting services on the server")
+		return
+	}
+
+	writeObj(c, svcs)
+}
+
+// getServiceByID godoc
+// @Summary      Service By ID
+// @Description  Get catalog service by its id
+// @Tags         services
+// @Produce      json
+// @Param        id    path     microservice.ServiceID true  "id of the service"  minimum(1)
+// @Success      200  {object}  respSuc{obj=microservice.Service}
+// @Failure      400  {object}  respErr
+// @Failure      404  {object}  respErr
+// @Failure      500  {object}  respErr
+// @Router       /service/{id}	 [get]
+func (a *ServiceHandler) getServiceByID(c *gin.Context) {
+	const op = "handler.getServiceByID"
+	log, ctx := prepareTools(c, op)
+
+	id, err := a.parseServiceID(c)
+	if err != nil {
+		log.Debug().Err(err).Str("id", c.Param("id")).Msg("can't parse service id")
+		writeBadRequest(c, err.Error())
+		return
+	}
+
+	svc, err := a.svc.GetByID(ctx, id)
+	if err != nil {
+		writeError(c, log, err, "error getting service on the server")
+		return
+	}
+
+	writeObj(c, svc)
+}
+
+// getServiceByName godoc
+// @Summary      Service By Name
+// @Description  Find catalog service by its name or alias, case and spacing insensitive
+// @Tags         services
+// @Produce      json
+// @Param        name    path     string true  "name or alias of the service"
+// @Success      200  {object}  respSuc{obj=microservice.Service}
+// @Failure      404  {object}  respErr
+// @Failure      500  {object}  respErr
+// @Router       /service/by-name/{name}	 [get]
+func (a *ServiceHandler) getServiceByName(c *gin.Context) {
+	const op = "handler.getServiceByName"
+	log, ctx := prepareTools(c, op)
+
+	svc, err := a.svc.GetByName(ctx, c.Param("name"))
+	if err != nil {
+		writeError(c, log, err, "error getting service on the server")
+		return
+	}
+
+	writeObj(c, svc)
+}
+
+// createService godoc
+// @Summary      Create Service
+// @Description  Add a new service to the catalog
+// @Tags         services
+// @Accept       json
+// @Produce      json
+// @Param        service  body     microservice.Service  true  "service object"
+// @Success      201  {object}  respSuc{obj=microservice.ServiceID}
+// @Failure      400  {object}  respErr
+// @Failure      422  {object}  respErr
+// @Failure      500  {object}  respErr
+// @Router       /service/	 [post]
+func (a *ServiceHandler) createService(c *gin.Context) {
+	const op = "handler.createService"
+	log, ctx := prepareTools(c, op)
+
+	svc := &microservice.Service{}
+	if err := c.ShouldBindJSON(svc); err != nil {
+		writeBadRequest(c, "error binding json: "+err.Error())
+		return
+	}
+
+	id, err := a.svc.Create(ctx, svc)
+	if err != nil {
+		writeError(c, log, err, "error creating service on the server")
+		return
+	}
+
+	log.Info().Int("id", int(id)).Msg("service created")
+
+	writeSuccess(c, http.StatusCreated, msgSuccess, id)
+}
+
+// updateService godoc
+// @Summary      Update Service
+// @Description  Update the catalog service
+// @Tags         services
+// @Accept       json
+// @Produce      json
+// @Param        id    path     microservice.ServiceID  true  "id of the service"  minimum(1)
+// @Param        service  body     microservice.Service  true  "service object"
+// @Success      200  {object}  respSucNoObj
+// @Failure      400  {object}  respErr
+// @Failure      404  {object}  respErr
+// @Failure      422  {object}  respErr
+// @Failure      500  {object}  respErr
+// @Router       /service/{id}	 [put]
+func (a *ServiceHandler) updateService(c *gin.Context) {
+	const op = "handler.updateService"
+	log, ctx := prepareTools(c, op)
+
+	id, err := a.parseServiceID(c)
+	if err != nil {
+		log.Debug().Err(err).Str("id", c.Param("id")).Msg("can't parse service id")
+		writeBadRequest(c, err.Error())
+		return
+	}
+
+	svc := &microservice.Service{}
+	if err := c.ShouldBindJSON(svc); err != nil {
+		writeBadRequest(c, "error binding json: "+err.Error())
+		return
+	}
+	svc.ID = id
+
+	if err = a.svc.Update(ctx, svc); err != nil {
+		writeError(c, log, err, "error updating service")
+		return
+	}
+
+	writeOK(c)
+}
+
+// deleteService godoc
+// @Summary      Delete Service
+// @Description  Delete the catalog service, subscriptions keep their service name
+// @Tags         services
+// @Produce      json
+// @Param        id    path     microservice.ServiceID  true  "id of the service"  minimum(1)
+// @Success      204  {object}  respSucNoObj
+// @Failure      400  {object}  respErr
+// @Failure      404  {object}  respErr
+// @Failure      500  {object}  respErr
+// @Router       /service/{id}	 [delete]
+func (a *ServiceHandler) deleteService(c *gin.Context) {
+	const op = "handler.deleteService"
+	log, ctx := prepareTools(c, op)
+
+	id, err := a.parseServiceID(c)
+	if err != nil {
+		log.Debug().Err(err).Str("id", c.Param("id")).Msg("can't parse service id")
+		writeBadRequest(c, err.Error())
+		return
+	}
+
+	if err = a.svc.DeleteByID(ctx, id); err != nil {
+		writeError(c, log, err, "error deleting service on the server")
+		return
+	}
+
+	log.Info().Int("id", int(id)).Msg("service deleted")
+
+	writeSuccess(c, http.StatusNoContent, msgSuccess, nil)
+}
+
+func (a *ServiceHandler) parseServiceID(c *gin.Context) (int64, error) {
+	idStr := c.Param("id")
+	if idStr == "" {
+		return 0, errors.New("can't parse service id: empty")
+	}
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return 0, e.Wrap(fmt.Sprintf("can't parse service id by string %s", idStr), err)
+	}
+
+	return id, nil
+}
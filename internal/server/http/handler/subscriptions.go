@@ -9,10 +9,12 @@ import (
 
 	microservice "github.com/ikotiki/go-rest-api-service-subscriptions"
 
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/auth"
 	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/service"
 	"github.com/ikotiki/go-rest-api-service-subscriptions/pkg/e"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type SubscriptionHandler struct {
@@ -24,21 +26,35 @@ func NewSubscriptionHandler(g *gin.RouterGroup, service service.Subscriptions) *
 		sub: service,
 	}
 	a.registerRoutes(g)
+	a.registerReportRoutes(g)
 	return a
 }
 
 func (a *SubscriptionHandler) registerRoutes(g *gin.RouterGroup) {
 	sub := g.Group("/subscription")
 	{
-		sub.GET("/:id", a.getSubscriptionByID)
-		sub.POST("/", a.createSubscription)
-		sub.PUT("/:id", a.updateSubscription)
-		sub.DELETE("/:id", a.deleteSubscription)
+		sub.GET("/:id", Authorize(auth.OpSubscriptionRead), a.getSubscriptionByID)
+		sub.POST("/", Authorize(auth.OpSubscriptionWrite), a.createSubscription)
+		sub.PUT("/:id", Authorize(auth.OpSubscriptionWrite), a.updateSubscription)
+		sub.DELETE("/:id", Authorize(auth.OpSubscriptionWrite), a.deleteSubscription)
+		sub.PUT("/by-key/:user_id/:service_name", Authorize(auth.OpSubscriptionWrite), a.upsertSubscription)
 
-		sub.GET("/query", a.querySubscriptions)
-		sub.GET("/sum", a.sumSubscriptions)
+		sub.GET("/query", Authorize(auth.OpSubscriptionRead), a.querySubscriptions)
+
+		sub.POST("/bulk", Authorize(auth.OpSubscriptionWrite), a.createSubscriptionsBulk)
+		sub.PUT("/bulk", Authorize(auth.OpSubscriptionWrite), a.updateSubscriptionsBulk)
+		sub.DELETE("/bulk", Authorize(auth.OpSubscriptionWrite), a.deleteSubscriptionsBulk)
+
+		sub.POST("/import", Authorize(auth.OpSubscriptionWrite), a.importSubscriptions)
 	}
+}
 
+func (a *SubscriptionHandler) registerReportRoutes(g *gin.RouterGroup) {
+	sub := g.Group("/subscription")
+	{
+		sub.GET("/sum", Authorize(auth.OpReportRead), a.sumSubscriptions)
+		sub.GET("/report/by-category", Authorize(auth.OpReportRead), a.reportByCategory)
+	}
 }
 
 // getSubscriptionByID godoc
@@ -69,13 +85,7 @@ func (a *SubscriptionHandler) getSubscriptionByID(c *gin.Context) {
 	// Get subscription
 	sub, err := a.sub.GetByID(ctx, id)
 	if err != nil {
-		if errors.Is(err, service.ErrNoSuchSubscription) {
-			writeNotFound(c, "no such subscription")
-			return
-		}
-		// logger.Error("error getting subscription", err)
-		log.Error().Err(err).Msg("error getting subscription")
-		writeServerInternal(c, "error getting subscription on the server")
+		writeError(c, log, err, "error getting subscription on the server")
 		return
 	}
 
@@ -92,7 +102,7 @@ func (a *SubscriptionHandler) getSubscriptionByID(c *gin.Context) {
 // @Param        subscription  body     microservice.Subscription  true  "subscription object"
 // @Success      201  {object}  respSuc{obj=microservice.SubscriptionID}
 // @Failure      400  {object}  respErr
-// @Failure      422  {object}  respErr
+// @Failure      422  {object}  respErr{obj=[]service.FieldError}
 // @Failure      500  {object}  respErr
 // @Router       /subscription/	 [post]
 func (a *SubscriptionHandler) createSubscription(c *gin.Context) {
@@ -107,12 +117,7 @@ func (a *SubscriptionHandler) createSubscription(c *gin.Context) {
 
 	id, err := a.sub.Create(ctx, sub)
 	if err != nil {
-		if errors.Is(err, service.ErrUserSubscriptionPairAlreadyExists) {
-			writeFailure(c, http.StatusUnprocessableEntity, "user-subscription pair already exists", nil)
-			return
-		}
-		log.Error().Err(err).Msg("error creating subscription")
-		writeServerInternal(c, "error creating subscription on the server")
+		writeError(c, log, err, "error creating subscription on the server")
 		return
 	}
 
@@ -123,7 +128,7 @@ func (a *SubscriptionHandler) createSubscription(c *gin.Context) {
 
 // updateSubscription godoc
 // @Summary      Update Subscription
-// @Description  Update the subscription
+// @Description  Update the subscription, id of the body may be omitted, but must match the path
 // @Tags         subscriptions
 // @Accept       json
 // @Produce      json
@@ -131,32 +136,107 @@ func (a *SubscriptionHandler) createSubscription(c *gin.Context) {
 // @Param        subscription  body     microservice.Subscription  true  "subscription object"
 // @Success      200  {object}  respSucNoObj
 // @Failure      400  {object}  respErr
-// @Failure      422  {object}  respErr
+// @Failure      422  {object}  respErr{obj=[]service.FieldError}
 // @Failure      500  {object}  respErr
 // @Router       /subscription/{id}	 [put]
 func (a *SubscriptionHandler) updateSubscription(c *gin.Context) {
 	const op = "handler.updateSubscription"
 	log, ctx := prepareTools(c, op)
 
+	id, err := a.parseSubscriptionID(c)
+	if err != nil {
+		log.Debug().Err(err).Str("id", c.Param("id")).Msg("can't parse subscription id")
+		writeBadRequest(c, err.Error())
+		return
+	}
+
 	sub := &microservice.Subscription{}
 	if err := c.ShouldBindJSON(sub); err != nil {
 		writeBadRequest(c, "error binding json: "+err.Error())
 		return
 	}
+	if sub.ID != 0 && sub.ID != id {
+		writeBadRequest(c, "id of the subscription doesn't match the path")
+		return
+	}
+	sub.ID = id
 
-	err := a.sub.Update(ctx, sub)
+	err = a.sub.Update(ctx, sub)
 	if err != nil {
-		if errors.Is(err, service.ErrNoSuchSubscription) {
-			writeNotFound(c, "no such subscription")
-		}
-		log.Error().Err(err).Msg("error updating subscription")
-		writeServerInternal(c, "error updating subscription")
+		writeError(c, log, err, "error updating subscription")
 		return
 	}
 
 	writeOK(c)
 }
 
+type upsertRequest struct {
+	CategoryID   *microservice.CategoryID `json:"category_id,omitempty"`
+	MonthlyPrice microservice.Price       `json:"monthly_price" example:"400"`
+	StartDate    microservice.Date        `json:"start_date" binding:"required"`
+	EndDate      microservice.Date        `json:"end_date,omitempty,omitzero"`
+}
+
+type upsertResponse struct {
+	ID      microservice.SubscriptionID `json:"id" example:"1"`
+	Created bool                        `json:"created" example:"true"`
+}
+
+// upsertSubscription godoc
+// @Summary      Upsert Subscription
+// @Description  Create the subscription of the user to the service or update the open one (without end date).
+// @Description  The subscription with end date ends the open one, if there is such.
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        user_id       path     string         true  "id of the user"  format(uuid)
+// @Param        service_name  path     string         true  "name of the service"
+// @Param        subscription  body     upsertRequest  true  "subscription fields"
+// @Success      200  {object}  respSuc{obj=upsertResponse}
+// @Success      201  {object}  respSuc{obj=upsertResponse}
+// @Failure      400  {object}  respErr
+// @Failure      422  {object}  respErr{obj=[]service.FieldError}
+// @Failure      500  {object}  respErr
+// @Router       /subscription/by-key/{user_id}/{service_name}	 [put]
+func (a *SubscriptionHandler) upsertSubscription(c *gin.Context) {
+	const op = "handler.upsertSubscription"
+	log, ctx := prepareTools(c, op)
+
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		writeBadRequest(c, "can't parse user id: "+err.Error())
+		return
+	}
+
+	req := &upsertRequest{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		writeBadRequest(c, "error binding json: "+err.Error())
+		return
+	}
+
+	sub := &microservice.Subscription{
+		UserID:       userID,
+		ServiceName:  c.Param("service_name"),
+		CategoryID:   req.CategoryID,
+		MonthlyPrice: req.MonthlyPrice,
+		StartDate:    req.StartDate,
+		EndDate:      req.EndDate,
+	}
+	id, created, err := a.sub.Upsert(ctx, sub)
+	if err != nil {
+		writeError(c, log, err, "error upserting subscription on the server")
+		return
+	}
+
+	log.Info().Int("id", int(id)).Bool("created", created).Msg("subscription upserted")
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	writeSuccess(c, status, msgSuccess, &upsertResponse{ID: id, Created: created})
+}
+
 // deleteSubscription godoc
 // @Summary      Delete Subscription
 // @Description  Delete a new subscription
@@ -184,13 +264,7 @@ func (a *SubscriptionHandler) deleteSubscription(c *gin.Context) {
 
 	err = a.sub.DeleteByID(ctx, id)
 	if err != nil {
-		if errors.Is(err, service.ErrNoSuchSubscription) {
-			log.Debug().Err(err).Msg("no such subscription")
-			writeNotFound(c, "no such subscription")
-			return
-		}
-		log.Error().Err(err).Msg("error deleting subscription")
-		writeServerInternal(c, "error deleting subscription on the server")
+		writeError(c, log, err, "error deleting subscription on the server")
 		return
 	}
 
@@ -201,10 +275,14 @@ func (a *SubscriptionHandler) deleteSubscription(c *gin.Context) {
 
 // querySubscriptions godoc
 // @Summary      Get Subscriptions
-// @Description  Get Subscriptions by a query
+// @Description  Get Subscriptions by a query.
+// @Description  With Accept: text/csv or application/x-ndjson results are streamed row by row,
+// @Description  an empty result is not an error then.
 // @Tags         subscriptions
 // @Accept       json
 // @Produce      json
+// @Produce      text/csv
+// @Produce      application/x-ndjson
 // @Param        query  body     service.SubscriptionQueryArgs  true  "query arguments"
 // @Success      200  {object}  respSuc{obj=[]microservice.Subscription}
 // @Failure      400  {object}  respErr
@@ -225,6 +303,11 @@ func (a *SubscriptionHandler) querySubscriptions(c *gin.Context) {
 		log.Debug().Msg("empty body")
 	}
 
+	if format := c.NegotiateFormat(gin.MIMEJSON, contentTypeCSV, contentTypeNDJSON); format != gin.MIMEJSON && format != "" {
+		a.streamSubscriptions(ctx, c, log, args, format)
+		return
+	}
+
 	subs, err := a.sub.Query(ctx, args)
 	if err != nil {
 		if errors.Is(err, service.ErrNoSuchSubscription) {
@@ -232,8 +315,7 @@ func (a *SubscriptionHandler) querySubscriptions(c *gin.Context) {
 			writeNotFound(c, "no subscriptions founds")
 			return
 		}
-		log.Error().Err(err).Msg("error getting subscriptions")
-		writeServerInternal(c, "error getting subscriptions")
+		writeError(c, log, err, "error getting subscriptions")
 		return
 	}
 	if len(subs) == 0 {
@@ -273,21 +355,14 @@ func (a *SubscriptionHandler) sumSubscriptions(c *gin.Context) {
 
 	sum, err := a.sub.Sum(ctx, args)
 	if err != nil {
-		switch {
-		case errors.Is(err, service.ErrNoUserID) || errors.Is(err, service.ErrNoSubscriptionID):
-			log.Debug().Err(err)
-			writeBadRequest(c, err.Error())
-			return
-		case errors.Is(err, service.ErrNoSuchSubscription):
+		if errors.Is(err, service.ErrNoSuchSubscription) {
 			log.Debug().Err(err).Msg("no subscriptions founds")
 			writeSuccess(c, http.StatusNotFound, "not found subscriptions for given args", 0)
 			// writeNotFound(c, "no subscriptions founds")
 			return
-		default:
-			log.Error().Err(err).Msg("error getting subscriptions sum")
-			writeServerInternal(c, "error getting subscriptions sum")
-			return
 		}
+		writeError(c, log, err, "error getting subscriptions sum")
+		return
 	}
 
 	log.Info().Interface("sum", int(sum)).Msg("subscriptions sum")
@@ -295,6 +370,40 @@ func (a *SubscriptionHandler) sumSubscriptions(c *gin.Context) {
 	writeObj(c, sum)
 }
 
+// reportByCategory godoc
+// @Summary      Costs By Category
+// @Description  Sum Subscriptions price by a query grouped by category
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        query  body    service.SubscriptionQueryArgs  true  "query arguments"
+// @Success      200  {object}  respSuc{obj=[]microservice.CategorySum}
+// @Failure      400  {object}  respErr
+// @Failure      500  {object}  respErr
+// @Router       /subscription/report/by-category	 [get]
+func (a *SubscriptionHandler) reportByCategory(c *gin.Context) {
+	const op = "handler.reportByCategory"
+	log, ctx := prepareTools(c, op)
+
+	args := &service.SubscriptionQueryArgs{}
+	if err := c.ShouldBindJSON(args); err != nil {
+		if !errors.Is(err, io.EOF) {
+			log.Debug().Err(err).Msg("error binding json")
+			writeBadRequest(c, "error binding json: "+err.Error())
+			return
+		}
+		log.Debug().Msg("empty body")
+	}
+
+	sums, err := a.sub.SumByCategory(ctx, args)
+	if err != nil {
+		writeError(c, log, err, "error getting subscriptions report")
+		return
+	}
+
+	writeObj(c, sums)
+}
+
 func (a *SubscriptionHandler) parseSubscriptionID(c *gin.Context) (int64, error) {
 	// Parse ID
 	idStr := c.Param("id")
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -13,6 +14,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	microservice "github.com/ikotiki/go-rest-api-service-subscriptions"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/service"
 	mock_service "github.com/ikotiki/go-rest-api-service-subscriptions/internal/service/mocks"
 	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/storage"
 	"github.com/ikotiki/go-rest-api-service-subscriptions/logger"
@@ -59,7 +61,7 @@ func Test_createSubscription(t *testing.T) {
 		{
 			name: "Ok",
 			mock: func() {
-				srv.EXPECT().Create(mock.Anything, mock.Anything).Return(1, nil)
+				srv.EXPECT().Create(mock.Anything, mock.Anything).Return(1, nil).Once()
 			},
 			input: &map[string]interface{}{
 				"user_id":       "3d6e2e6c-0d8a-4c1d-9b6f-3b1f9c2b1f9c",
@@ -72,9 +74,8 @@ func Test_createSubscription(t *testing.T) {
 		},
 		{
 			name: "Error (id)",
-			mock: func() {
-				srv.EXPECT().Create(mock.Anything, mock.Anything).Return(0, errors.New("error binding json: invalid UUID length"))
-			},
+			// Binding fails before the service is called.
+			mock: func() {},
 			input: &map[string]interface{}{
 				"user_id":       "3d6e2e6c-0d8a-4c1d-",
 				"service_name":  "test",
@@ -84,6 +85,23 @@ func Test_createSubscription(t *testing.T) {
 			want:    &resp{Obj: 0, Success: false, Msg: "error binding json: invalid UUID length"},
 			wantErr: true,
 		},
+		{
+			name: "Error (validation)",
+			mock: func() {
+				srv.EXPECT().Create(mock.Anything, mock.Anything).Return(0, &service.ValidationError{Fields: []service.FieldError{
+					{Field: "end_date", Message: "must be after start_date"},
+				}}).Once()
+			},
+			input: &map[string]interface{}{
+				"user_id":       "3d6e2e6c-0d8a-4c1d-9b6f-3b1f9c2b1f9c",
+				"service_name":  "test",
+				"monthly_price": 100,
+				"start_date":    "2020-01-01",
+				"end_date":      "2019-01-01",
+			},
+			want:    &resp{Obj: 0, Success: false, Msg: "end_date must be after start_date"},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -198,7 +216,9 @@ func Test_updateSubscription(t *testing.T) {
 		{
 			name: "Ok",
 			mock: func() {
-				srv.EXPECT().Update(mock.Anything, mock.Anything).Return(nil)
+				srv.EXPECT().Update(mock.Anything, mock.MatchedBy(func(sub *microservice.Subscription) bool {
+					return sub.ID == 1
+				})).Return(nil).Once()
 			},
 			input: &map[string]interface{}{
 				"user_id":       "3d6e2e6c-0d8a-4c1d-9b6f-3b1f9c2b1f9c",
@@ -210,6 +230,19 @@ func Test_updateSubscription(t *testing.T) {
 			want:    &resp{Obj: nil, Success: true, Msg: msgSuccess},
 			wantErr: false,
 		},
+		{
+			name: "Error (id of body)",
+			mock: func() {},
+			input: &map[string]interface{}{
+				"id":            2,
+				"user_id":       "3d6e2e6c-0d8a-4c1d-9b6f-3b1f9c2b1f9c",
+				"service_name":  "test",
+				"monthly_price": 100,
+				"start_date":    "2020-01-01",
+			},
+			want:    &resp{Obj: nil, Success: false, Msg: "id of the subscription doesn't match the path"},
+			wantErr: true,
+		},
 		{
 			name: "Error (id)",
 			mock: func() {
@@ -232,7 +265,8 @@ func Test_updateSubscription(t *testing.T) {
 
 			tt.mock()
 
-			c.Request = createTestRequest(t, "POST", "/subscription", tt.input)
+			c.Request = createTestRequest(t, "PUT", "/subscription/1", tt.input)
+			c.Params = append(c.Params, gin.Param{Key: "id", Value: "1"})
 			log.Debug().Interface("request", c.Request).Interface("body", c.Request.Body).Msg("request")
 
 			h.updateSubscription(c)
@@ -320,9 +354,9 @@ func Test_querySubscriptions(t *testing.T) {
 
 	time_day := 24 * time.Hour
 	subs := []*storage.Subscription{
-		{1, uuid.MustParse("123e4567-e89b-12d3-a456-426614174000"), "Yandex Taxi", 400, test_time, test_time.Add(2 * time_day)},
-		{2, uuid.MustParse("123e4567-e89b-12d3-a456-426614174001"), "Sberbank Shop", 200, test_time.Add(-120 * time_day), test_time.Add(-90 * time_day)},
-		{3, uuid.MustParse("123e4567-e89b-12d3-a456-426614174002"), "Ozon Sales", 300, test_time.Add(-60 * time_day), test_time.Add(-30 * time_day)},
+		{ID: 1, UserID: uuid.MustParse("123e4567-e89b-12d3-a456-426614174000"), ServiceName: "Yandex Taxi", MonthlyPrice: 400, StartDate: test_time, EndDate: test_time.Add(2 * time_day)},
+		{ID: 2, UserID: uuid.MustParse("123e4567-e89b-12d3-a456-426614174001"), ServiceName: "Sberbank Shop", MonthlyPrice: 200, StartDate: test_time.Add(-120 * time_day), EndDate: test_time.Add(-90 * time_day)},
+		{ID: 3, UserID: uuid.MustParse("123e4567-e89b-12d3-a456-426614174002"), ServiceName: "Ozon Sales", MonthlyPrice: 300, StartDate: test_time.Add(-60 * time_day), EndDate: test_time.Add(-30 * time_day)},
 	}
 	bytes, _ := json.Marshal(subs)
 	jsonSubs := []map[string]interface{}{}
@@ -461,3 +495,211 @@ func Test_sumSubscriptions(t *testing.T) {
 		})
 	}
 }
+
+func Test_importSubscriptions(t *testing.T) {
+	logger.InitLoggerByFlag(defaultLogLevel, true)
+	srv := mock_service.NewMockSubscriptions(t)
+	router := gin.New()
+	NewSubscriptionHandler(router.Group("/"), srv)
+	gin.SetMode(gin.TestMode)
+
+	const file = "User,service_name,monthly_price,start_date\n"
+
+	tests := []struct {
+		name        string
+		mock        func()
+		contentType string
+		query       string
+		wantStatus  int
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				srv.EXPECT().Import(mock.Anything, mock.Anything, &service.ImportOptions{
+					Mapping:    map[string]string{"user_id": "User"},
+					DateFormat: "02.01.2006",
+					DryRun:     true,
+				}).Return(&service.ImportReport{DryRun: true}, nil).Once()
+			},
+			contentType: "text/csv; charset=utf-8",
+			query:       "?map=user_id=User&date_format=02.01.2006&dry_run=true",
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name: "Error (header)",
+			mock: func() {
+				srv.EXPECT().Import(mock.Anything, mock.Anything, mock.Anything).
+					Return(nil, service.ErrInvalidImport).Once()
+			},
+			contentType: "text/csv",
+			wantStatus:  http.StatusBadRequest,
+		},
+		{
+			name:        "Error (mapping)",
+			mock:        func() {},
+			contentType: "text/csv",
+			query:       "?map=user_id",
+			wantStatus:  http.StatusBadRequest,
+		},
+		{
+			name:        "Error (content type)",
+			mock:        func() {},
+			contentType: "application/json",
+			wantStatus:  http.StatusUnsupportedMediaType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/subscription/import"+tt.query, strings.NewReader(file))
+			req.Header.Set("Content-Type", tt.contentType)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func Test_querySubscriptions_export(t *testing.T) {
+	logger.InitLoggerByFlag(defaultLogLevel, true)
+	srv := mock_service.NewMockSubscriptions(t)
+	router := gin.New()
+	NewSubscriptionHandler(router.Group("/"), srv)
+	gin.SetMode(gin.TestMode)
+
+	serviceID := int64(5)
+	subs := []*microservice.Subscription{
+		{ID: 1, UserID: uuid.MustParse("3d6e2e6c-0d8a-4c1d-9b6f-3b1f9c2b1f9c"), ServiceID: &serviceID, ServiceName: "Netflix", MonthlyPrice: 100, StartDate: test_time},
+		{ID: 2, UserID: uuid.MustParse("3d6e2e6c-0d8a-4c1d-9b6f-3b1f9c2b1f9c"), ServiceName: "Local Gym", MonthlyPrice: 1500, StartDate: test_time, EndDate: test_time},
+	}
+	date := test_time.Format("2006-01-02")
+
+	tests := []struct {
+		name       string
+		mock       func()
+		accept     string
+		wantStatus int
+		wantType   string
+		wantBody   string
+	}{
+		{
+			name: "Ok (csv)",
+			mock: func() {
+				srv.EXPECT().QueryEach(mock.Anything, mock.Anything, mock.Anything).RunAndReturn(
+					func(_ context.Context, _ *service.SubscriptionQueryArgs, fn func(*microservice.Subscription) error) error {
+						for _, sub := range subs {
+							if err := fn(sub); err != nil {
+								return err
+							}
+						}
+						return nil
+					}).Once()
+			},
+			accept:     "text/csv",
+			wantStatus: http.StatusOK,
+			wantType:   "text/csv",
+			wantBody: "id,user_id,service_id,service_name,category_id,monthly_price,start_date,end_date\n" +
+				"1,3d6e2e6c-0d8a-4c1d-9b6f-3b1f9c2b1f9c,5,Netflix,,100," + date + ",\n" +
+				"2,3d6e2e6c-0d8a-4c1d-9b6f-3b1f9c2b1f9c,,Local Gym,,1500," + date + "," + date + "\n",
+		},
+		{
+			name: "Ok (ndjson, empty)",
+			mock: func() {
+				srv.EXPECT().QueryEach(mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+			},
+			accept:     "application/x-ndjson",
+			wantStatus: http.StatusOK,
+			wantType:   "application/x-ndjson",
+		},
+		{
+			name: "Error (before rows)",
+			mock: func() {
+				srv.EXPECT().QueryEach(mock.Anything, mock.Anything, mock.Anything).Return(errors.New("db is down")).Once()
+			},
+			accept:     "application/x-ndjson",
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/subscription/query", http.NoBody)
+			req.Header.Set("Accept", tt.accept)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantType != "" {
+				assert.Equal(t, tt.wantType, w.Header().Get("Content-Type"))
+				assert.Equal(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func Test_upsertSubscription(t *testing.T) {
+	logger.InitLoggerByFlag(defaultLogLevel, true)
+	srv := mock_service.NewMockSubscriptions(t)
+	router := gin.New()
+	NewSubscriptionHandler(router.Group("/"), srv)
+	gin.SetMode(gin.TestMode)
+
+	const userID = "3d6e2e6c-0d8a-4c1d-9b6f-3b1f9c2b1f9c"
+	body := map[string]interface{}{"monthly_price": 100, "start_date": "2020-01-01"}
+
+	tests := []struct {
+		name       string
+		mock       func()
+		path       string
+		wantStatus int
+	}{
+		{
+			name: "Ok (created)",
+			mock: func() {
+				srv.EXPECT().Upsert(mock.Anything, mock.MatchedBy(func(sub *microservice.Subscription) bool {
+					return sub.UserID.String() == userID && sub.ServiceName == "Local Gym"
+				})).Return(1, true, nil).Once()
+			},
+			path:       "/subscription/by-key/" + userID + "/Local%20Gym",
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name: "Ok (updated)",
+			mock: func() {
+				srv.EXPECT().Upsert(mock.Anything, mock.Anything).Return(1, false, nil).Once()
+			},
+			path:       "/subscription/by-key/" + userID + "/Netflix",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Error (user id)",
+			mock:       func() {},
+			path:       "/subscription/by-key/not-a-uuid/Netflix",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Error (overlap)",
+			mock: func() {
+				srv.EXPECT().Upsert(mock.Anything, mock.Anything).Return(0, false, service.ErrUserSubscriptionPairAlreadyExists).Once()
+			},
+			path:       "/subscription/by-key/" + userID + "/Netflix",
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, createTestRequest(t, http.MethodPut, tt.path, body))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
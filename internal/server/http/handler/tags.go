@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/auth"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type TagHandler struct {
+	tag service.Tags
+}
+
+type tagsRequest struct {
+	Tags []string `json:"tags" binding:"required" example:"family,streaming"`
+}
+
+func NewTagHandler(g *gin.RouterGroup, service service.Tags) *TagHandler {
+	a := &TagHandler{
+		tag: service,
+	}
+	a.registerRoutes(g)
+	return a
+}
+
+func (a *TagHandler) registerRoutes(g *gin.RouterGroup) {
+	sub := g.Group("/subscription/:id/tags")
+	{
+		sub.GET("/", Authorize(auth.OpSubscriptionRead), a.getSubscriptionTags)
+		sub.POST("/", Authorize(auth.OpSubscriptionWrite), a.addSubscriptionTags)
+		sub.DELETE("/:tag", Authorize(auth.OpSubscriptionWrite), a.removeSubscriptionTag)
+	}
+
+	g.GET("/tag/:user_id", Authorize(auth.OpSubscriptionRead), a.getUserTags)
+}
+
+// getSubscriptionTags godoc
+// @Summary      Subscription Tags
+// @Description  Get tags of the subscription
+// @Tags         tags
+// @Produce      json
+// @Param        id    path     microservice.SubscriptionID true  "id of the subscription"  minimum(1)
+// @Success      200  {object}  respSuc{obj=[]microservice.Tag}
+// @Failure      400  {object}  respErr
+// @Failure      404  {object}  respErr
+// @Failure      500  {object}  respErr
+// @Router       /subscription/{id}/tags/	 [get]
+func (a *TagHandler) getSubscriptionTags(c *gin.Context) {
+	const op = "handler.getSubscriptionTags"
+	log, ctx := prepareTools(c, op)
+
+	id, err := parseID(c, "id")
+	if err != nil {
+		log.Debug().Err(err).Str("id", c.Param("id")).Msg("can't parse subscription id")
+		writeBadRequest(c, err.Error())
+		return
+	}
+
+	tags, err := a.tag.ListBySubscription(ctx, id)
+	if err != nil {
+		writeError(c, log, err, "error getting tags on the server")
+		return
+	}
+
+	writeObj(c, tags)
+}
+
+// addSubscriptionTags godoc
+// @Summary      Tag Subscription
+// @Description  Attach tags to the subscription, missing tags of the subscription owner are created
+// @Tags         tags
+// @Accept       json
+// @Produce      json
+// @Param        id    path     microservice.SubscriptionID true  "id of the subscription"  minimum(1)
+// @Param        tags  body     tagsRequest  true  "tags to attach"
+// @Success      200  {object}  respSuc{obj=[]microservice.Tag}
+// @Failure      400  {object}  respErr
+// @Failure      404  {object}  respErr
+// @Failure      500  {object}  respErr
+// @Router       /subscription/{id}/tags/	 [post]
+func (a *TagHandler) addSubscriptionTags(c *gin.Context) {
+	const op = "handler.addSubscriptionTags"
+	log, ctx := prepareTools(c, op)
+
+	id, err := parseID(c, "id")
+	if err != nil {
+		log.Debug().Err(err).Str("id", c.Param("id")).Msg("can't parse subscription id")
+		writeBadRequest(c, err.Error())
+		return
+	}
+
+	req := &tagsRequest{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		writeBadRequest(c, "error binding json: "+err.Error())
+		return
+	}
+
+	tags, err := a.tag.AddToSubscription(ctx, id, req.Tags)
+	if err != nil {
+		writeError(c, log, err, "error adding tags on the server")
+		return
+	}
+
+	writeObj(c, tags)
+}
+
+// removeSubscriptionTag godoc
+// @Summary      Untag Subscription
+// @Description  Detach the tag from the subscription
+// @Tags         tags
+// @Produce      json
+// @Param        id    path     microservice.SubscriptionID true  "id of the subscription"  minimum(1)
+// @Param        tag   path     string true  "tag name"
+// @Success      200  {object}  respSucNoObj
+// @Failure      400  {object}  respErr
+// @Failure      404  {object}  respErr
+// @Failure      500  {object}  respErr
+// @Router       /subscription/{id}/tags/{tag}	 [delete]
+func (a *TagHandler) removeSubscriptionTag(c *gin.Context) {
+	const op = "handler.removeSubscriptionTag"
+	log, ctx := prepareTools(c, op)
+
+	id, err := parseID(c, "id")
+	if err != nil {
+		log.Debug().Err(err).Str("id", c.Param("id")).Msg("can't parse subscription id")
+		writeBadRequest(c, err.Error())
+		return
+	}
+
+	if err = a.tag.RemoveFromSubscription(ctx, id, c.Param("tag")); err != nil {
+		writeError(c, log, err, "error removing tag on the server")
+		return
+	}
+
+	writeOK(c)
+}
+
+// getUserTags godoc
+// @Summary      User Tags
+// @Description  Get all tags defined by the user
+// @Tags         tags
+// @Produce      json
+// @Param        user_id    path     string true  "id of the user"
+// @Success      200  {object}  respSuc{obj=[]microservice.Tag}
+// @Failure      400  {object}  respErr
+// @Failure      500  {object}  respErr
+// @Router       /tag/{user_id}	 [get]
+func (a *TagHandler) getUserTags(c *gin.Context) {
+	const op = "handler.getUserTags"
+	log, ctx := prepareTools(c, op)
+
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil || userID == uuid.Nil {
+		writeBadRequest(c, service.ErrNoUserID.Error())
+		return
+	}
+
+	tags, err := a.tag.ListByUser(ctx, userID)
+	if err != nil {
+		writeError(c, log, err, "error getting tags on the server")
+		return
+	}
+
+	writeObj(c, tags)
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/auth"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/pkg/api/problem"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/tenant"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultTenantHeader is the header choosing the tenant of the request.
+const DefaultTenantHeader = "X-Tenant-ID"
+
+// TenantKey is a gin context key of the tenant of the request.
+const TenantKey = "tenant"
+
+// TenantMiddleware puts the tenant of the request to its context, so
+// storages limit data to it. Principals bound to a tenant by Basic Auth
+// config, the token claim or the API key are limited to it. Only unbound
+// admins and requests without principal choose the tenant by the header,
+// other unbound principals are limited to the default tenant. Requests
+// without tenant get the default one.
+func TenantMiddleware(header string) gin.HandlerFunc {
+	if header == "" {
+		header = DefaultTenantHeader
+	}
+
+	return func(c *gin.Context) {
+		id := c.GetHeader(header)
+		if p, ok := auth.FromContext(c.Request.Context()); ok && (p.Tenant != "" || !p.Admin) {
+			bound := p.Tenant
+			if bound == "" {
+				bound = tenant.Default
+			}
+			if id != "" && id != bound {
+				writeErrorResponse(c, http.StatusForbidden, problem.CodeForbidden, "access to the tenant is denied", nil)
+				c.Abort()
+				return
+			}
+			id = bound
+		}
+		if id == "" {
+			id = tenant.Default
+		}
+		if err := tenant.Validate(id); err != nil {
+			writeErrorResponse(c, http.StatusBadRequest, problem.CodeInvalidTenant, err.Error(), nil)
+			c.Abort()
+			return
+		}
+
+		c.Set(TenantKey, id)
+		c.Request = c.Request.WithContext(tenant.WithID(c.Request.Context(), id))
+		c.Next()
+	}
+}
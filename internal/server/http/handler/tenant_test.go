@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/auth"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/tenant"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenantMiddleware(t *testing.T) {
+	logger.InitLoggerByFlag(defaultLogLevel, true)
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(AuthMiddleware(auth.NewBasicAuthenticator(
+		map[string]string{"admin": "secret", "acme": "secret", "alice": "secret"},
+		map[string]*auth.Principal{
+			"admin": {Name: "admin", Admin: true},
+			"acme":  {Name: "acme", Tenant: "acme"},
+			"alice": {Name: "alice"},
+		},
+	)), TenantMiddleware(""))
+	router.GET("/tenant", func(c *gin.Context) {
+		c.String(http.StatusOK, tenant.FromContext(c.Request.Context()))
+	})
+
+	tests := []struct {
+		name       string
+		user       string
+		header     string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "Ok (default)", user: "admin", wantStatus: http.StatusOK, wantBody: tenant.Default},
+		{name: "Ok (header)", user: "admin", header: "globex", wantStatus: http.StatusOK, wantBody: "globex"},
+		{name: "Ok (bound)", user: "acme", wantStatus: http.StatusOK, wantBody: "acme"},
+		{name: "Ok (bound, same header)", user: "acme", header: "acme", wantStatus: http.StatusOK, wantBody: "acme"},
+		{name: "Error (bound, other header)", user: "acme", header: "globex", wantStatus: http.StatusForbidden},
+		{name: "Ok (unbound)", user: "alice", wantStatus: http.StatusOK, wantBody: tenant.Default},
+		{name: "Ok (unbound, default header)", user: "alice", header: tenant.Default, wantStatus: http.StatusOK, wantBody: tenant.Default},
+		{name: "Error (unbound, other header)", user: "alice", header: "globex", wantStatus: http.StatusForbidden},
+		{name: "Error (invalid)", user: "admin", header: "a b", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/tenant", nil)
+			req.SetBasicAuth(tt.user, "secret")
+			if tt.header != "" {
+				req.Header.Set(DefaultTenantHeader, tt.header)
+			}
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -2,14 +2,17 @@ package handler
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/pkg/api/problem"
 	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/pkg/api/response"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/pkg/e"
 
-	"github.com/gin-contrib/requestid"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog"
-	"github.com/rs/zerolog/log"
 )
 
 type resp = response.Response
@@ -41,8 +44,8 @@ func writeSuccess(c *gin.Context, status int, msg string, obj interface{}) {
 	c.JSON(status, resp{Success: true, Msg: msg, Obj: obj})
 }
 
-func writeFailure(c *gin.Context, status int, msg string, obj interface{}) {
-	c.JSON(status, resp{Success: false, Msg: msg, Obj: obj})
+func writeFailure(c *gin.Context, status int, msg string) {
+	writeErrorResponse(c, status, codeByStatus(status), msg, nil)
 }
 
 /* ---- Predefined both HTTP and response statuses ---- */
@@ -57,18 +60,32 @@ func writeObj(c *gin.Context, obj interface{}) {
 }
 
 func writeBadRequest(c *gin.Context, msg string) {
-	c.JSON(http.StatusBadRequest, resp{Success: false, Msg: msg})
+	writeErrorResponse(c, http.StatusBadRequest, problem.CodeBadRequest, msg, nil)
 }
 
 func writeNotFound(c *gin.Context, msg string) {
-	c.JSON(http.StatusNotFound, resp{Success: false, Msg: msg})
+	writeErrorResponse(c, http.StatusNotFound, problem.CodeNotFound, msg, nil)
 }
 
 func writeServerInternal(c *gin.Context, msg string) {
-	c.JSON(http.StatusInternalServerError, resp{Success: false, Msg: msg})
+	writeErrorResponse(c, http.StatusInternalServerError, problem.CodeInternal, msg, nil)
 }
 
 func prepareTools(c *gin.Context, op string) (logger zerolog.Logger, ctx context.Context) {
-	return log.With().Str("op", op).Str("request_id", requestid.Get(c)).Logger(),
+	return zerolog.Ctx(c.Request.Context()).With().Str("op", op).Logger(),
 		c.Request.Context()
 }
+
+// parseID parses int64 identifier from the path parameter.
+func parseID(c *gin.Context, param string) (int64, error) {
+	idStr := c.Param(param)
+	if idStr == "" {
+		return 0, errors.New("can't parse " + param + ": empty")
+	}
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return 0, e.Wrap(fmt.Sprintf("can't parse %s by string %s", param, idStr), err)
+	}
+
+	return id, nil
+}
@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"regexp"
+
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// HeaderRequestID carries the request id from callers and back to them.
+const HeaderRequestID = "X-Request-ID"
+
+// loggerKey is a gin context key of the request logger.
+const loggerKey = "logger"
+
+// Inbound ids are logged as is, so they are limited to safe characters.
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID takes the id of the request from the X-Request-ID header of
+// the caller or generates a new one, if it's missing or malformed. The
+// id is sent back in the same header.
+func RequestID() gin.HandlerFunc {
+	next := requestid.New(requestid.WithCustomHeaderStrKey(HeaderRequestID))
+	return func(c *gin.Context) {
+		if id := c.GetHeader(HeaderRequestID); id != "" && !requestIDPattern.MatchString(id) {
+			c.Request.Header.Del(HeaderRequestID)
+		}
+		next(c)
+	}
+}
+
+// Context puts the logger of the request to its context, so handlers,
+// services and storages log through zerolog.Ctx with the request id and
+// the trace id, if the request is traced.
+func Context(log *zerolog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		lc := log.With().Str("request_id", requestid.Get(c))
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			lc = lc.Str("trace_id", sc.TraceID().String())
+		}
+		reqLog := lc.Logger()
+
+		c.Set(loggerKey, &reqLog)
+		c.Request = c.Request.WithContext(reqLog.WithContext(ctx))
+		c.Next()
+	}
+}
+
+// fromContext returns the logger of the request or the given one, if the
+// request has none.
+func fromContext(c *gin.Context, log *zerolog.Logger) *zerolog.Logger {
+	if l, ok := c.Value(loggerKey).(*zerolog.Logger); ok {
+		return l
+	}
+	return log
+}
@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContext(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var buf bytes.Buffer
+	log := zerolog.New(&buf)
+	router := gin.New()
+	router.Use(RequestID(), Context(&log))
+	router.GET("/", func(c *gin.Context) {
+		zerolog.Ctx(c.Request.Context()).Info().Msg("handled")
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name    string
+		id      string
+		wantNew bool
+	}{
+		{name: "Ok (inbound)", id: "req-42"},
+		{name: "Ok (generated)", wantNew: true},
+		{name: "Ok (malformed)", id: "bad id\n{}", wantNew: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.id != "" {
+				req.Header.Set(HeaderRequestID, tt.id)
+			}
+			router.ServeHTTP(w, req)
+
+			id := w.Header().Get(HeaderRequestID)
+			if tt.wantNew {
+				assert.NotEmpty(t, id)
+				assert.NotEqual(t, tt.id, id)
+			} else {
+				assert.Equal(t, tt.id, id)
+			}
+			assert.JSONEq(t, `{"level":"info","request_id":"`+id+`","message":"handled"}`, buf.String())
+		})
+	}
+}
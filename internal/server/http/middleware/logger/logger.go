@@ -7,9 +7,12 @@ import (
 	"github.com/rs/zerolog"
 )
 
+// New logs requests and their completion through the logger of the
+// request, if Context put one, and the given logger otherwise.
 func New(log *zerolog.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
+		log := fromContext(c, log)
 
 		// Collect data after request
 		status := c.Writer.Status()
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+
+	microservice "github.com/ikotiki/go-rest-api-service-subscriptions"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/auth"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+// authorize checks, whether the principal of the context may act on behalf
+// of the user. Without principal, e.g. with authentication disabled,
+// everything is allowed.
+func authorize(ctx context.Context, userID microservice.UserID) error {
+	p, ok := auth.FromContext(ctx)
+	if !ok || p.CanActAs(userID) {
+		return nil
+	}
+	return ErrForbidden
+}
+
+// authorizeRead checks, whether the principal of the context may read data
+// of the user.
+func authorizeRead(ctx context.Context, userID microservice.UserID) error {
+	p, ok := auth.FromContext(ctx)
+	if !ok || p.CanReadAs(userID) {
+		return nil
+	}
+	return ErrForbidden
+}
+
+// getOwned returns the subscription, if the principal may change it.
+// Subscriptions of other users are reported missing, so their ids are
+// not disclosed.
+func getOwned(ctx context.Context, subs storage.Subscriptions, id microservice.SubscriptionID) (*microservice.Subscription, error) {
+	sub, err := subs.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err = authorize(ctx, sub.UserID); err != nil {
+		return nil, ErrNoSuchSubscription
+	}
+	return sub, nil
+}
+
+// lockOwned returns the subscription like getOwned and locks it until the
+// end of the transaction.
+func lockOwned(ctx context.Context, subs storage.Subscriptions, id microservice.SubscriptionID) (*microservice.Subscription, error) {
+	sub, err := subs.GetByIDForUpdate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err = authorize(ctx, sub.UserID); err != nil {
+		return nil, ErrNoSuchSubscription
+	}
+	return sub, nil
+}
+
+// lockOwnedMany locks existing subscriptions of the ids like lockOwned and
+// returns the ones the principal may change by id.
+func lockOwnedMany(ctx context.Context, subs storage.Subscriptions, ids []microservice.SubscriptionID) (map[microservice.SubscriptionID]*microservice.Subscription, error) {
+	locked, err := subs.GetManyForUpdate(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	owned := make(map[microservice.SubscriptionID]*microservice.Subscription, len(locked))
+	for _, sub := range locked {
+		if authorize(ctx, sub.UserID) == nil {
+			owned[sub.ID] = sub
+		}
+	}
+	return owned, nil
+}
+
+// getReadable returns the subscription, if the principal may read it.
+func getReadable(ctx context.Context, subs storage.Subscriptions, id microservice.SubscriptionID) (*microservice.Subscription, error) {
+	sub, err := subs.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err = authorizeRead(ctx, sub.UserID); err != nil {
+		return nil, ErrNoSuchSubscription
+	}
+	return sub, nil
+}
+
+// scopeQuery limits the query to subscriptions of the principal's user,
+// unless the principal reads data of all users.
+func scopeQuery(ctx context.Context, args *SubscriptionQueryArgs) (*SubscriptionQueryArgs, error) {
+	p, ok := auth.FromContext(ctx)
+	if !ok || p.ReadsAnyUser() {
+		return args, nil
+	}
+	if p.UserID == uuid.Nil {
+		return nil, ErrForbidden
+	}
+	if args.UserID != "" {
+		if userID, err := uuid.Parse(args.UserID); err == nil && userID != p.UserID {
+			return nil, ErrForbidden
+		}
+	}
+
+	scoped := *args
+	scoped.UserID = p.UserID.String()
+	return &scoped, nil
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	microservice "github.com/ikotiki/go-rest-api-service-subscriptions"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/auth"
+	mock_storage "github.com/ikotiki/go-rest-api-service-subscriptions/internal/storage/mocks"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSubscriptionService_access(t *testing.T) {
+	logger.InitLoggerByFlag("trace", false)
+
+	alice := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
+	bob := uuid.MustParse("123e4567-e89b-12d3-a456-426614174001")
+	sub := &microservice.Subscription{ID: 1, UserID: bob, ServiceName: "Netflix"}
+
+	asAlice := auth.WithPrincipal(t.Context(), &auth.Principal{Name: "alice", UserID: alice})
+	asAdmin := auth.WithPrincipal(t.Context(), &auth.Principal{Name: "admin", Admin: true})
+	asAuditor := auth.WithPrincipal(t.Context(), &auth.Principal{Name: "auditor", Roles: []string{auth.RoleAuditor}})
+
+	t.Run("GetByID of other user", func(t *testing.T) {
+		store := mock_storage.NewMockSubscriptions(t)
+		store.EXPECT().GetByID(mock.Anything, sub.ID).Return(sub, nil)
+		srv := NewSubscriptionService(store, nil, nil)
+
+		_, err := srv.GetByID(asAlice, sub.ID)
+		assert.ErrorIs(t, err, ErrNoSuchSubscription)
+
+		got, err := srv.GetByID(asAdmin, sub.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, sub, got)
+
+		got, err = srv.GetByID(asAuditor, sub.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, sub, got)
+	})
+
+	t.Run("DeleteByID of other user", func(t *testing.T) {
+		store := mock_storage.NewMockSubscriptions(t)
+		store.EXPECT().GetByID(mock.Anything, sub.ID).Return(sub, nil)
+		srv := NewSubscriptionService(store, nil, nil)
+
+		assert.ErrorIs(t, srv.DeleteByID(asAlice, sub.ID), ErrNoSuchSubscription)
+		assert.ErrorIs(t, srv.DeleteByID(asAuditor, sub.ID), ErrNoSuchSubscription)
+	})
+
+	t.Run("Create for other user", func(t *testing.T) {
+		srv := NewSubscriptionService(mock_storage.NewMockSubscriptions(t), nil, nil)
+
+		_, err := srv.Create(asAlice, &microservice.Subscription{
+			UserID: bob, ServiceName: "Netflix", MonthlyPrice: 100, StartDate: microservice.NewDate(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)),
+		})
+		assert.ErrorIs(t, err, ErrForbidden)
+	})
+}
+
+func Test_scopeQuery(t *testing.T) {
+	alice := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
+	bob := uuid.MustParse("123e4567-e89b-12d3-a456-426614174001")
+
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		input     *SubscriptionQueryArgs
+		want      string
+		wantErr   error
+	}{
+		{name: "No principal", input: &SubscriptionQueryArgs{}, want: ""},
+		{name: "Admin", principal: &auth.Principal{Admin: true}, input: &SubscriptionQueryArgs{UserID: bob.String()}, want: bob.String()},
+		{name: "Auditor", principal: &auth.Principal{Roles: []string{auth.RoleAuditor}}, input: &SubscriptionQueryArgs{UserID: bob.String()}, want: bob.String()},
+		{name: "User", principal: &auth.Principal{UserID: alice}, input: &SubscriptionQueryArgs{}, want: alice.String()},
+		{name: "User of other user", principal: &auth.Principal{UserID: alice}, input: &SubscriptionQueryArgs{UserID: bob.String()}, wantErr: ErrForbidden},
+		{name: "Unbound", principal: &auth.Principal{Name: "bob"}, input: &SubscriptionQueryArgs{}, wantErr: ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := t.Context()
+			if tt.principal != nil {
+				ctx = auth.WithPrincipal(ctx, tt.principal)
+			}
+
+			got, err := scopeQuery(ctx, tt.input)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got.UserID)
+			}
+		})
+	}
+}
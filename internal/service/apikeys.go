@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	microservice "github.com/ikotiki/go-rest-api-service-subscriptions"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/auth"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/storage"
+)
+
+// apiKeyPrefix marks API keys, so they are recognized in configs and logs.
+const apiKeyPrefix = "sk_"
+
+// apiKeyPrefixLen is a length of the key's part stored in clear to identify it.
+const apiKeyPrefixLen = len(apiKeyPrefix) + 8
+
+type APIKeyService struct {
+	store  storage.APIKeys
+	policy *auth.Policy
+}
+
+// NewAPIKeyService makes the service issuing keys with roles of the policy.
+func NewAPIKeyService(store storage.APIKeys, policy *auth.Policy) *APIKeyService {
+	return &APIKeyService{store: store, policy: policy}
+}
+
+// Create stores the new key and returns it. The key is never shown again,
+// only its hash is kept. Keys without user act on any user, so they need
+// the admin or the auditor role.
+func (s *APIKeyService) Create(ctx context.Context, key *microservice.APIKey) (secret string, err error) {
+	if err = requireAdmin(ctx); err != nil {
+		return "", err
+	}
+	if key.Name = strings.TrimSpace(key.Name); key.Name == "" {
+		return "", ErrInvalidAPIKeyName
+	}
+	if len(key.Scopes) == 0 {
+		return "", ErrInvalidScope
+	}
+	for _, scope := range key.Scopes {
+		if !slices.Contains(auth.Scopes, scope) {
+			return "", fmt.Errorf("%w: %s", ErrInvalidScope, scope)
+		}
+	}
+	for i := range key.Roles {
+		key.Roles[i] = strings.TrimSpace(key.Roles[i])
+		if !s.policy.HasRole(key.Roles[i]) {
+			return "", fmt.Errorf("%w: %q", ErrInvalidRole, key.Roles[i])
+		}
+	}
+	if key.UserID == nil && !(&auth.Principal{Admin: auth.HasAdminRole(key.Roles), Roles: key.Roles}).ReadsAnyUser() {
+		return "", fmt.Errorf("%w: keys without user_id need the %s or %s role", ErrInvalidRole, auth.RoleAdmin, auth.RoleAuditor)
+	}
+
+	buf := make([]byte, 32)
+	if _, err = rand.Read(buf); err != nil {
+		return "", err
+	}
+	secret = apiKeyPrefix + base64.RawURLEncoding.EncodeToString(buf)
+
+	key.Prefix = secret[:apiKeyPrefixLen]
+	key.Hash = hashAPIKey(secret)
+	if key.ID, err = s.store.Create(ctx, key); err != nil {
+		return "", err
+	}
+	return secret, nil
+}
+
+func (s *APIKeyService) List(ctx context.Context) (keys []*microservice.APIKey, err error) {
+	if err = requireReadAll(ctx); err != nil {
+		return nil, err
+	}
+	return s.store.List(ctx)
+}
+
+func (s *APIKeyService) Revoke(ctx context.Context, id microservice.APIKeyID) (err error) {
+	if err = requireAdmin(ctx); err != nil {
+		return err
+	}
+	return s.store.Revoke(ctx, id)
+}
+
+// Verify returns the principal of the key, it implements auth.APIKeyVerifier.
+func (s *APIKeyService) Verify(ctx context.Context, secret string) (p *auth.Principal, err error) {
+	if !strings.HasPrefix(secret, apiKeyPrefix) {
+		return nil, auth.ErrInvalidCredentials
+	}
+	key, err := s.store.GetByHash(ctx, hashAPIKey(secret))
+	if errors.Is(err, ErrNoSuchAPIKey) {
+		return nil, auth.ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	p = &auth.Principal{
+		Name:   fmt.Sprintf("apikey:%d", key.ID),
+		Admin:  auth.HasAdminRole(key.Roles),
+		Scopes: key.Scopes,
+		Roles:  key.Roles,
+		Tenant: key.TenantID,
+	}
+	if key.UserID != nil {
+		p.UserID = *key.UserID
+	}
+	if p.Scopes == nil {
+		p.Scopes = []string{}
+	}
+	return p, nil
+}
+
+// hashAPIKey hashes the key for storage. Keys are random, so a plain hash
+// can't be reversed by guessing.
+func hashAPIKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// requireAdmin allows only admins not limited by scopes, so keys can't
+// issue other keys. Without principal everything is allowed.
+func requireAdmin(ctx context.Context) error {
+	p, ok := auth.FromContext(ctx)
+	if !ok || (p.Admin && p.Scopes == nil) {
+		return nil
+	}
+	return ErrForbidden
+}
+
+// requireReadAll allows admins and auditors not limited by scopes.
+func requireReadAll(ctx context.Context) error {
+	p, ok := auth.FromContext(ctx)
+	if !ok || (p.ReadsAnyUser() && p.Scopes == nil) {
+		return nil
+	}
+	return ErrForbidden
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	microservice "github.com/ikotiki/go-rest-api-service-subscriptions"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/auth"
+	mock_storage "github.com/ikotiki/go-rest-api-service-subscriptions/internal/storage/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyService_CreateVerify(t *testing.T) {
+	userID := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
+	store := mock_storage.NewMockAPIKeys(t)
+	srv := NewAPIKeyService(store, auth.DefaultPolicy())
+
+	var stored *microservice.APIKey
+	store.EXPECT().Create(mock.Anything, mock.Anything).RunAndReturn(
+		func(_ context.Context, key *microservice.APIKey) (microservice.APIKeyID, error) {
+			stored = key
+			return 7, nil
+		})
+
+	key := &microservice.APIKey{Name: " billing ", UserID: &userID, Scopes: []string{auth.ScopeReportsRead}}
+	secret, err := srv.Create(t.Context(), key)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(secret, key.Prefix))
+	assert.Equal(t, hashAPIKey(secret), stored.Hash)
+	assert.Equal(t, "billing", stored.Name)
+	assert.Equal(t, microservice.APIKeyID(7), key.ID)
+
+	store.EXPECT().GetByHash(mock.Anything, stored.Hash).Return(stored, nil)
+	p, err := srv.Verify(t.Context(), secret)
+	require.NoError(t, err)
+	assert.Equal(t, &auth.Principal{Name: "apikey:7", UserID: userID, Scopes: []string{auth.ScopeReportsRead}}, p)
+
+	unbound := &microservice.APIKey{ID: 8, Scopes: auth.Scopes, Roles: []string{auth.RoleAuditor}}
+	store.EXPECT().GetByHash(mock.Anything, hashAPIKey("sk_auditor")).Return(unbound, nil)
+	p, err = srv.Verify(t.Context(), "sk_auditor")
+	require.NoError(t, err)
+	assert.False(t, p.Admin)
+	assert.True(t, p.ReadsAnyUser())
+
+	store.EXPECT().GetByHash(mock.Anything, hashAPIKey("sk_revoked")).Return(nil, ErrNoSuchAPIKey)
+	_, err = srv.Verify(t.Context(), "sk_revoked")
+	assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
+}
+
+func TestAPIKeyService_Create(t *testing.T) {
+	admin := auth.WithPrincipal(context.Background(), &auth.Principal{Name: "admin", Admin: true})
+	scopedAdmin := auth.WithPrincipal(context.Background(), &auth.Principal{Name: "apikey:1", Admin: true, Scopes: auth.Scopes})
+	user := auth.WithPrincipal(context.Background(), &auth.Principal{Name: "alice", UserID: uuid.New()})
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		key     *microservice.APIKey
+		wantErr error
+	}{
+		{name: "Error (name)", ctx: admin, key: &microservice.APIKey{Name: " ", Scopes: auth.Scopes}, wantErr: ErrInvalidAPIKeyName},
+		{name: "Error (no scopes)", ctx: admin, key: &microservice.APIKey{Name: "billing"}, wantErr: ErrInvalidScope},
+		{name: "Error (unknown scope)", ctx: admin, key: &microservice.APIKey{Name: "billing", Scopes: []string{"admin"}}, wantErr: ErrInvalidScope},
+		{name: "Error (unknown role)", ctx: admin, key: &microservice.APIKey{Name: "billing", Scopes: auth.Scopes, Roles: []string{"owner"}}, wantErr: ErrInvalidRole},
+		{name: "Error (unbound viewer)", ctx: admin, key: &microservice.APIKey{Name: "billing", Scopes: auth.Scopes, Roles: []string{auth.RoleViewer}}, wantErr: ErrInvalidRole},
+		{name: "Error (unbound without roles)", ctx: admin, key: &microservice.APIKey{Name: "billing", Scopes: auth.Scopes}, wantErr: ErrInvalidRole},
+		{name: "Error (user)", ctx: user, key: &microservice.APIKey{Name: "billing", Scopes: auth.Scopes}, wantErr: ErrForbidden},
+		{name: "Error (api key)", ctx: scopedAdmin, key: &microservice.APIKey{Name: "billing", Scopes: auth.Scopes}, wantErr: ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := NewAPIKeyService(mock_storage.NewMockAPIKeys(t), auth.DefaultPolicy())
+
+			_, err := srv.Create(tt.ctx, tt.key)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,509 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	microservice "github.com/ikotiki/go-rest-api-service-subscriptions"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/auth"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/storage"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/tracing"
+)
+
+// MaxBulkSize limits items of a single bulk request.
+const MaxBulkSize = 5000
+
+// BulkResult is an outcome of a single item of a bulk request.
+type BulkResult struct {
+	Index  int                         `json:"index" example:"0"`
+	ID     microservice.SubscriptionID `json:"id,omitempty" example:"1"`
+	Error  string                      `json:"error,omitempty" example:"no such subscription"`
+	Fields []FieldError                `json:"fields,omitempty"`
+
+	err error
+}
+
+// BulkResults lists outcomes of all items in order of the request.
+type BulkResults struct {
+	Succeeded int           `json:"succeeded" example:"1"`
+	Failed    int           `json:"failed" example:"0"`
+	Results   []*BulkResult `json:"results"`
+}
+
+func newBulkResults(n int) *BulkResults {
+	res := &BulkResults{Results: make([]*BulkResult, n)}
+	for i := range res.Results {
+		res.Results[i] = &BulkResult{Index: i}
+	}
+	return res
+}
+
+func (r *BulkResults) accept(i int, id microservice.SubscriptionID) {
+	r.Results[i].ID = id
+	r.Succeeded++
+}
+
+func (r *BulkResults) reject(i int, err error) {
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		r.Results[i].Fields = verr.Fields
+	}
+	r.Results[i].Error = err.Error()
+	r.Results[i].err = err
+	r.Failed++
+}
+
+// rejection describes all rejected items as a validation error,
+// which fields are prefixed with the item position.
+func (r *BulkResults) rejection() error {
+	verr := &ValidationError{}
+	for _, res := range r.Results {
+		if res.Error == "" {
+			continue
+		}
+		item := fmt.Sprintf("items[%d]", res.Index)
+		if len(res.Fields) == 0 {
+			verr.add(item, res.Error)
+		}
+		for _, f := range res.Fields {
+			verr.add(item+"."+f.Field, f.Message)
+		}
+	}
+	return verr.err()
+}
+
+// bulkItemErrors are errors rejecting a single item of the bulk request,
+// other errors abort the whole request.
+var bulkItemErrors = []error{
+	ErrNoSuchSubscription,
+	ErrUserSubscriptionPairAlreadyExists,
+	ErrUnknownService,
+	ErrInvalidServiceName,
+	ErrForbidden,
+	ErrSharesExceedPrice,
+	ErrDuplicateBulkItem,
+}
+
+func isBulkItemError(err error) bool {
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		return true
+	}
+	for _, itemErr := range bulkItemErrors {
+		if errors.Is(err, itemErr) {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateBulk creates subscriptions within a single transaction. Rejected
+// items are reported in results and the rest are created, unless
+// allOrNothing is set, then nothing is created and ValidationError lists
+// the rejected items.
+func (s *SubscriptionService) CreateBulk(ctx context.Context, subs []*microservice.Subscription, allOrNothing bool) (res *BulkResults, err error) {
+	ctx, span := tracing.Start(ctx, "SubscriptionService.CreateBulk")
+	defer tracing.End(span, &err)
+
+	if len(subs) == 0 || len(subs) > MaxBulkSize {
+		return nil, ErrBulkSize
+	}
+	return s.createBulk(ctx, subs, allOrNothing, false)
+}
+
+// createBulk checks and creates subscriptions, with dryRun nothing is
+// created and accepted items are reported without ids. The catalog and
+// stored subscriptions of the same users and services are loaded once for
+// all items, instead of querying them per item.
+func (s *SubscriptionService) createBulk(ctx context.Context, subs []*microservice.Subscription, allOrNothing, dryRun bool) (res *BulkResults, err error) {
+	err = s.tx.WithTx(ctx, func(tx storage.Storage) error {
+		catalog, err := newCatalogSnapshot(ctx, tx.Services)
+		if err != nil {
+			return err
+		}
+		srv := NewSubscriptionService(tx.Subscriptions, catalog, tx.Transactor)
+		res = newBulkResults(len(subs))
+
+		checked := make([]*microservice.Subscription, 0, len(subs))
+		checkedIdx := make([]int, 0, len(subs))
+		for i, sub := range subs {
+			if err := srv.prepareCreateFields(ctx, sub); err != nil {
+				if !isBulkItemError(err) {
+					return err
+				}
+				res.reject(i, err)
+				continue
+			}
+			checked = append(checked, sub)
+			checkedIdx = append(checkedIdx, i)
+		}
+
+		stored, err := srv.queryByKeys(ctx, checked)
+		if err != nil {
+			return err
+		}
+
+		accepted := make([]*microservice.Subscription, 0, len(checked))
+		acceptedIdx := make([]int, 0, len(checked))
+		for j, sub := range checked {
+			err := checkOverlapWithin(sub, stored)
+			if err == nil {
+				err = checkOverlapWithin(sub, accepted)
+			}
+			if err != nil {
+				res.reject(checkedIdx[j], err)
+				continue
+			}
+			accepted = append(accepted, sub)
+			acceptedIdx = append(acceptedIdx, checkedIdx[j])
+		}
+
+		if allOrNothing && res.Failed > 0 {
+			return res.rejection()
+		}
+		if dryRun {
+			for _, i := range acceptedIdx {
+				res.accept(i, 0)
+			}
+			return nil
+		}
+		if len(accepted) == 0 {
+			return nil
+		}
+
+		ids, err := tx.Subscriptions.CreateMany(ctx, accepted)
+		if err != nil {
+			return err
+		}
+		for j, id := range ids {
+			res.accept(acceptedIdx[j], id)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// UpdateBulk updates subscriptions within a single transaction, the same way
+// as CreateBulk creates them. Subscriptions are locked, and their members,
+// the catalog and stored subscriptions of the same users and services are
+// loaded once for all items. Items are checked and written in order of the
+// request, so each one is checked against the earlier updated ones, as the
+// database checks them.
+func (s *SubscriptionService) UpdateBulk(ctx context.Context, subs []*microservice.Subscription, allOrNothing bool) (res *BulkResults, err error) {
+	ctx, span := tracing.Start(ctx, "SubscriptionService.UpdateBulk")
+	defer tracing.End(span, &err)
+
+	if len(subs) == 0 || len(subs) > MaxBulkSize {
+		return nil, ErrBulkSize
+	}
+
+	err = s.tx.WithTx(ctx, func(tx storage.Storage) error {
+		catalog, err := newCatalogSnapshot(ctx, tx.Services)
+		if err != nil {
+			return err
+		}
+		srv := NewSubscriptionService(tx.Subscriptions, catalog, tx.Transactor)
+		res = newBulkResults(len(subs))
+
+		ids := make([]microservice.SubscriptionID, 0, len(subs))
+		seen := make(map[microservice.SubscriptionID]bool, len(subs))
+		for i, sub := range subs {
+			if err := validateSubscription(sub); err != nil {
+				res.reject(i, err)
+				continue
+			}
+			if seen[sub.ID] {
+				res.reject(i, ErrDuplicateBulkItem)
+				continue
+			}
+			seen[sub.ID] = true
+			ids = append(ids, sub.ID)
+		}
+
+		cur, err := lockOwnedMany(ctx, tx.Subscriptions, ids)
+		if err != nil {
+			return err
+		}
+
+		checked := make([]*microservice.Subscription, 0, len(ids))
+		checkedIdx := make([]int, 0, len(ids))
+		repriced := make([]microservice.SubscriptionID, 0, len(ids))
+		for i, sub := range subs {
+			if res.Results[i].Error != "" {
+				continue
+			}
+			old, ok := cur[sub.ID]
+			if !ok {
+				res.reject(i, ErrNoSuchSubscription)
+				continue
+			}
+			// Owner of the subscription can't be changed.
+			sub.UserID = old.UserID
+			if err := srv.resolveService(ctx, sub); err != nil {
+				if !isBulkItemError(err) {
+					return err
+				}
+				res.reject(i, err)
+				continue
+			}
+			if sub.MonthlyPrice != old.MonthlyPrice {
+				repriced = append(repriced, sub.ID)
+			}
+			checked = append(checked, sub)
+			checkedIdx = append(checkedIdx, i)
+		}
+
+		shares := make(map[microservice.SubscriptionID][]*microservice.Member, len(repriced))
+		if len(repriced) > 0 {
+			members, err := tx.Members.ListBySubscriptions(ctx, repriced)
+			if err != nil {
+				return err
+			}
+			for _, m := range members {
+				shares[m.SubscriptionID] = append(shares[m.SubscriptionID], m)
+			}
+		}
+
+		stored, err := srv.queryByKeys(ctx, checked)
+		if err != nil {
+			return err
+		}
+		periods := newPeriodIndex(stored)
+
+		accepted := make([]*microservice.Subscription, 0, len(checked))
+		acceptedIdx := make([]int, 0, len(checked))
+		for j, sub := range checked {
+			err := checkShares(shares[sub.ID], sub.MonthlyPrice)
+			if err == nil {
+				err = periods.checkOverlap(sub)
+			}
+			if err != nil {
+				res.reject(checkedIdx[j], err)
+				continue
+			}
+			periods.add(sub)
+			accepted = append(accepted, sub)
+			acceptedIdx = append(acceptedIdx, checkedIdx[j])
+		}
+
+		if allOrNothing && res.Failed > 0 {
+			return res.rejection()
+		}
+		for j, sub := range accepted {
+			if err = tx.Subscriptions.Update(ctx, sub); err != nil {
+				return err
+			}
+			res.accept(acceptedIdx[j], sub.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// DeleteBulk deletes subscriptions within a single transaction, missing
+// subscriptions are reported as rejected, as well as repeated ids.
+func (s *SubscriptionService) DeleteBulk(ctx context.Context, ids []microservice.SubscriptionID, allOrNothing bool) (res *BulkResults, err error) {
+	ctx, span := tracing.Start(ctx, "SubscriptionService.DeleteBulk")
+	defer tracing.End(span, &err)
+
+	if len(ids) == 0 || len(ids) > MaxBulkSize {
+		return nil, ErrBulkSize
+	}
+
+	err = s.tx.WithTx(ctx, func(tx storage.Storage) error {
+		res = newBulkResults(len(ids))
+
+		unique := make([]microservice.SubscriptionID, 0, len(ids))
+		seen := make(map[microservice.SubscriptionID]bool, len(ids))
+		for i, id := range ids {
+			if seen[id] {
+				res.reject(i, ErrDuplicateBulkItem)
+				continue
+			}
+			seen[id] = true
+			unique = append(unique, id)
+		}
+
+		owned, err := ownedIDs(ctx, tx.Subscriptions, unique)
+		if err != nil {
+			return err
+		}
+		deleted, err := tx.Subscriptions.DeleteMany(ctx, owned)
+		if err != nil {
+			return err
+		}
+		isDeleted := make(map[microservice.SubscriptionID]bool, len(deleted))
+		for _, id := range deleted {
+			isDeleted[id] = true
+		}
+
+		for i, id := range ids {
+			switch {
+			case res.Results[i].Error != "":
+			case isDeleted[id]:
+				res.accept(i, id)
+			default:
+				res.reject(i, ErrNoSuchSubscription)
+			}
+		}
+
+		if allOrNothing && res.Failed > 0 {
+			return res.rejection()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// queryByKeys returns stored subscriptions of users of the subscriptions to
+// their services with a single query. It may return more of them, e.g. of
+// one user to the service of another one, as overlaps are checked anyway.
+func (s *SubscriptionService) queryByKeys(ctx context.Context, subs []*microservice.Subscription) ([]*microservice.Subscription, error) {
+	if len(subs) == 0 {
+		return nil, nil
+	}
+
+	users := make([]string, 0, len(subs))
+	names := make([]string, 0, len(subs))
+	for _, sub := range subs {
+		users = append(users, sub.UserID.String())
+		names = append(names, sub.ServiceName)
+	}
+	slices.Sort(users)
+	slices.Sort(names)
+
+	return s.store.Query(ctx, &storage.QueryArgs{
+		Where: []storage.Where{
+			{Column: "user_id", Operator: storage.OpIn, Value: slices.Compact(users)},
+			{Column: "service_name", Operator: storage.OpIn, Value: slices.Compact(names)},
+		},
+	})
+}
+
+// catalogSnapshot resolves services from the catalog loaded once, so items
+// of bulk requests don't query it one by one. Other methods are served by
+// the storage.
+type catalogSnapshot struct {
+	storage.Services
+	byID  map[microservice.ServiceID]*microservice.Service
+	byKey map[string]*microservice.Service
+}
+
+func newCatalogSnapshot(ctx context.Context, store storage.Services) (*catalogSnapshot, error) {
+	svcs, err := store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &catalogSnapshot{
+		Services: store,
+		byID:     make(map[microservice.ServiceID]*microservice.Service, len(svcs)),
+		byKey:    make(map[string]*microservice.Service, len(svcs)),
+	}
+	for _, svc := range svcs {
+		c.byID[svc.ID] = svc
+		c.byKey[svc.NameKey] = svc
+		for _, key := range svc.AliasKeys {
+			c.byKey[key] = svc
+		}
+	}
+	return c, nil
+}
+
+func (c *catalogSnapshot) GetByID(_ context.Context, id microservice.ServiceID) (*microservice.Service, error) {
+	if svc, ok := c.byID[id]; ok {
+		return svc, nil
+	}
+	return nil, ErrNoSuchService
+}
+
+func (c *catalogSnapshot) GetByKey(_ context.Context, key string) (*microservice.Service, error) {
+	if svc, ok := c.byKey[key]; ok {
+		return svc, nil
+	}
+	return nil, ErrNoSuchService
+}
+
+// checkOverlapWithin checks the subscription against others of the same
+// request, which are not stored yet.
+func checkOverlapWithin(sub *microservice.Subscription, others []*microservice.Subscription) error {
+	for _, other := range others {
+		if other.UserID == sub.UserID && other.ServiceName == sub.ServiceName && periodsOverlap(sub, other) {
+			return ErrUserSubscriptionPairAlreadyExists
+		}
+	}
+	return nil
+}
+
+// periodIndex holds subscriptions by user and service, as they are after
+// the updates added so far. Replaced versions of subscriptions are kept,
+// but skipped by checks.
+type periodIndex struct {
+	byKey  map[periodKey][]*microservice.Subscription
+	latest map[microservice.SubscriptionID]*microservice.Subscription
+}
+
+type periodKey struct {
+	userID      microservice.UserID
+	serviceName string
+}
+
+func newPeriodIndex(subs []*microservice.Subscription) *periodIndex {
+	idx := &periodIndex{
+		byKey:  make(map[periodKey][]*microservice.Subscription, len(subs)),
+		latest: make(map[microservice.SubscriptionID]*microservice.Subscription, len(subs)),
+	}
+	for _, sub := range subs {
+		idx.add(sub)
+	}
+	return idx
+}
+
+func (idx *periodIndex) add(sub *microservice.Subscription) {
+	key := periodKey{userID: sub.UserID, serviceName: sub.ServiceName}
+	idx.byKey[key] = append(idx.byKey[key], sub)
+	idx.latest[sub.ID] = sub
+}
+
+// checkOverlap checks the subscription against the latest versions of
+// others of the same user and service.
+func (idx *periodIndex) checkOverlap(sub *microservice.Subscription) error {
+	for _, other := range idx.byKey[periodKey{userID: sub.UserID, serviceName: sub.ServiceName}] {
+		if other.ID != sub.ID && idx.latest[other.ID] == other && periodsOverlap(sub, other) {
+			return ErrUserSubscriptionPairAlreadyExists
+		}
+	}
+	return nil
+}
+
+// ownedIDs filters out ids of subscriptions, which the principal may not
+// access, as well as missing ones, locking the rest with a single query.
+// Admins may access all of them.
+func ownedIDs(ctx context.Context, subs storage.Subscriptions, ids []microservice.SubscriptionID) ([]microservice.SubscriptionID, error) {
+	if p, ok := auth.FromContext(ctx); !ok || p.Admin {
+		return ids, nil
+	}
+
+	locked, err := lockOwnedMany(ctx, subs, ids)
+	if err != nil {
+		return nil, err
+	}
+	owned := make([]microservice.SubscriptionID, 0, len(locked))
+	for _, id := range ids {
+		if _, ok := locked[id]; ok {
+			owned = append(owned, id)
+		}
+	}
+	return owned, nil
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	microservice "github.com/ikotiki/go-rest-api-service-subscriptions"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/storage"
+	mock_storage "github.com/ikotiki/go-rest-api-service-subscriptions/internal/storage/mocks"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newBulkTestService(t *testing.T) (*SubscriptionService, *mock_storage.MockSubscriptions) {
+	store := mock_storage.NewMockSubscriptions(t)
+	catalog := mock_storage.NewMockServices(t)
+	catalog.EXPECT().GetByKey(mock.Anything, mock.Anything).Return(nil, ErrNoSuchService).Maybe()
+	catalog.EXPECT().List(mock.Anything).Return(nil, nil).Maybe()
+	tx := mock_storage.NewMockTransactor(t)
+	tx.EXPECT().WithTx(mock.Anything, mock.Anything).RunAndReturn(func(ctx context.Context, fn func(tx storage.Storage) error) error {
+		return fn(storage.Storage{Subscriptions: store, Services: catalog, Transactor: tx})
+	}).Maybe()
+	return NewSubscriptionService(store, catalog, tx), store
+}
+
+func TestSubscriptionService_CreateBulk(t *testing.T) {
+	logger.InitLoggerByFlag("trace", false)
+
+	userID := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
+	start, _ := time.Parse("2006-01-02", "2020-03-01")
+	items := func() []*microservice.Subscription {
+		return []*microservice.Subscription{
+			{UserID: userID, ServiceName: "Local Gym", MonthlyPrice: 1500, StartDate: microservice.NewDate(start)},
+			{UserID: userID, ServiceName: "Local Gym", MonthlyPrice: 1500, StartDate: microservice.NewDate(start.AddDate(0, 1, 0))},
+			{UserID: userID, ServiceName: "Cinema", MonthlyPrice: -1, StartDate: microservice.NewDate(start)},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		allOrNothing bool
+		mock         func(store *mock_storage.MockSubscriptions)
+		want         *BulkResults
+		wantFields   []string
+	}{
+		{
+			name: "Ok (partial)",
+			mock: func(store *mock_storage.MockSubscriptions) {
+				// Stored subscriptions of all items are queried at once.
+				store.EXPECT().Query(mock.Anything, mock.Anything).Return(nil, nil).Once()
+				store.EXPECT().CreateMany(mock.Anything, mock.MatchedBy(func(subs []*microservice.Subscription) bool {
+					return len(subs) == 1
+				})).Return([]microservice.SubscriptionID{7}, nil)
+			},
+			want: &BulkResults{Succeeded: 1, Failed: 2},
+		},
+		{
+			name:         "Error (all or nothing)",
+			allOrNothing: true,
+			mock: func(store *mock_storage.MockSubscriptions) {
+				store.EXPECT().Query(mock.Anything, mock.Anything).Return(nil, nil)
+			},
+			wantFields: []string{"items[1]", "items[2].monthly_price"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, store := newBulkTestService(t)
+			tt.mock(store)
+
+			got, err := srv.CreateBulk(t.Context(), items(), tt.allOrNothing)
+			if tt.wantFields != nil {
+				var verr *ValidationError
+				if assert.ErrorAs(t, err, &verr) {
+					fields := []string{}
+					for _, f := range verr.Fields {
+						fields = append(fields, f.Field)
+					}
+					assert.Equal(t, tt.wantFields, fields)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want.Succeeded, got.Succeeded)
+			assert.Equal(t, tt.want.Failed, got.Failed)
+			assert.Equal(t, microservice.SubscriptionID(7), got.Results[0].ID)
+			assert.Equal(t, ErrUserSubscriptionPairAlreadyExists.Error(), got.Results[1].Error)
+			assert.NotEmpty(t, got.Results[2].Fields)
+		})
+	}
+}
+
+func TestSubscriptionService_UpdateBulk(t *testing.T) {
+	logger.InitLoggerByFlag("trace", false)
+
+	userID := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
+	memberID := uuid.MustParse("223e4567-e89b-12d3-a456-426614174000")
+	date := func(s string) microservice.Date {
+		d, _ := time.Parse("2006-01-02", s)
+		return microservice.NewDate(d)
+	}
+	subscription := func(id microservice.SubscriptionID, name string, price microservice.Price, start, end string) *microservice.Subscription {
+		sub := &microservice.Subscription{ID: id, UserID: userID, ServiceName: name, MonthlyPrice: price, StartDate: date(start)}
+		if end != "" {
+			sub.EndDate = date(end)
+		}
+		return sub
+	}
+	amount := microservice.Price(1000)
+
+	store := mock_storage.NewMockSubscriptions(t)
+	catalog := mock_storage.NewMockServices(t)
+	catalog.EXPECT().GetByKey(mock.Anything, mock.Anything).Return(nil, ErrNoSuchService).Maybe()
+	catalog.EXPECT().List(mock.Anything).Return(nil, nil).Once()
+	members := mock_storage.NewMockMembers(t)
+	tx := mock_storage.NewMockTransactor(t)
+	tx.EXPECT().WithTx(mock.Anything, mock.Anything).RunAndReturn(func(ctx context.Context, fn func(tx storage.Storage) error) error {
+		return fn(storage.Storage{Subscriptions: store, Services: catalog, Members: members, Transactor: tx})
+	})
+	srv := NewSubscriptionService(store, catalog, tx)
+
+	stored := []*microservice.Subscription{
+		subscription(1, "Local Gym", 1500, "2020-03-01", ""),
+		subscription(2, "Cinema", 300, "2020-03-01", ""),
+		subscription(4, "Cinema", 300, "2020-01-01", "2020-02-01"),
+		subscription(5, "Cinema", 300, "2019-01-01", "2019-02-01"),
+	}
+	store.EXPECT().GetManyForUpdate(mock.Anything, []microservice.SubscriptionID{1, 2, 3, 4, 5}).Return(stored, nil).Once()
+	members.EXPECT().ListBySubscriptions(mock.Anything, []microservice.SubscriptionID{1}).Return([]*microservice.Member{
+		{SubscriptionID: 1, UserID: memberID, ShareAmount: &amount},
+	}, nil).Once()
+	store.EXPECT().Query(mock.Anything, mock.Anything).Return(stored[1:], nil).Once()
+	store.EXPECT().Update(mock.Anything, mock.MatchedBy(func(sub *microservice.Subscription) bool {
+		return sub.ID == 2 || sub.ID == 4
+	})).Return(nil).Times(2)
+
+	got, err := srv.UpdateBulk(t.Context(), []*microservice.Subscription{
+		// Shares of members exceed the new price.
+		subscription(1, "Local Gym", 900, "2020-03-01", ""),
+		// Moved later, so the next items are checked against the new period.
+		subscription(2, "Cinema", 300, "2020-06-01", ""),
+		subscription(2, "Cinema", 300, "2020-07-01", ""),
+		subscription(3, "Cinema", 300, "2018-01-01", "2018-02-01"),
+		// Overlaps only with the former period of the second subscription.
+		subscription(4, "Cinema", 300, "2020-01-01", "2020-05-01"),
+		subscription(5, "Cinema", 300, "2020-07-01", "2020-08-01"),
+	}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, got.Succeeded)
+	assert.Equal(t, 4, got.Failed)
+
+	errs := []string{}
+	for _, res := range got.Results {
+		errs = append(errs, res.Error)
+	}
+	assert.Equal(t, []string{
+		ErrSharesExceedPrice.Error(),
+		"",
+		ErrDuplicateBulkItem.Error(),
+		ErrNoSuchSubscription.Error(),
+		"",
+		ErrUserSubscriptionPairAlreadyExists.Error(),
+	}, errs)
+}
+
+func TestSubscriptionService_DeleteBulk(t *testing.T) {
+	logger.InitLoggerByFlag("trace", false)
+
+	t.Run("Ok (missing)", func(t *testing.T) {
+		srv, store := newBulkTestService(t)
+		store.EXPECT().DeleteMany(mock.Anything, []microservice.SubscriptionID{1, 2}).Return([]microservice.SubscriptionID{2}, nil)
+
+		got, err := srv.DeleteBulk(t.Context(), []microservice.SubscriptionID{1, 2}, false)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, got.Succeeded)
+		assert.Equal(t, ErrNoSuchSubscription.Error(), got.Results[0].Error)
+	})
+
+	t.Run("Ok (repeated)", func(t *testing.T) {
+		srv, store := newBulkTestService(t)
+		store.EXPECT().DeleteMany(mock.Anything, []microservice.SubscriptionID{1}).Return([]microservice.SubscriptionID{1}, nil)
+
+		got, err := srv.DeleteBulk(t.Context(), []microservice.SubscriptionID{1, 1}, false)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, got.Succeeded)
+		assert.Equal(t, 1, got.Failed)
+		assert.Equal(t, ErrDuplicateBulkItem.Error(), got.Results[1].Error)
+	})
+
+	t.Run("Error (size)", func(t *testing.T) {
+		srv, _ := newBulkTestService(t)
+		_, err := srv.DeleteBulk(t.Context(), nil, false)
+		assert.ErrorIs(t, err, ErrBulkSize)
+	})
+}
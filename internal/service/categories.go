@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	microservice "github.com/ikotiki/go-rest-api-service-subscriptions"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/storage"
+)
+
+type CategoryService struct {
+	store storage.Categories
+}
+
+func NewCategoryService(store storage.Categories) *CategoryService {
+	return &CategoryService{store: store}
+}
+
+func (s *CategoryService) GetByID(ctx context.Context, id microservice.CategoryID) (cat *microservice.Category, err error) {
+	return s.store.GetByID(ctx, id)
+}
+
+func (s *CategoryService) Create(ctx context.Context, cat *microservice.Category) (id microservice.CategoryID, err error) {
+	if cat.Name = strings.TrimSpace(cat.Name); cat.Name == "" {
+		return 0, ErrInvalidCategoryName
+	}
+	return s.store.Create(ctx, cat)
+}
+
+func (s *CategoryService) Update(ctx context.Context, cat *microservice.Category) (err error) {
+	if cat.Name = strings.TrimSpace(cat.Name); cat.Name == "" {
+		return ErrInvalidCategoryName
+	}
+	return s.store.Update(ctx, cat)
+}
+
+func (s *CategoryService) DeleteByID(ctx context.Context, id microservice.CategoryID) (err error) {
+	return s.store.DeleteByID(ctx, id)
+}
+
+func (s *CategoryService) List(ctx context.Context) (cats []*microservice.Category, err error) {
+	return s.store.List(ctx)
+}
@@ -0,0 +1,294 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	microservice "github.com/ikotiki/go-rest-api-service-subscriptions"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/tracing"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultImportDateFormat is a layout of dates in imported files.
+	DefaultImportDateFormat = "2006-01-02"
+	// DefaultImportBatchSize is a number of rows created within a single transaction.
+	DefaultImportBatchSize = 500
+)
+
+var ErrInvalidImport = errors.New("invalid import file")
+
+// Statuses of rows in the import report.
+const (
+	ImportRowSkipped = "skipped"
+	ImportRowInvalid = "invalid"
+)
+
+// importFields are subscription fields, which can be read from a file.
+var importFields = []string{"user_id", "service_id", "service_name", "category_id", "monthly_price", "start_date", "end_date"}
+
+// ImportOptions configure reading subscriptions from a CSV file.
+type ImportOptions struct {
+	// Mapping maps subscription fields to columns of the file, fields
+	// missing in it are read from columns of the same name.
+	Mapping map[string]string
+	// DateFormat is a layout of dates, DefaultImportDateFormat by default.
+	DateFormat string
+	// DryRun checks rows without creating subscriptions.
+	DryRun bool
+	// BatchSize is a number of rows created at once, DefaultImportBatchSize by default.
+	BatchSize int
+}
+
+// ImportRow describes a row of the file, which was not imported.
+type ImportRow struct {
+	Line   int          `json:"line" example:"2"`
+	Status string       `json:"status" example:"invalid"`
+	Error  string       `json:"error" example:"validation failed: monthly_price must not be negative"`
+	Fields []FieldError `json:"fields,omitempty"`
+}
+
+// ImportReport counts rows of the file by outcome. Duplicates are rows of
+// subscriptions, which overlap with existing ones of the same user and service.
+type ImportReport struct {
+	DryRun  bool         `json:"dry_run" example:"false"`
+	Created int          `json:"created" example:"10"`
+	Skipped int          `json:"skipped" example:"1"`
+	Invalid int          `json:"invalid" example:"1"`
+	Rows    []*ImportRow `json:"rows"`
+}
+
+func (r *ImportReport) addError(line int, err error) {
+	row := &ImportRow{Line: line, Status: ImportRowInvalid, Error: err.Error()}
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		row.Fields = verr.Fields
+	}
+	if errors.Is(err, ErrUserSubscriptionPairAlreadyExists) {
+		row.Status = ImportRowSkipped
+		r.Skipped++
+	} else {
+		r.Invalid++
+	}
+	r.Rows = append(r.Rows, row)
+}
+
+// Import reads subscriptions from the CSV file with a header row and
+// creates them in batches, each within its own transaction. Invalid and
+// duplicate rows are reported and do not stop the import.
+func (s *SubscriptionService) Import(ctx context.Context, r io.Reader, opts *ImportOptions) (report *ImportReport, err error) {
+	ctx, span := tracing.Start(ctx, "SubscriptionService.Import")
+	defer tracing.End(span, &err)
+
+	if opts == nil {
+		opts = &ImportOptions{}
+	}
+	dateFormat := opts.DateFormat
+	if dateFormat == "" {
+		dateFormat = DefaultImportDateFormat
+	}
+	batchSize := opts.BatchSize
+	if batchSize <= 0 || batchSize > MaxBulkSize {
+		batchSize = DefaultImportBatchSize
+	}
+
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("%w: no header", ErrInvalidImport)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidImport, err)
+	}
+	columns, err := importColumns(header, opts.Mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	report = &ImportReport{DryRun: opts.DryRun, Rows: []*ImportRow{}}
+
+	// Rows of a dry run are not stored, so duplicates within the file
+	// are found among the accepted ones.
+	var accepted []*microservice.Subscription
+
+	batch := make([]*microservice.Subscription, 0, batchSize)
+	lines := make([]int, 0, batchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		res, err := s.createBulk(ctx, batch, false, opts.DryRun)
+		if err != nil {
+			return err
+		}
+		for i, item := range res.Results {
+			if item.Error == "" {
+				report.Created++
+				if opts.DryRun {
+					accepted = append(accepted, batch[i])
+				}
+				continue
+			}
+			report.addError(lines[i], item.err)
+		}
+		batch, lines = batch[:0], lines[:0]
+		return nil
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// The reader skips a malformed record and goes on with the next one.
+			var perr *csv.ParseError
+			if errors.As(err, &perr) {
+				report.addError(perr.StartLine, err)
+				continue
+			}
+			return nil, fmt.Errorf("%w: %w", ErrInvalidImport, err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		sub, err := parseImportRecord(record, columns, dateFormat)
+		if err == nil && opts.DryRun {
+			err = checkOverlapWithin(sub, accepted)
+		}
+		if err == nil {
+			err = checkOverlapWithin(sub, batch)
+		}
+		if err != nil {
+			report.addError(line, err)
+			continue
+		}
+
+		batch = append(batch, sub)
+		lines = append(lines, line)
+		if len(batch) == batchSize {
+			if err = flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err = flush(); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// importColumns returns positions of subscription fields in the header,
+// fields without columns are omitted.
+func importColumns(header []string, mapping map[string]string) (map[string]int, error) {
+	for field := range mapping {
+		if !isImportField(field) {
+			return nil, fmt.Errorf("%w: unknown field %q in mapping", ErrInvalidImport, field)
+		}
+	}
+
+	positions := make(map[string]int, len(header))
+	for i, name := range header {
+		positions[strings.TrimSpace(name)] = i
+	}
+
+	columns := make(map[string]int, len(importFields))
+	for _, field := range importFields {
+		name := field
+		if mapped, ok := mapping[field]; ok {
+			name = mapped
+		}
+		if i, ok := positions[name]; ok {
+			columns[field] = i
+		}
+	}
+
+	for _, field := range []string{"user_id", "start_date"} {
+		if _, ok := columns[field]; !ok {
+			return nil, fmt.Errorf("%w: no column for %s", ErrInvalidImport, field)
+		}
+	}
+	return columns, nil
+}
+
+func isImportField(field string) bool {
+	for _, f := range importFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// parseImportRecord reads the subscription from the record, empty values
+// are left unset.
+func parseImportRecord(record []string, columns map[string]int, dateFormat string) (*microservice.Subscription, error) {
+	sub := &microservice.Subscription{}
+	verr := &ValidationError{}
+
+	value := func(field string) string {
+		i, ok := columns[field]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	parseInt := func(field string) (int64, bool) {
+		v := value(field)
+		if v == "" {
+			return 0, false
+		}
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			verr.add(field, "must be an integer")
+			return 0, false
+		}
+		return n, true
+	}
+	parseDate := func(field string) microservice.Date {
+		v := value(field)
+		if v == "" {
+			return microservice.Date{}
+		}
+		t, err := time.Parse(dateFormat, v)
+		if err != nil {
+			verr.add(field, "must be a date in format "+dateFormat)
+			return microservice.Date{}
+		}
+		return microservice.NewDate(t)
+	}
+
+	if v := value("user_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			verr.add("user_id", "must be a UUID")
+		}
+		sub.UserID = id
+	}
+	if id, ok := parseInt("service_id"); ok {
+		sub.ServiceID = &id
+	}
+	sub.ServiceName = value("service_name")
+	if id, ok := parseInt("category_id"); ok {
+		sub.CategoryID = &id
+	}
+	if price, ok := parseInt("monthly_price"); ok {
+		sub.MonthlyPrice = microservice.Price(price)
+	}
+	sub.StartDate = parseDate("start_date")
+	sub.EndDate = parseDate("end_date")
+
+	if err := verr.err(); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
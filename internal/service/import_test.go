@@ -0,0 +1,103 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	microservice "github.com/ikotiki/go-rest-api-service-subscriptions"
+	mock_storage "github.com/ikotiki/go-rest-api-service-subscriptions/internal/storage/mocks"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSubscriptionService_Import(t *testing.T) {
+	logger.InitLoggerByFlag("trace", false)
+
+	const file = `User,service_name,monthly_price,start_date
+123e4567-e89b-12d3-a456-426614174000,Local Gym,1500,01.03.2020
+123e4567-e89b-12d3-a456-426614174000,Local Gym,1500,01.04.2020
+not-a-uuid,Cinema,300,2020-03-01
+123e4567-e89b-12d3-a456-426614174000,Cinema
+`
+
+	tests := []struct {
+		name    string
+		dryRun  bool
+		mock    func(store *mock_storage.MockSubscriptions)
+		created int
+	}{
+		{
+			name: "Ok",
+			mock: func(store *mock_storage.MockSubscriptions) {
+				store.EXPECT().Query(mock.Anything, mock.Anything).Return(nil, nil)
+				store.EXPECT().CreateMany(mock.Anything, mock.MatchedBy(func(subs []*microservice.Subscription) bool {
+					return len(subs) == 1 && subs[0].MonthlyPrice == 1500
+				})).Return([]microservice.SubscriptionID{1}, nil).Once()
+			},
+			created: 1,
+		},
+		{
+			name:   "Ok (dry run)",
+			dryRun: true,
+			mock: func(store *mock_storage.MockSubscriptions) {
+				store.EXPECT().Query(mock.Anything, mock.Anything).Return(nil, nil)
+			},
+			created: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, store := newBulkTestService(t)
+			tt.mock(store)
+
+			got, err := srv.Import(t.Context(), strings.NewReader(file), &ImportOptions{
+				Mapping:    map[string]string{"user_id": "User"},
+				DateFormat: "02.01.2006",
+				DryRun:     tt.dryRun,
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.dryRun, got.DryRun)
+			assert.Equal(t, tt.created, got.Created)
+			assert.Equal(t, 1, got.Skipped)
+			assert.Equal(t, 2, got.Invalid)
+
+			lines := []int{}
+			for _, row := range got.Rows {
+				lines = append(lines, row.Line)
+			}
+			assert.Equal(t, []int{3, 4, 5}, lines)
+			assert.Equal(t, ImportRowSkipped, got.Rows[0].Status)
+			assert.Equal(t, []string{"user_id", "start_date"}, []string{got.Rows[1].Fields[0].Field, got.Rows[1].Fields[1].Field})
+		})
+	}
+}
+
+func TestSubscriptionService_Import_Malformed(t *testing.T) {
+	const file = `user_id,service_name,monthly_price,start_date
+123e4567-e89b-12d3-a456-426614174000,Local "Gym,1500,2020-03-01
+123e4567-e89b-12d3-a456-426614174000,"Local" Gym,1500,2020-03-01
+`
+	srv := &SubscriptionService{}
+
+	got, err := srv.Import(t.Context(), strings.NewReader(file), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, got.Created)
+	assert.Equal(t, 2, got.Invalid)
+	assert.Equal(t, 2, got.Rows[0].Line)
+	assert.Equal(t, 3, got.Rows[1].Line)
+}
+
+func TestSubscriptionService_Import_Header(t *testing.T) {
+	srv := &SubscriptionService{}
+
+	_, err := srv.Import(t.Context(), strings.NewReader("user,start_date\n"), nil)
+	assert.ErrorIs(t, err, ErrInvalidImport)
+
+	_, err = srv.Import(t.Context(), strings.NewReader("user_id,start_date\n"), &ImportOptions{Mapping: map[string]string{"price": "Price"}})
+	assert.ErrorIs(t, err, ErrInvalidImport)
+
+	_, err = srv.Import(t.Context(), strings.NewReader(""), nil)
+	assert.ErrorIs(t, err, ErrInvalidImport)
+}
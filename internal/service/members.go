@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"math"
+	"slices"
+
+	microservice "github.com/ikotiki/go-rest-api-service-subscriptions"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+type MemberService struct {
+	store storage.Members
+	subs  storage.Subscriptions
+	tx    storage.Transactor
+}
+
+func NewMemberService(store storage.Members, subs storage.Subscriptions, tx storage.Transactor) *MemberService {
+	return &MemberService{store: store, subs: subs, tx: tx}
+}
+
+// memberShare calculates the part of the price paid by the member, the same
+// way as subscription_shares view does.
+func memberShare(m *microservice.Member, price microservice.Price) microservice.Price {
+	if m.ShareAmount != nil {
+		return *m.ShareAmount
+	}
+	if m.SharePercent != nil {
+		return microservice.Price(math.Round(float64(price) * *m.SharePercent / 100))
+	}
+	return 0
+}
+
+// checkShares reports ErrSharesExceedPrice, if shares of members don't fit
+// into the price.
+func checkShares(members []*microservice.Member, price microservice.Price) error {
+	var total microservice.Price
+	for _, m := range members {
+		total += memberShare(m, price)
+	}
+	if total > price {
+		return ErrSharesExceedPrice
+	}
+	return nil
+}
+
+func validateShare(m *microservice.Member) error {
+	if m.UserID == uuid.Nil {
+		return ErrNoUserID
+	}
+	switch {
+	case (m.SharePercent == nil) == (m.ShareAmount == nil):
+		return ErrInvalidShare
+	case m.SharePercent != nil && (*m.SharePercent <= 0 || *m.SharePercent > 100):
+		return ErrInvalidShare
+	case m.ShareAmount != nil && *m.ShareAmount < 0:
+		return ErrInvalidShare
+	}
+	return nil
+}
+
+// Add adds the member to the subscription or changes the member's share and
+// returns all members of the subscription. Shares of members can't exceed
+// the subscription price, the subscription is locked while they are
+// checked, so concurrent changes of members and the price can't overflow it.
+func (s *MemberService) Add(ctx context.Context, m *microservice.Member) (members []*microservice.Member, err error) {
+	if err = validateShare(m); err != nil {
+		return nil, err
+	}
+
+	err = s.tx.WithTx(ctx, func(tx storage.Storage) error {
+		sub, err := lockOwned(ctx, tx.Subscriptions, m.SubscriptionID)
+		if err != nil {
+			return err
+		}
+
+		members, err := tx.Members.ListBySubscription(ctx, m.SubscriptionID)
+		if err != nil {
+			return err
+		}
+		members = slices.DeleteFunc(members, func(member *microservice.Member) bool { return member.UserID == m.UserID })
+		if err = checkShares(append(members, m), sub.MonthlyPrice); err != nil {
+			return err
+		}
+
+		return tx.Members.Add(ctx, m)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.store.ListBySubscription(ctx, m.SubscriptionID)
+}
+
+func (s *MemberService) Remove(ctx context.Context, id microservice.SubscriptionID, userID microservice.UserID) (err error) {
+	if _, err = getOwned(ctx, s.subs, id); err != nil {
+		return err
+	}
+	return s.store.Remove(ctx, id, userID)
+}
+
+func (s *MemberService) ListBySubscription(ctx context.Context, id microservice.SubscriptionID) (members []*microservice.Member, err error) {
+	if _, err = getReadable(ctx, s.subs, id); err != nil {
+		return nil, err
+	}
+	return s.store.ListBySubscription(ctx, id)
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	microservice "github.com/ikotiki/go-rest-api-service-subscriptions"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/storage"
+	mock_storage "github.com/ikotiki/go-rest-api-service-subscriptions/internal/storage/mocks"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMemberService_Add(t *testing.T) {
+	logger.InitLoggerByFlag("trace", false)
+
+	ownerID := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
+	userID := uuid.MustParse("223e4567-e89b-12d3-a456-426614174000")
+	otherID := uuid.MustParse("323e4567-e89b-12d3-a456-426614174000")
+	sub := &microservice.Subscription{ID: 1, UserID: ownerID, MonthlyPrice: 400}
+
+	percent := func(v float64) *float64 { return &v }
+	amount := func(v microservice.Price) *microservice.Price { return &v }
+
+	tests := []struct {
+		name    string
+		mock    func(store *mock_storage.MockMembers, subs *mock_storage.MockSubscriptions)
+		input   *microservice.Member
+		want    []*microservice.Member
+		wantErr error
+	}{
+		{
+			name: "Ok",
+			mock: func(store *mock_storage.MockMembers, subs *mock_storage.MockSubscriptions) {
+				existing := []*microservice.Member{{SubscriptionID: 1, UserID: otherID, ShareAmount: amount(100)}}
+				subs.EXPECT().GetByIDForUpdate(mock.Anything, microservice.SubscriptionID(1)).Return(sub, nil)
+				store.EXPECT().ListBySubscription(mock.Anything, microservice.SubscriptionID(1)).Return(existing, nil).Once()
+				store.EXPECT().Add(mock.Anything, mock.Anything).Return(nil)
+				store.EXPECT().ListBySubscription(mock.Anything, microservice.SubscriptionID(1)).Return([]*microservice.Member{
+					existing[0], {SubscriptionID: 1, UserID: userID, SharePercent: percent(50)},
+				}, nil).Once()
+			},
+			input: &microservice.Member{SubscriptionID: 1, UserID: userID, SharePercent: percent(50)},
+			want: []*microservice.Member{
+				{SubscriptionID: 1, UserID: otherID, ShareAmount: amount(100)},
+				{SubscriptionID: 1, UserID: userID, SharePercent: percent(50)},
+			},
+		},
+		{
+			name: "Ok (change own share)",
+			mock: func(store *mock_storage.MockMembers, subs *mock_storage.MockSubscriptions) {
+				existing := []*microservice.Member{{SubscriptionID: 1, UserID: userID, ShareAmount: amount(400)}}
+				subs.EXPECT().GetByIDForUpdate(mock.Anything, microservice.SubscriptionID(1)).Return(sub, nil)
+				store.EXPECT().ListBySubscription(mock.Anything, microservice.SubscriptionID(1)).Return(existing, nil).Once()
+				store.EXPECT().Add(mock.Anything, mock.Anything).Return(nil)
+				store.EXPECT().ListBySubscription(mock.Anything, microservice.SubscriptionID(1)).Return([]*microservice.Member{
+					{SubscriptionID: 1, UserID: userID, ShareAmount: amount(300)},
+				}, nil).Once()
+			},
+			input: &microservice.Member{SubscriptionID: 1, UserID: userID, ShareAmount: amount(300)},
+			want:  []*microservice.Member{{SubscriptionID: 1, UserID: userID, ShareAmount: amount(300)}},
+		},
+		{
+			name: "Error (shares exceed price)",
+			mock: func(store *mock_storage.MockMembers, subs *mock_storage.MockSubscriptions) {
+				existing := []*microservice.Member{{SubscriptionID: 1, UserID: otherID, SharePercent: percent(60)}}
+				subs.EXPECT().GetByIDForUpdate(mock.Anything, microservice.SubscriptionID(1)).Return(sub, nil)
+				store.EXPECT().ListBySubscription(mock.Anything, microservice.SubscriptionID(1)).Return(existing, nil)
+			},
+			input:   &microservice.Member{SubscriptionID: 1, UserID: userID, ShareAmount: amount(161)},
+			wantErr: ErrSharesExceedPrice,
+		},
+		{
+			name: "Error (no subscription)",
+			mock: func(store *mock_storage.MockMembers, subs *mock_storage.MockSubscriptions) {
+				subs.EXPECT().GetByIDForUpdate(mock.Anything, microservice.SubscriptionID(1)).Return(nil, ErrNoSuchSubscription)
+			},
+			input:   &microservice.Member{SubscriptionID: 1, UserID: userID, SharePercent: percent(50)},
+			wantErr: ErrNoSuchSubscription,
+		},
+		{
+			name:    "Error (both shares)",
+			mock:    func(store *mock_storage.MockMembers, subs *mock_storage.MockSubscriptions) {},
+			input:   &microservice.Member{SubscriptionID: 1, UserID: userID, SharePercent: percent(50), ShareAmount: amount(100)},
+			wantErr: ErrInvalidShare,
+		},
+		{
+			name:    "Error (no share)",
+			mock:    func(store *mock_storage.MockMembers, subs *mock_storage.MockSubscriptions) {},
+			input:   &microservice.Member{SubscriptionID: 1, UserID: userID},
+			wantErr: ErrInvalidShare,
+		},
+		{
+			name:    "Error (percent out of range)",
+			mock:    func(store *mock_storage.MockMembers, subs *mock_storage.MockSubscriptions) {},
+			input:   &microservice.Member{SubscriptionID: 1, UserID: userID, SharePercent: percent(101)},
+			wantErr: ErrInvalidShare,
+		},
+		{
+			name:    "Error (no user)",
+			mock:    func(store *mock_storage.MockMembers, subs *mock_storage.MockSubscriptions) {},
+			input:   &microservice.Member{SubscriptionID: 1, SharePercent: percent(50)},
+			wantErr: ErrNoUserID,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := mock_storage.NewMockMembers(t)
+			subs := mock_storage.NewMockSubscriptions(t)
+			tt.mock(store, subs)
+			tx := mock_storage.NewMockTransactor(t)
+			tx.EXPECT().WithTx(mock.Anything, mock.Anything).RunAndReturn(func(ctx context.Context, fn func(tx storage.Storage) error) error {
+				return fn(storage.Storage{Subscriptions: subs, Members: store, Transactor: tx})
+			}).Maybe()
+			srv := NewMemberService(store, subs, tx)
+
+			got, err := srv.Add(t.Context(), tt.input)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
@@ -7,17 +7,19 @@ package service
 import (
 	"context"
 	"github.com/ikotiki/go-rest-api-service-subscriptions"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/auth"
 	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/service"
 	mock "github.com/stretchr/testify/mock"
+	"io"
 )
 
-// NewMockSubscriptions creates a new instance of MockSubscriptions. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// NewMockAPIKeys creates a new instance of MockAPIKeys. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
-func NewMockSubscriptions(t interface {
+func NewMockAPIKeys(t interface {
 	mock.TestingT
 	Cleanup(func())
-}) *MockSubscriptions {
-	mock := &MockSubscriptions{}
+}) *MockAPIKeys {
+	mock := &MockAPIKeys{}
 	mock.Mock.Test(t)
 
 	t.Cleanup(func() { mock.AssertExpectations(t) })
@@ -25,66 +27,66 @@ func NewMockSubscriptions(t interface {
 	return mock
 }
 
-// MockSubscriptions is an autogenerated mock type for the Subscriptions type
-type MockSubscriptions struct {
+// MockAPIKeys is an autogenerated mock type for the APIKeys type
+type MockAPIKeys struct {
 	mock.Mock
 }
 
-type MockSubscriptions_Expecter struct {
+type MockAPIKeys_Expecter struct {
 	mock *mock.Mock
 }
 
-func (_m *MockSubscriptions) EXPECT() *MockSubscriptions_Expecter {
-	return &MockSubscriptions_Expecter{mock: &_m.Mock}
+func (_m *MockAPIKeys) EXPECT() *MockAPIKeys_Expecter {
+	return &MockAPIKeys_Expecter{mock: &_m.Mock}
 }
 
-// Create provides a mock function for the type MockSubscriptions
-func (_mock *MockSubscriptions) Create(ctx context.Context, sub *microservice.Subscription) (microservice.SubscriptionID, error) {
-	ret := _mock.Called(ctx, sub)
+// Create provides a mock function for the type MockAPIKeys
+func (_mock *MockAPIKeys) Create(ctx context.Context, key *microservice.APIKey) (string, error) {
+	ret := _mock.Called(ctx, key)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Create")
 	}
 
-	var r0 microservice.SubscriptionID
+	var r0 string
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *microservice.Subscription) (microservice.SubscriptionID, error)); ok {
-		return returnFunc(ctx, sub)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *microservice.APIKey) (string, error)); ok {
+		return returnFunc(ctx, key)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *microservice.Subscription) microservice.SubscriptionID); ok {
-		r0 = returnFunc(ctx, sub)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *microservice.APIKey) string); ok {
+		r0 = returnFunc(ctx, key)
 	} else {
-		r0 = ret.Get(0).(microservice.SubscriptionID)
+		r0 = ret.Get(0).(string)
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, *microservice.Subscription) error); ok {
-		r1 = returnFunc(ctx, sub)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *microservice.APIKey) error); ok {
+		r1 = returnFunc(ctx, key)
 	} else {
 		r1 = ret.Error(1)
 	}
 	return r0, r1
 }
 
-// MockSubscriptions_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
-type MockSubscriptions_Create_Call struct {
+// MockAPIKeys_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockAPIKeys_Create_Call struct {
 	*mock.Call
 }
 
 // Create is a helper method to define mock.On call
 //   - ctx context.Context
-//   - sub *microservice.Subscription
-func (_e *MockSubscriptions_Expecter) Create(ctx interface{}, sub interface{}) *MockSubscriptions_Create_Call {
-	return &MockSubscriptions_Create_Call{Call: _e.mock.On("Create", ctx, sub)}
+//   - key *microservice.APIKey
+func (_e *MockAPIKeys_Expecter) Create(ctx interface{}, key interface{}) *MockAPIKeys_Create_Call {
+	return &MockAPIKeys_Create_Call{Call: _e.mock.On("Create", ctx, key)}
 }
 
-func (_c *MockSubscriptions_Create_Call) Run(run func(ctx context.Context, sub *microservice.Subscription)) *MockSubscriptions_Create_Call {
+func (_c *MockAPIKeys_Create_Call) Run(run func(ctx context.Context, key *microservice.APIKey)) *MockAPIKeys_Create_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
 			arg0 = args[0].(context.Context)
 		}
-		var arg1 *microservice.Subscription
+		var arg1 *microservice.APIKey
 		if args[1] != nil {
-			arg1 = args[1].(*microservice.Subscription)
+			arg1 = args[1].(*microservice.APIKey)
 		}
 		run(
 			arg0,
@@ -94,26 +96,88 @@ func (_c *MockSubscriptions_Create_Call) Run(run func(ctx context.Context, sub *
 	return _c
 }
 
-func (_c *MockSubscriptions_Create_Call) Return(id microservice.SubscriptionID, err error) *MockSubscriptions_Create_Call {
-	_c.Call.Return(id, err)
+func (_c *MockAPIKeys_Create_Call) Return(secret string, err error) *MockAPIKeys_Create_Call {
+	_c.Call.Return(secret, err)
 	return _c
 }
 
-func (_c *MockSubscriptions_Create_Call) RunAndReturn(run func(ctx context.Context, sub *microservice.Subscription) (microservice.SubscriptionID, error)) *MockSubscriptions_Create_Call {
+func (_c *MockAPIKeys_Create_Call) RunAndReturn(run func(ctx context.Context, key *microservice.APIKey) (string, error)) *MockAPIKeys_Create_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// DeleteByID provides a mock function for the type MockSubscriptions
-func (_mock *MockSubscriptions) DeleteByID(ctx context.Context, id microservice.SubscriptionID) error {
+// List provides a mock function for the type MockAPIKeys
+func (_mock *MockAPIKeys) List(ctx context.Context) ([]*microservice.APIKey, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []*microservice.APIKey
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]*microservice.APIKey, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) []*microservice.APIKey); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*microservice.APIKey)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAPIKeys_List_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'List'
+type MockAPIKeys_List_Call struct {
+	*mock.Call
+}
+
+// List is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockAPIKeys_Expecter) List(ctx interface{}) *MockAPIKeys_List_Call {
+	return &MockAPIKeys_List_Call{Call: _e.mock.On("List", ctx)}
+}
+
+func (_c *MockAPIKeys_List_Call) Run(run func(ctx context.Context)) *MockAPIKeys_List_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAPIKeys_List_Call) Return(keys []*microservice.APIKey, err error) *MockAPIKeys_List_Call {
+	_c.Call.Return(keys, err)
+	return _c
+}
+
+func (_c *MockAPIKeys_List_Call) RunAndReturn(run func(ctx context.Context) ([]*microservice.APIKey, error)) *MockAPIKeys_List_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Revoke provides a mock function for the type MockAPIKeys
+func (_mock *MockAPIKeys) Revoke(ctx context.Context, id microservice.APIKeyID) error {
 	ret := _mock.Called(ctx, id)
 
 	if len(ret) == 0 {
-		panic("no return value specified for DeleteByID")
+		panic("no return value specified for Revoke")
 	}
 
 	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, microservice.SubscriptionID) error); ok {
+	if returnFunc, ok := ret.Get(0).(func(context.Context, microservice.APIKeyID) error); ok {
 		r0 = returnFunc(ctx, id)
 	} else {
 		r0 = ret.Error(0)
@@ -121,27 +185,27 @@ func (_mock *MockSubscriptions) DeleteByID(ctx context.Context, id microservice.
 	return r0
 }
 
-// MockSubscriptions_DeleteByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteByID'
-type MockSubscriptions_DeleteByID_Call struct {
+// MockAPIKeys_Revoke_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Revoke'
+type MockAPIKeys_Revoke_Call struct {
 	*mock.Call
 }
 
-// DeleteByID is a helper method to define mock.On call
+// Revoke is a helper method to define mock.On call
 //   - ctx context.Context
-//   - id microservice.SubscriptionID
-func (_e *MockSubscriptions_Expecter) DeleteByID(ctx interface{}, id interface{}) *MockSubscriptions_DeleteByID_Call {
-	return &MockSubscriptions_DeleteByID_Call{Call: _e.mock.On("DeleteByID", ctx, id)}
+//   - id microservice.APIKeyID
+func (_e *MockAPIKeys_Expecter) Revoke(ctx interface{}, id interface{}) *MockAPIKeys_Revoke_Call {
+	return &MockAPIKeys_Revoke_Call{Call: _e.mock.On("Revoke", ctx, id)}
 }
 
-func (_c *MockSubscriptions_DeleteByID_Call) Run(run func(ctx context.Context, id microservice.SubscriptionID)) *MockSubscriptions_DeleteByID_Call {
+func (_c *MockAPIKeys_Revoke_Call) Run(run func(ctx context.Context, id microservice.APIKeyID)) *MockAPIKeys_Revoke_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
 			arg0 = args[0].(context.Context)
 		}
-		var arg1 microservice.SubscriptionID
+		var arg1 microservice.APIKeyID
 		if args[1] != nil {
-			arg1 = args[1].(microservice.SubscriptionID)
+			arg1 = args[1].(microservice.APIKeyID)
 		}
 		run(
 			arg0,
@@ -151,65 +215,65 @@ func (_c *MockSubscriptions_DeleteByID_Call) Run(run func(ctx context.Context, i
 	return _c
 }
 
-func (_c *MockSubscriptions_DeleteByID_Call) Return(err error) *MockSubscriptions_DeleteByID_Call {
+func (_c *MockAPIKeys_Revoke_Call) Return(err error) *MockAPIKeys_Revoke_Call {
 	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *MockSubscriptions_DeleteByID_Call) RunAndReturn(run func(ctx context.Context, id microservice.SubscriptionID) error) *MockSubscriptions_DeleteByID_Call {
+func (_c *MockAPIKeys_Revoke_Call) RunAndReturn(run func(ctx context.Context, id microservice.APIKeyID) error) *MockAPIKeys_Revoke_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetByID provides a mock function for the type MockSubscriptions
-func (_mock *MockSubscriptions) GetByID(ctx context.Context, id microservice.SubscriptionID) (*microservice.Subscription, error) {
-	ret := _mock.Called(ctx, id)
+// Verify provides a mock function for the type MockAPIKeys
+func (_mock *MockAPIKeys) Verify(ctx context.Context, key string) (*auth.Principal, error) {
+	ret := _mock.Called(ctx, key)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetByID")
+		panic("no return value specified for Verify")
 	}
 
-	var r0 *microservice.Subscription
+	var r0 *auth.Principal
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, microservice.SubscriptionID) (*microservice.Subscription, error)); ok {
-		return returnFunc(ctx, id)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*auth.Principal, error)); ok {
+		return returnFunc(ctx, key)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, microservice.SubscriptionID) *microservice.Subscription); ok {
-		r0 = returnFunc(ctx, id)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *auth.Principal); ok {
+		r0 = returnFunc(ctx, key)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*microservice.Subscription)
+			r0 = ret.Get(0).(*auth.Principal)
 		}
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, microservice.SubscriptionID) error); ok {
-		r1 = returnFunc(ctx, id)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, key)
 	} else {
 		r1 = ret.Error(1)
 	}
 	return r0, r1
 }
 
-// MockSubscriptions_GetByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByID'
-type MockSubscriptions_GetByID_Call struct {
+// MockAPIKeys_Verify_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Verify'
+type MockAPIKeys_Verify_Call struct {
 	*mock.Call
 }
 
-// GetByID is a helper method to define mock.On call
+// Verify is a helper method to define mock.On call
 //   - ctx context.Context
-//   - id microservice.SubscriptionID
-func (_e *MockSubscriptions_Expecter) GetByID(ctx interface{}, id interface{}) *MockSubscriptions_GetByID_Call {
-	return &MockSubscriptions_GetByID_Call{Call: _e.mock.On("GetByID", ctx, id)}
+//   - key string
+func (_e *MockAPIKeys_Expecter) Verify(ctx interface{}, key interface{}) *MockAPIKeys_Verify_Call {
+	return &MockAPIKeys_Verify_Call{Call: _e.mock.On("Verify", ctx, key)}
 }
 
-func (_c *MockSubscriptions_GetByID_Call) Run(run func(ctx context.Context, id microservice.SubscriptionID)) *MockSubscriptions_GetByID_Call {
+func (_c *MockAPIKeys_Verify_Call) Run(run func(ctx context.Context, key string)) *MockAPIKeys_Verify_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
 			arg0 = args[0].(context.Context)
 		}
-		var arg1 microservice.SubscriptionID
+		var arg1 string
 		if args[1] != nil {
-			arg1 = args[1].(microservice.SubscriptionID)
+			arg1 = args[1].(string)
 		}
 		run(
 			arg0,
@@ -219,65 +283,90 @@ func (_c *MockSubscriptions_GetByID_Call) Run(run func(ctx context.Context, id m
 	return _c
 }
 
-func (_c *MockSubscriptions_GetByID_Call) Return(sub *microservice.Subscription, err error) *MockSubscriptions_GetByID_Call {
-	_c.Call.Return(sub, err)
+func (_c *MockAPIKeys_Verify_Call) Return(p *auth.Principal, err error) *MockAPIKeys_Verify_Call {
+	_c.Call.Return(p, err)
 	return _c
 }
 
-func (_c *MockSubscriptions_GetByID_Call) RunAndReturn(run func(ctx context.Context, id microservice.SubscriptionID) (*microservice.Subscription, error)) *MockSubscriptions_GetByID_Call {
+func (_c *MockAPIKeys_Verify_Call) RunAndReturn(run func(ctx context.Context, key string) (*auth.Principal, error)) *MockAPIKeys_Verify_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// Query provides a mock function for the type MockSubscriptions
-func (_mock *MockSubscriptions) Query(ctx context.Context, args *service.SubscriptionQueryArgs) ([]*microservice.Subscription, error) {
-	ret := _mock.Called(ctx, args)
+// NewMockCategories creates a new instance of MockCategories. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockCategories(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockCategories {
+	mock := &MockCategories{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockCategories is an autogenerated mock type for the Categories type
+type MockCategories struct {
+	mock.Mock
+}
+
+type MockCategories_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockCategories) EXPECT() *MockCategories_Expecter {
+	return &MockCategories_Expecter{mock: &_m.Mock}
+}
+
+// Create provides a mock function for the type MockCategories
+func (_mock *MockCategories) Create(ctx context.Context, cat *microservice.Category) (microservice.CategoryID, error) {
+	ret := _mock.Called(ctx, cat)
 
 	if len(ret) == 0 {
-		panic("no return value specified for Query")
+		panic("no return value specified for Create")
 	}
 
-	var r0 []*microservice.Subscription
+	var r0 microservice.CategoryID
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *service.SubscriptionQueryArgs) ([]*microservice.Subscription, error)); ok {
-		return returnFunc(ctx, args)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *microservice.Category) (microservice.CategoryID, error)); ok {
+		return returnFunc(ctx, cat)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *service.SubscriptionQueryArgs) []*microservice.Subscription); ok {
-		r0 = returnFunc(ctx, args)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *microservice.Category) microservice.CategoryID); ok {
+		r0 = returnFunc(ctx, cat)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*microservice.Subscription)
-		}
+		r0 = ret.Get(0).(microservice.CategoryID)
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, *service.SubscriptionQueryArgs) error); ok {
-		r1 = returnFunc(ctx, args)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *microservice.Category) error); ok {
+		r1 = returnFunc(ctx, cat)
 	} else {
 		r1 = ret.Error(1)
 	}
 	return r0, r1
 }
 
-// MockSubscriptions_Query_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Query'
-type MockSubscriptions_Query_Call struct {
+// MockCategories_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockCategories_Create_Call struct {
 	*mock.Call
 }
 
-// Query is a helper method to define mock.On call
+// Create is a helper method to define mock.On call
 //   - ctx context.Context
-//   - args *service.SubscriptionQueryArgs
-func (_e *MockSubscriptions_Expecter) Query(ctx interface{}, args interface{}) *MockSubscriptions_Query_Call {
-	return &MockSubscriptions_Query_Call{Call: _e.mock.On("Query", ctx, args)}
+//   - cat *microservice.Category
+func (_e *MockCategories_Expecter) Create(ctx interface{}, cat interface{}) *MockCategories_Create_Call {
+	return &MockCategories_Create_Call{Call: _e.mock.On("Create", ctx, cat)}
 }
 
-func (_c *MockSubscriptions_Query_Call) Run(run func(ctx context.Context, args *service.SubscriptionQueryArgs)) *MockSubscriptions_Query_Call {
+func (_c *MockCategories_Create_Call) Run(run func(ctx context.Context, cat *microservice.Category)) *MockCategories_Create_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
 			arg0 = args[0].(context.Context)
 		}
-		var arg1 *service.SubscriptionQueryArgs
+		var arg1 *microservice.Category
 		if args[1] != nil {
-			arg1 = args[1].(*service.SubscriptionQueryArgs)
+			arg1 = args[1].(*microservice.Category)
 		}
 		run(
 			arg0,
@@ -287,63 +376,54 @@ func (_c *MockSubscriptions_Query_Call) Run(run func(ctx context.Context, args *
 	return _c
 }
 
-func (_c *MockSubscriptions_Query_Call) Return(subs []*microservice.Subscription, err error) *MockSubscriptions_Query_Call {
-	_c.Call.Return(subs, err)
+func (_c *MockCategories_Create_Call) Return(id microservice.CategoryID, err error) *MockCategories_Create_Call {
+	_c.Call.Return(id, err)
 	return _c
 }
 
-func (_c *MockSubscriptions_Query_Call) RunAndReturn(run func(ctx context.Context, args *service.SubscriptionQueryArgs) ([]*microservice.Subscription, error)) *MockSubscriptions_Query_Call {
+func (_c *MockCategories_Create_Call) RunAndReturn(run func(ctx context.Context, cat *microservice.Category) (microservice.CategoryID, error)) *MockCategories_Create_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// Sum provides a mock function for the type MockSubscriptions
-func (_mock *MockSubscriptions) Sum(ctx context.Context, args *service.SubscriptionQueryArgs) (microservice.Price, error) {
-	ret := _mock.Called(ctx, args)
+// DeleteByID provides a mock function for the type MockCategories
+func (_mock *MockCategories) DeleteByID(ctx context.Context, id microservice.CategoryID) error {
+	ret := _mock.Called(ctx, id)
 
 	if len(ret) == 0 {
-		panic("no return value specified for Sum")
+		panic("no return value specified for DeleteByID")
 	}
 
-	var r0 microservice.Price
-	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *service.SubscriptionQueryArgs) (microservice.Price, error)); ok {
-		return returnFunc(ctx, args)
-	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *service.SubscriptionQueryArgs) microservice.Price); ok {
-		r0 = returnFunc(ctx, args)
-	} else {
-		r0 = ret.Get(0).(microservice.Price)
-	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, *service.SubscriptionQueryArgs) error); ok {
-		r1 = returnFunc(ctx, args)
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, microservice.CategoryID) error); ok {
+		r0 = returnFunc(ctx, id)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
-	return r0, r1
+	return r0
 }
 
-// MockSubscriptions_Sum_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Sum'
-type MockSubscriptions_Sum_Call struct {
+// MockCategories_DeleteByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteByID'
+type MockCategories_DeleteByID_Call struct {
 	*mock.Call
 }
 
-// Sum is a helper method to define mock.On call
+// DeleteByID is a helper method to define mock.On call
 //   - ctx context.Context
-//   - args *service.SubscriptionQueryArgs
-func (_e *MockSubscriptions_Expecter) Sum(ctx interface{}, args interface{}) *MockSubscriptions_Sum_Call {
-	return &MockSubscriptions_Sum_Call{Call: _e.mock.On("Sum", ctx, args)}
+//   - id microservice.CategoryID
+func (_e *MockCategories_Expecter) DeleteByID(ctx interface{}, id interface{}) *MockCategories_DeleteByID_Call {
+	return &MockCategories_DeleteByID_Call{Call: _e.mock.On("DeleteByID", ctx, id)}
 }
 
-func (_c *MockSubscriptions_Sum_Call) Run(run func(ctx context.Context, args *service.SubscriptionQueryArgs)) *MockSubscriptions_Sum_Call {
+func (_c *MockCategories_DeleteByID_Call) Run(run func(ctx context.Context, id microservice.CategoryID)) *MockCategories_DeleteByID_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
 			arg0 = args[0].(context.Context)
 		}
-		var arg1 *service.SubscriptionQueryArgs
+		var arg1 microservice.CategoryID
 		if args[1] != nil {
-			arg1 = args[1].(*service.SubscriptionQueryArgs)
+			arg1 = args[1].(microservice.CategoryID)
 		}
 		run(
 			arg0,
@@ -353,54 +433,65 @@ func (_c *MockSubscriptions_Sum_Call) Run(run func(ctx context.Context, args *se
 	return _c
 }
 
-func (_c *MockSubscriptions_Sum_Call) Return(sum microservice.Price, err error) *MockSubscriptions_Sum_Call {
-	_c.Call.Return(sum, err)
+func (_c *MockCategories_DeleteByID_Call) Return(err error) *MockCategories_DeleteByID_Call {
+	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *MockSubscriptions_Sum_Call) RunAndReturn(run func(ctx context.Context, args *service.SubscriptionQueryArgs) (microservice.Price, error)) *MockSubscriptions_Sum_Call {
+func (_c *MockCategories_DeleteByID_Call) RunAndReturn(run func(ctx context.Context, id microservice.CategoryID) error) *MockCategories_DeleteByID_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// Update provides a mock function for the type MockSubscriptions
-func (_mock *MockSubscriptions) Update(ctx context.Context, sub *microservice.Subscription) error {
-	ret := _mock.Called(ctx, sub)
+// GetByID provides a mock function for the type MockCategories
+func (_mock *MockCategories) GetByID(ctx context.Context, id microservice.CategoryID) (*microservice.Category, error) {
+	ret := _mock.Called(ctx, id)
 
 	if len(ret) == 0 {
-		panic("no return value specified for Update")
+		panic("no return value specified for GetByID")
 	}
 
-	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *microservice.Subscription) error); ok {
-		r0 = returnFunc(ctx, sub)
+	var r0 *microservice.Category
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, microservice.CategoryID) (*microservice.Category, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, microservice.CategoryID) *microservice.Category); ok {
+		r0 = returnFunc(ctx, id)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*microservice.Category)
+		}
 	}
-	return r0
+	if returnFunc, ok := ret.Get(1).(func(context.Context, microservice.CategoryID) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
 }
 
-// MockSubscriptions_Update_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Update'
-type MockSubscriptions_Update_Call struct {
+// MockCategories_GetByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByID'
+type MockCategories_GetByID_Call struct {
 	*mock.Call
 }
 
-// Update is a helper method to define mock.On call
+// GetByID is a helper method to define mock.On call
 //   - ctx context.Context
-//   - sub *microservice.Subscription
-func (_e *MockSubscriptions_Expecter) Update(ctx interface{}, sub interface{}) *MockSubscriptions_Update_Call {
-	return &MockSubscriptions_Update_Call{Call: _e.mock.On("Update", ctx, sub)}
+//   - id microservice.CategoryID
+func (_e *MockCategories_Expecter) GetByID(ctx interface{}, id interface{}) *MockCategories_GetByID_Call {
+	return &MockCategories_GetByID_Call{Call: _e.mock.On("GetByID", ctx, id)}
 }
 
-func (_c *MockSubscriptions_Update_Call) Run(run func(ctx context.Context, sub *microservice.Subscription)) *MockSubscriptions_Update_Call {
+func (_c *MockCategories_GetByID_Call) Run(run func(ctx context.Context, id microservice.CategoryID)) *MockCategories_GetByID_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
 			arg0 = args[0].(context.Context)
 		}
-		var arg1 *microservice.Subscription
+		var arg1 microservice.CategoryID
 		if args[1] != nil {
-			arg1 = args[1].(*microservice.Subscription)
+			arg1 = args[1].(microservice.CategoryID)
 		}
 		run(
 			arg0,
@@ -410,12 +501,1970 @@ func (_c *MockSubscriptions_Update_Call) Run(run func(ctx context.Context, sub *
 	return _c
 }
 
-func (_c *MockSubscriptions_Update_Call) Return(err error) *MockSubscriptions_Update_Call {
-	_c.Call.Return(err)
+func (_c *MockCategories_GetByID_Call) Return(cat *microservice.Category, err error) *MockCategories_GetByID_Call {
+	_c.Call.Return(cat, err)
 	return _c
 }
 
-func (_c *MockSubscriptions_Update_Call) RunAndReturn(run func(ctx context.Context, sub *microservice.Subscription) error) *MockSubscriptions_Update_Call {
+func (_c *MockCategories_GetByID_Call) RunAndReturn(run func(ctx context.Context, id microservice.CategoryID) (*microservice.Category, error)) *MockCategories_GetByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// List provides a mock function for the type MockCategories
+func (_mock *MockCategories) List(ctx context.Context) ([]*microservice.Category, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []*microservice.Category
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]*microservice.Category, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) []*microservice.Category); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*microservice.Category)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCategories_List_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'List'
+type MockCategories_List_Call struct {
+	*mock.Call
+}
+
+// List is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockCategories_Expecter) List(ctx interface{}) *MockCategories_List_Call {
+	return &MockCategories_List_Call{Call: _e.mock.On("List", ctx)}
+}
+
+func (_c *MockCategories_List_Call) Run(run func(ctx context.Context)) *MockCategories_List_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCategories_List_Call) Return(cats []*microservice.Category, err error) *MockCategories_List_Call {
+	_c.Call.Return(cats, err)
+	return _c
+}
+
+func (_c *MockCategories_List_Call) RunAndReturn(run func(ctx context.Context) ([]*microservice.Category, error)) *MockCategories_List_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Update provides a mock function for the type MockCategories
+func (_mock *MockCategories) Update(ctx context.Context, cat *microservice.Category) error {
+	ret := _mock.Called(ctx, cat)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *microservice.Category) error); ok {
+		r0 = returnFunc(ctx, cat)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockCategories_Update_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Update'
+type MockCategories_Update_Call struct {
+	*mock.Call
+}
+
+// Update is a helper method to define mock.On call
+//   - ctx context.Context
+//   - cat *microservice.Category
+func (_e *MockCategories_Expecter) Update(ctx interface{}, cat interface{}) *MockCategories_Update_Call {
+	return &MockCategories_Update_Call{Call: _e.mock.On("Update", ctx, cat)}
+}
+
+func (_c *MockCategories_Update_Call) Run(run func(ctx context.Context, cat *microservice.Category)) *MockCategories_Update_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *microservice.Category
+		if args[1] != nil {
+			arg1 = args[1].(*microservice.Category)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCategories_Update_Call) Return(err error) *MockCategories_Update_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockCategories_Update_Call) RunAndReturn(run func(ctx context.Context, cat *microservice.Category) error) *MockCategories_Update_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockMembers creates a new instance of MockMembers. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockMembers(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockMembers {
+	mock := &MockMembers{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockMembers is an autogenerated mock type for the Members type
+type MockMembers struct {
+	mock.Mock
+}
+
+type MockMembers_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockMembers) EXPECT() *MockMembers_Expecter {
+	return &MockMembers_Expecter{mock: &_m.Mock}
+}
+
+// Add provides a mock function for the type MockMembers
+func (_mock *MockMembers) Add(ctx context.Context, m *microservice.Member) ([]*microservice.Member, error) {
+	ret := _mock.Called(ctx, m)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Add")
+	}
+
+	var r0 []*microservice.Member
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *microservice.Member) ([]*microservice.Member, error)); ok {
+		return returnFunc(ctx, m)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *microservice.Member) []*microservice.Member); ok {
+		r0 = returnFunc(ctx, m)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*microservice.Member)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *microservice.Member) error); ok {
+		r1 = returnFunc(ctx, m)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockMembers_Add_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Add'
+type MockMembers_Add_Call struct {
+	*mock.Call
+}
+
+// Add is a helper method to define mock.On call
+//   - ctx context.Context
+//   - m *microservice.Member
+func (_e *MockMembers_Expecter) Add(ctx interface{}, m interface{}) *MockMembers_Add_Call {
+	return &MockMembers_Add_Call{Call: _e.mock.On("Add", ctx, m)}
+}
+
+func (_c *MockMembers_Add_Call) Run(run func(ctx context.Context, m *microservice.Member)) *MockMembers_Add_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *microservice.Member
+		if args[1] != nil {
+			arg1 = args[1].(*microservice.Member)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockMembers_Add_Call) Return(members []*microservice.Member, err error) *MockMembers_Add_Call {
+	_c.Call.Return(members, err)
+	return _c
+}
+
+func (_c *MockMembers_Add_Call) RunAndReturn(run func(ctx context.Context, m *microservice.Member) ([]*microservice.Member, error)) *MockMembers_Add_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListBySubscription provides a mock function for the type MockMembers
+func (_mock *MockMembers) ListBySubscription(ctx context.Context, id microservice.SubscriptionID) ([]*microservice.Member, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListBySubscription")
+	}
+
+	var r0 []*microservice.Member
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, microservice.SubscriptionID) ([]*microservice.Member, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, microservice.SubscriptionID) []*microservice.Member); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*microservice.Member)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, microservice.SubscriptionID) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockMembers_ListBySubscription_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListBySubscription'
+type MockMembers_ListBySubscription_Call struct {
+	*mock.Call
+}
+
+// ListBySubscription is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id microservice.SubscriptionID
+func (_e *MockMembers_Expecter) ListBySubscription(ctx interface{}, id interface{}) *MockMembers_ListBySubscription_Call {
+	return &MockMembers_ListBySubscription_Call{Call: _e.mock.On("ListBySubscription", ctx, id)}
+}
+
+func (_c *MockMembers_ListBySubscription_Call) Run(run func(ctx context.Context, id microservice.SubscriptionID)) *MockMembers_ListBySubscription_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 microservice.SubscriptionID
+		if args[1] != nil {
+			arg1 = args[1].(microservice.SubscriptionID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockMembers_ListBySubscription_Call) Return(members []*microservice.Member, err error) *MockMembers_ListBySubscription_Call {
+	_c.Call.Return(members, err)
+	return _c
+}
+
+func (_c *MockMembers_ListBySubscription_Call) RunAndReturn(run func(ctx context.Context, id microservice.SubscriptionID) ([]*microservice.Member, error)) *MockMembers_ListBySubscription_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Remove provides a mock function for the type MockMembers
+func (_mock *MockMembers) Remove(ctx context.Context, id microservice.SubscriptionID, userID microservice.UserID) error {
+	ret := _mock.Called(ctx, id, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Remove")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, microservice.SubscriptionID, microservice.UserID) error); ok {
+		r0 = returnFunc(ctx, id, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockMembers_Remove_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Remove'
+type MockMembers_Remove_Call struct {
+	*mock.Call
+}
+
+// Remove is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id microservice.SubscriptionID
+//   - userID microservice.UserID
+func (_e *MockMembers_Expecter) Remove(ctx interface{}, id interface{}, userID interface{}) *MockMembers_Remove_Call {
+	return &MockMembers_Remove_Call{Call: _e.mock.On("Remove", ctx, id, userID)}
+}
+
+func (_c *MockMembers_Remove_Call) Run(run func(ctx context.Context, id microservice.SubscriptionID, userID microservice.UserID)) *MockMembers_Remove_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 microservice.SubscriptionID
+		if args[1] != nil {
+			arg1 = args[1].(microservice.SubscriptionID)
+		}
+		var arg2 microservice.UserID
+		if args[2] != nil {
+			arg2 = args[2].(microservice.UserID)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockMembers_Remove_Call) Return(err error) *MockMembers_Remove_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockMembers_Remove_Call) RunAndReturn(run func(ctx context.Context, id microservice.SubscriptionID, userID microservice.UserID) error) *MockMembers_Remove_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockServices creates a new instance of MockServices. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockServices(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockServices {
+	mock := &MockServices{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockServices is an autogenerated mock type for the Services type
+type MockServices struct {
+	mock.Mock
+}
+
+type MockServices_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockServices) EXPECT() *MockServices_Expecter {
+	return &MockServices_Expecter{mock: &_m.Mock}
+}
+
+// Create provides a mock function for the type MockServices
+func (_mock *MockServices) Create(ctx context.Context, svc *microservice.Service) (microservice.ServiceID, error) {
+	ret := _mock.Called(ctx, svc)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 microservice.ServiceID
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *microservice.Service) (microservice.ServiceID, error)); ok {
+		return returnFunc(ctx, svc)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *microservice.Service) microservice.ServiceID); ok {
+		r0 = returnFunc(ctx, svc)
+	} else {
+		r0 = ret.Get(0).(microservice.ServiceID)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *microservice.Service) error); ok {
+		r1 = returnFunc(ctx, svc)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockServices_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockServices_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - ctx context.Context
+//   - svc *microservice.Service
+func (_e *MockServices_Expecter) Create(ctx interface{}, svc interface{}) *MockServices_Create_Call {
+	return &MockServices_Create_Call{Call: _e.mock.On("Create", ctx, svc)}
+}
+
+func (_c *MockServices_Create_Call) Run(run func(ctx context.Context, svc *microservice.Service)) *MockServices_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *microservice.Service
+		if args[1] != nil {
+			arg1 = args[1].(*microservice.Service)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockServices_Create_Call) Return(id microservice.ServiceID, err error) *MockServices_Create_Call {
+	_c.Call.Return(id, err)
+	return _c
+}
+
+func (_c *MockServices_Create_Call) RunAndReturn(run func(ctx context.Context, svc *microservice.Service) (microservice.ServiceID, error)) *MockServices_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteByID provides a mock function for the type MockServices
+func (_mock *MockServices) DeleteByID(ctx context.Context, id microservice.ServiceID) error {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteByID")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, microservice.ServiceID) error); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockServices_DeleteByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteByID'
+type MockServices_DeleteByID_Call struct {
+	*mock.Call
+}
+
+// DeleteByID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id microservice.ServiceID
+func (_e *MockServices_Expecter) DeleteByID(ctx interface{}, id interface{}) *MockServices_DeleteByID_Call {
+	return &MockServices_DeleteByID_Call{Call: _e.mock.On("DeleteByID", ctx, id)}
+}
+
+func (_c *MockServices_DeleteByID_Call) Run(run func(ctx context.Context, id microservice.ServiceID)) *MockServices_DeleteByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 microservice.ServiceID
+		if args[1] != nil {
+			arg1 = args[1].(microservice.ServiceID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockServices_DeleteByID_Call) Return(err error) *MockServices_DeleteByID_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockServices_DeleteByID_Call) RunAndReturn(run func(ctx context.Context, id microservice.ServiceID) error) *MockServices_DeleteByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByID provides a mock function for the type MockServices
+func (_mock *MockServices) GetByID(ctx context.Context, id microservice.ServiceID) (*microservice.Service, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByID")
+	}
+
+	var r0 *microservice.Service
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, microservice.ServiceID) (*microservice.Service, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, microservice.ServiceID) *microservice.Service); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*microservice.Service)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, microservice.ServiceID) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockServices_GetByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByID'
+type MockServices_GetByID_Call struct {
+	*mock.Call
+}
+
+// GetByID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id microservice.ServiceID
+func (_e *MockServices_Expecter) GetByID(ctx interface{}, id interface{}) *MockServices_GetByID_Call {
+	return &MockServices_GetByID_Call{Call: _e.mock.On("GetByID", ctx, id)}
+}
+
+func (_c *MockServices_GetByID_Call) Run(run func(ctx context.Context, id microservice.ServiceID)) *MockServices_GetByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 microservice.ServiceID
+		if args[1] != nil {
+			arg1 = args[1].(microservice.ServiceID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockServices_GetByID_Call) Return(svc *microservice.Service, err error) *MockServices_GetByID_Call {
+	_c.Call.Return(svc, err)
+	return _c
+}
+
+func (_c *MockServices_GetByID_Call) RunAndReturn(run func(ctx context.Context, id microservice.ServiceID) (*microservice.Service, error)) *MockServices_GetByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByName provides a mock function for the type MockServices
+func (_mock *MockServices) GetByName(ctx context.Context, name string) (*microservice.Service, error) {
+	ret := _mock.Called(ctx, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByName")
+	}
+
+	var r0 *microservice.Service
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*microservice.Service, error)); ok {
+		return returnFunc(ctx, name)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *microservice.Service); ok {
+		r0 = returnFunc(ctx, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*microservice.Service)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockServices_GetByName_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByName'
+type MockServices_GetByName_Call struct {
+	*mock.Call
+}
+
+// GetByName is a helper method to define mock.On call
+//   - ctx context.Context
+//   - name string
+func (_e *MockServices_Expecter) GetByName(ctx interface{}, name interface{}) *MockServices_GetByName_Call {
+	return &MockServices_GetByName_Call{Call: _e.mock.On("GetByName", ctx, name)}
+}
+
+func (_c *MockServices_GetByName_Call) Run(run func(ctx context.Context, name string)) *MockServices_GetByName_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockServices_GetByName_Call) Return(svc *microservice.Service, err error) *MockServices_GetByName_Call {
+	_c.Call.Return(svc, err)
+	return _c
+}
+
+func (_c *MockServices_GetByName_Call) RunAndReturn(run func(ctx context.Context, name string) (*microservice.Service, error)) *MockServices_GetByName_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// List provides a mock function for the type MockServices
+func (_mock *MockServices) List(ctx context.Context) ([]*microservice.Service, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []*microservice.Service
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]*microservice.Service, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) []*microservice.Service); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*microservice.Service)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockServices_List_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'List'
+type MockServices_List_Call struct {
+	*mock.Call
+}
+
+// List is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockServices_Expecter) List(ctx interface{}) *MockServices_List_Call {
+	return &MockServices_List_Call{Call: _e.mock.On("List", ctx)}
+}
+
+func (_c *MockServices_List_Call) Run(run func(ctx context.Context)) *MockServices_List_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockServices_List_Call) Return(svcs []*microservice.Service, err error) *MockServices_List_Call {
+	_c.Call.Return(svcs, err)
+	return _c
+}
+
+func (_c *MockServices_List_Call) RunAndReturn(run func(ctx context.Context) ([]*microservice.Service, error)) *MockServices_List_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Update provides a mock function for the type MockServices
+func (_mock *MockServices) Update(ctx context.Context, svc *microservice.Service) error {
+	ret := _mock.Called(ctx, svc)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *microservice.Service) error); ok {
+		r0 = returnFunc(ctx, svc)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockServices_Update_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Update'
+type MockServices_Update_Call struct {
+	*mock.Call
+}
+
+// Update is a helper method to define mock.On call
+//   - ctx context.Context
+//   - svc *microservice.Service
+func (_e *MockServices_Expecter) Update(ctx interface{}, svc interface{}) *MockServices_Update_Call {
+	return &MockServices_Update_Call{Call: _e.mock.On("Update", ctx, svc)}
+}
+
+func (_c *MockServices_Update_Call) Run(run func(ctx context.Context, svc *microservice.Service)) *MockServices_Update_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *microservice.Service
+		if args[1] != nil {
+			arg1 = args[1].(*microservice.Service)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockServices_Update_Call) Return(err error) *MockServices_Update_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockServices_Update_Call) RunAndReturn(run func(ctx context.Context, svc *microservice.Service) error) *MockServices_Update_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockSubscriptions creates a new instance of MockSubscriptions. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockSubscriptions(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockSubscriptions {
+	mock := &MockSubscriptions{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockSubscriptions is an autogenerated mock type for the Subscriptions type
+type MockSubscriptions struct {
+	mock.Mock
+}
+
+type MockSubscriptions_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockSubscriptions) EXPECT() *MockSubscriptions_Expecter {
+	return &MockSubscriptions_Expecter{mock: &_m.Mock}
+}
+
+// Create provides a mock function for the type MockSubscriptions
+func (_mock *MockSubscriptions) Create(ctx context.Context, sub *microservice.Subscription) (microservice.SubscriptionID, error) {
+	ret := _mock.Called(ctx, sub)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 microservice.SubscriptionID
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *microservice.Subscription) (microservice.SubscriptionID, error)); ok {
+		return returnFunc(ctx, sub)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *microservice.Subscription) microservice.SubscriptionID); ok {
+		r0 = returnFunc(ctx, sub)
+	} else {
+		r0 = ret.Get(0).(microservice.SubscriptionID)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *microservice.Subscription) error); ok {
+		r1 = returnFunc(ctx, sub)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockSubscriptions_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockSubscriptions_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - ctx context.Context
+//   - sub *microservice.Subscription
+func (_e *MockSubscriptions_Expecter) Create(ctx interface{}, sub interface{}) *MockSubscriptions_Create_Call {
+	return &MockSubscriptions_Create_Call{Call: _e.mock.On("Create", ctx, sub)}
+}
+
+func (_c *MockSubscriptions_Create_Call) Run(run func(ctx context.Context, sub *microservice.Subscription)) *MockSubscriptions_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *microservice.Subscription
+		if args[1] != nil {
+			arg1 = args[1].(*microservice.Subscription)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockSubscriptions_Create_Call) Return(id microservice.SubscriptionID, err error) *MockSubscriptions_Create_Call {
+	_c.Call.Return(id, err)
+	return _c
+}
+
+func (_c *MockSubscriptions_Create_Call) RunAndReturn(run func(ctx context.Context, sub *microservice.Subscription) (microservice.SubscriptionID, error)) *MockSubscriptions_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateBulk provides a mock function for the type MockSubscriptions
+func (_mock *MockSubscriptions) CreateBulk(ctx context.Context, subs []*microservice.Subscription, allOrNothing bool) (*service.BulkResults, error) {
+	ret := _mock.Called(ctx, subs, allOrNothing)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateBulk")
+	}
+
+	var r0 *service.BulkResults
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []*microservice.Subscription, bool) (*service.BulkResults, error)); ok {
+		return returnFunc(ctx, subs, allOrNothing)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []*microservice.Subscription, bool) *service.BulkResults); ok {
+		r0 = returnFunc(ctx, subs, allOrNothing)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*service.BulkResults)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, []*microservice.Subscription, bool) error); ok {
+		r1 = returnFunc(ctx, subs, allOrNothing)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockSubscriptions_CreateBulk_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateBulk'
+type MockSubscriptions_CreateBulk_Call struct {
+	*mock.Call
+}
+
+// CreateBulk is a helper method to define mock.On call
+//   - ctx context.Context
+//   - subs []*microservice.Subscription
+//   - allOrNothing bool
+func (_e *MockSubscriptions_Expecter) CreateBulk(ctx interface{}, subs interface{}, allOrNothing interface{}) *MockSubscriptions_CreateBulk_Call {
+	return &MockSubscriptions_CreateBulk_Call{Call: _e.mock.On("CreateBulk", ctx, subs, allOrNothing)}
+}
+
+func (_c *MockSubscriptions_CreateBulk_Call) Run(run func(ctx context.Context, subs []*microservice.Subscription, allOrNothing bool)) *MockSubscriptions_CreateBulk_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 []*microservice.Subscription
+		if args[1] != nil {
+			arg1 = args[1].([]*microservice.Subscription)
+		}
+		var arg2 bool
+		if args[2] != nil {
+			arg2 = args[2].(bool)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockSubscriptions_CreateBulk_Call) Return(res *service.BulkResults, err error) *MockSubscriptions_CreateBulk_Call {
+	_c.Call.Return(res, err)
+	return _c
+}
+
+func (_c *MockSubscriptions_CreateBulk_Call) RunAndReturn(run func(ctx context.Context, subs []*microservice.Subscription, allOrNothing bool) (*service.BulkResults, error)) *MockSubscriptions_CreateBulk_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteBulk provides a mock function for the type MockSubscriptions
+func (_mock *MockSubscriptions) DeleteBulk(ctx context.Context, ids []microservice.SubscriptionID, allOrNothing bool) (*service.BulkResults, error) {
+	ret := _mock.Called(ctx, ids, allOrNothing)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteBulk")
+	}
+
+	var r0 *service.BulkResults
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []microservice.SubscriptionID, bool) (*service.BulkResults, error)); ok {
+		return returnFunc(ctx, ids, allOrNothing)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []microservice.SubscriptionID, bool) *service.BulkResults); ok {
+		r0 = returnFunc(ctx, ids, allOrNothing)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*service.BulkResults)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, []microservice.SubscriptionID, bool) error); ok {
+		r1 = returnFunc(ctx, ids, allOrNothing)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockSubscriptions_DeleteBulk_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteBulk'
+type MockSubscriptions_DeleteBulk_Call struct {
+	*mock.Call
+}
+
+// DeleteBulk is a helper method to define mock.On call
+//   - ctx context.Context
+//   - ids []microservice.SubscriptionID
+//   - allOrNothing bool
+func (_e *MockSubscriptions_Expecter) DeleteBulk(ctx interface{}, ids interface{}, allOrNothing interface{}) *MockSubscriptions_DeleteBulk_Call {
+	return &MockSubscriptions_DeleteBulk_Call{Call: _e.mock.On("DeleteBulk", ctx, ids, allOrNothing)}
+}
+
+func (_c *MockSubscriptions_DeleteBulk_Call) Run(run func(ctx context.Context, ids []microservice.SubscriptionID, allOrNothing bool)) *MockSubscriptions_DeleteBulk_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 []microservice.SubscriptionID
+		if args[1] != nil {
+			arg1 = args[1].([]microservice.SubscriptionID)
+		}
+		var arg2 bool
+		if args[2] != nil {
+			arg2 = args[2].(bool)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockSubscriptions_DeleteBulk_Call) Return(res *service.BulkResults, err error) *MockSubscriptions_DeleteBulk_Call {
+	_c.Call.Return(res, err)
+	return _c
+}
+
+func (_c *MockSubscriptions_DeleteBulk_Call) RunAndReturn(run func(ctx context.Context, ids []microservice.SubscriptionID, allOrNothing bool) (*service.BulkResults, error)) *MockSubscriptions_DeleteBulk_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteByID provides a mock function for the type MockSubscriptions
+func (_mock *MockSubscriptions) DeleteByID(ctx context.Context, id microservice.SubscriptionID) error {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteByID")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, microservice.SubscriptionID) error); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockSubscriptions_DeleteByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteByID'
+type MockSubscriptions_DeleteByID_Call struct {
+	*mock.Call
+}
+
+// DeleteByID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id microservice.SubscriptionID
+func (_e *MockSubscriptions_Expecter) DeleteByID(ctx interface{}, id interface{}) *MockSubscriptions_DeleteByID_Call {
+	return &MockSubscriptions_DeleteByID_Call{Call: _e.mock.On("DeleteByID", ctx, id)}
+}
+
+func (_c *MockSubscriptions_DeleteByID_Call) Run(run func(ctx context.Context, id microservice.SubscriptionID)) *MockSubscriptions_DeleteByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 microservice.SubscriptionID
+		if args[1] != nil {
+			arg1 = args[1].(microservice.SubscriptionID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockSubscriptions_DeleteByID_Call) Return(err error) *MockSubscriptions_DeleteByID_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockSubscriptions_DeleteByID_Call) RunAndReturn(run func(ctx context.Context, id microservice.SubscriptionID) error) *MockSubscriptions_DeleteByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByID provides a mock function for the type MockSubscriptions
+func (_mock *MockSubscriptions) GetByID(ctx context.Context, id microservice.SubscriptionID) (*microservice.Subscription, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByID")
+	}
+
+	var r0 *microservice.Subscription
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, microservice.SubscriptionID) (*microservice.Subscription, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, microservice.SubscriptionID) *microservice.Subscription); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*microservice.Subscription)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, microservice.SubscriptionID) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockSubscriptions_GetByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByID'
+type MockSubscriptions_GetByID_Call struct {
+	*mock.Call
+}
+
+// GetByID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id microservice.SubscriptionID
+func (_e *MockSubscriptions_Expecter) GetByID(ctx interface{}, id interface{}) *MockSubscriptions_GetByID_Call {
+	return &MockSubscriptions_GetByID_Call{Call: _e.mock.On("GetByID", ctx, id)}
+}
+
+func (_c *MockSubscriptions_GetByID_Call) Run(run func(ctx context.Context, id microservice.SubscriptionID)) *MockSubscriptions_GetByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 microservice.SubscriptionID
+		if args[1] != nil {
+			arg1 = args[1].(microservice.SubscriptionID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockSubscriptions_GetByID_Call) Return(sub *microservice.Subscription, err error) *MockSubscriptions_GetByID_Call {
+	_c.Call.Return(sub, err)
+	return _c
+}
+
+func (_c *MockSubscriptions_GetByID_Call) RunAndReturn(run func(ctx context.Context, id microservice.SubscriptionID) (*microservice.Subscription, error)) *MockSubscriptions_GetByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Import provides a mock function for the type MockSubscriptions
+func (_mock *MockSubscriptions) Import(ctx context.Context, r io.Reader, opts *service.ImportOptions) (*service.ImportReport, error) {
+	ret := _mock.Called(ctx, r, opts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Import")
+	}
+
+	var r0 *service.ImportReport
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, io.Reader, *service.ImportOptions) (*service.ImportReport, error)); ok {
+		return returnFunc(ctx, r, opts)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, io.Reader, *service.ImportOptions) *service.ImportReport); ok {
+		r0 = returnFunc(ctx, r, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*service.ImportReport)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, io.Reader, *service.ImportOptions) error); ok {
+		r1 = returnFunc(ctx, r, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockSubscriptions_Import_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Import'
+type MockSubscriptions_Import_Call struct {
+	*mock.Call
+}
+
+// Import is a helper method to define mock.On call
+//   - ctx context.Context
+//   - r io.Reader
+//   - opts *service.ImportOptions
+func (_e *MockSubscriptions_Expecter) Import(ctx interface{}, r interface{}, opts interface{}) *MockSubscriptions_Import_Call {
+	return &MockSubscriptions_Import_Call{Call: _e.mock.On("Import", ctx, r, opts)}
+}
+
+func (_c *MockSubscriptions_Import_Call) Run(run func(ctx context.Context, r io.Reader, opts *service.ImportOptions)) *MockSubscriptions_Import_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 io.Reader
+		if args[1] != nil {
+			arg1 = args[1].(io.Reader)
+		}
+		var arg2 *service.ImportOptions
+		if args[2] != nil {
+			arg2 = args[2].(*service.ImportOptions)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockSubscriptions_Import_Call) Return(report *service.ImportReport, err error) *MockSubscriptions_Import_Call {
+	_c.Call.Return(report, err)
+	return _c
+}
+
+func (_c *MockSubscriptions_Import_Call) RunAndReturn(run func(ctx context.Context, r io.Reader, opts *service.ImportOptions) (*service.ImportReport, error)) *MockSubscriptions_Import_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Query provides a mock function for the type MockSubscriptions
+func (_mock *MockSubscriptions) Query(ctx context.Context, args *service.SubscriptionQueryArgs) ([]*microservice.Subscription, error) {
+	ret := _mock.Called(ctx, args)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Query")
+	}
+
+	var r0 []*microservice.Subscription
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *service.SubscriptionQueryArgs) ([]*microservice.Subscription, error)); ok {
+		return returnFunc(ctx, args)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *service.SubscriptionQueryArgs) []*microservice.Subscription); ok {
+		r0 = returnFunc(ctx, args)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*microservice.Subscription)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *service.SubscriptionQueryArgs) error); ok {
+		r1 = returnFunc(ctx, args)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockSubscriptions_Query_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Query'
+type MockSubscriptions_Query_Call struct {
+	*mock.Call
+}
+
+// Query is a helper method to define mock.On call
+//   - ctx context.Context
+//   - args *service.SubscriptionQueryArgs
+func (_e *MockSubscriptions_Expecter) Query(ctx interface{}, args interface{}) *MockSubscriptions_Query_Call {
+	return &MockSubscriptions_Query_Call{Call: _e.mock.On("Query", ctx, args)}
+}
+
+func (_c *MockSubscriptions_Query_Call) Run(run func(ctx context.Context, args *service.SubscriptionQueryArgs)) *MockSubscriptions_Query_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *service.SubscriptionQueryArgs
+		if args[1] != nil {
+			arg1 = args[1].(*service.SubscriptionQueryArgs)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockSubscriptions_Query_Call) Return(subs []*microservice.Subscription, err error) *MockSubscriptions_Query_Call {
+	_c.Call.Return(subs, err)
+	return _c
+}
+
+func (_c *MockSubscriptions_Query_Call) RunAndReturn(run func(ctx context.Context, args *service.SubscriptionQueryArgs) ([]*microservice.Subscription, error)) *MockSubscriptions_Query_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// QueryEach provides a mock function for the type MockSubscriptions
+func (_mock *MockSubscriptions) QueryEach(ctx context.Context, args *service.SubscriptionQueryArgs, fn func(sub *microservice.Subscription) error) error {
+	ret := _mock.Called(ctx, args, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for QueryEach")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *service.SubscriptionQueryArgs, func(sub *microservice.Subscription) error) error); ok {
+		r0 = returnFunc(ctx, args, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockSubscriptions_QueryEach_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'QueryEach'
+type MockSubscriptions_QueryEach_Call struct {
+	*mock.Call
+}
+
+// QueryEach is a helper method to define mock.On call
+//   - ctx context.Context
+//   - args *service.SubscriptionQueryArgs
+//   - fn func(sub *microservice.Subscription) error
+func (_e *MockSubscriptions_Expecter) QueryEach(ctx interface{}, args interface{}, fn interface{}) *MockSubscriptions_QueryEach_Call {
+	return &MockSubscriptions_QueryEach_Call{Call: _e.mock.On("QueryEach", ctx, args, fn)}
+}
+
+func (_c *MockSubscriptions_QueryEach_Call) Run(run func(ctx context.Context, args *service.SubscriptionQueryArgs, fn func(sub *microservice.Subscription) error)) *MockSubscriptions_QueryEach_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *service.SubscriptionQueryArgs
+		if args[1] != nil {
+			arg1 = args[1].(*service.SubscriptionQueryArgs)
+		}
+		var arg2 func(sub *microservice.Subscription) error
+		if args[2] != nil {
+			arg2 = args[2].(func(sub *microservice.Subscription) error)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockSubscriptions_QueryEach_Call) Return(err error) *MockSubscriptions_QueryEach_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockSubscriptions_QueryEach_Call) RunAndReturn(run func(ctx context.Context, args *service.SubscriptionQueryArgs, fn func(sub *microservice.Subscription) error) error) *MockSubscriptions_QueryEach_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Sum provides a mock function for the type MockSubscriptions
+func (_mock *MockSubscriptions) Sum(ctx context.Context, args *service.SubscriptionQueryArgs) (microservice.Price, error) {
+	ret := _mock.Called(ctx, args)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Sum")
+	}
+
+	var r0 microservice.Price
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *service.SubscriptionQueryArgs) (microservice.Price, error)); ok {
+		return returnFunc(ctx, args)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *service.SubscriptionQueryArgs) microservice.Price); ok {
+		r0 = returnFunc(ctx, args)
+	} else {
+		r0 = ret.Get(0).(microservice.Price)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *service.SubscriptionQueryArgs) error); ok {
+		r1 = returnFunc(ctx, args)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockSubscriptions_Sum_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Sum'
+type MockSubscriptions_Sum_Call struct {
+	*mock.Call
+}
+
+// Sum is a helper method to define mock.On call
+//   - ctx context.Context
+//   - args *service.SubscriptionQueryArgs
+func (_e *MockSubscriptions_Expecter) Sum(ctx interface{}, args interface{}) *MockSubscriptions_Sum_Call {
+	return &MockSubscriptions_Sum_Call{Call: _e.mock.On("Sum", ctx, args)}
+}
+
+func (_c *MockSubscriptions_Sum_Call) Run(run func(ctx context.Context, args *service.SubscriptionQueryArgs)) *MockSubscriptions_Sum_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *service.SubscriptionQueryArgs
+		if args[1] != nil {
+			arg1 = args[1].(*service.SubscriptionQueryArgs)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockSubscriptions_Sum_Call) Return(sum microservice.Price, err error) *MockSubscriptions_Sum_Call {
+	_c.Call.Return(sum, err)
+	return _c
+}
+
+func (_c *MockSubscriptions_Sum_Call) RunAndReturn(run func(ctx context.Context, args *service.SubscriptionQueryArgs) (microservice.Price, error)) *MockSubscriptions_Sum_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SumByCategory provides a mock function for the type MockSubscriptions
+func (_mock *MockSubscriptions) SumByCategory(ctx context.Context, args *service.SubscriptionQueryArgs) ([]*microservice.CategorySum, error) {
+	ret := _mock.Called(ctx, args)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SumByCategory")
+	}
+
+	var r0 []*microservice.CategorySum
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *service.SubscriptionQueryArgs) ([]*microservice.CategorySum, error)); ok {
+		return returnFunc(ctx, args)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *service.SubscriptionQueryArgs) []*microservice.CategorySum); ok {
+		r0 = returnFunc(ctx, args)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*microservice.CategorySum)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *service.SubscriptionQueryArgs) error); ok {
+		r1 = returnFunc(ctx, args)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockSubscriptions_SumByCategory_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SumByCategory'
+type MockSubscriptions_SumByCategory_Call struct {
+	*mock.Call
+}
+
+// SumByCategory is a helper method to define mock.On call
+//   - ctx context.Context
+//   - args *service.SubscriptionQueryArgs
+func (_e *MockSubscriptions_Expecter) SumByCategory(ctx interface{}, args interface{}) *MockSubscriptions_SumByCategory_Call {
+	return &MockSubscriptions_SumByCategory_Call{Call: _e.mock.On("SumByCategory", ctx, args)}
+}
+
+func (_c *MockSubscriptions_SumByCategory_Call) Run(run func(ctx context.Context, args *service.SubscriptionQueryArgs)) *MockSubscriptions_SumByCategory_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *service.SubscriptionQueryArgs
+		if args[1] != nil {
+			arg1 = args[1].(*service.SubscriptionQueryArgs)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockSubscriptions_SumByCategory_Call) Return(sums []*microservice.CategorySum, err error) *MockSubscriptions_SumByCategory_Call {
+	_c.Call.Return(sums, err)
+	return _c
+}
+
+func (_c *MockSubscriptions_SumByCategory_Call) RunAndReturn(run func(ctx context.Context, args *service.SubscriptionQueryArgs) ([]*microservice.CategorySum, error)) *MockSubscriptions_SumByCategory_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Update provides a mock function for the type MockSubscriptions
+func (_mock *MockSubscriptions) Update(ctx context.Context, sub *microservice.Subscription) error {
+	ret := _mock.Called(ctx, sub)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *microservice.Subscription) error); ok {
+		r0 = returnFunc(ctx, sub)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockSubscriptions_Update_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Update'
+type MockSubscriptions_Update_Call struct {
+	*mock.Call
+}
+
+// Update is a helper method to define mock.On call
+//   - ctx context.Context
+//   - sub *microservice.Subscription
+func (_e *MockSubscriptions_Expecter) Update(ctx interface{}, sub interface{}) *MockSubscriptions_Update_Call {
+	return &MockSubscriptions_Update_Call{Call: _e.mock.On("Update", ctx, sub)}
+}
+
+func (_c *MockSubscriptions_Update_Call) Run(run func(ctx context.Context, sub *microservice.Subscription)) *MockSubscriptions_Update_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *microservice.Subscription
+		if args[1] != nil {
+			arg1 = args[1].(*microservice.Subscription)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockSubscriptions_Update_Call) Return(err error) *MockSubscriptions_Update_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockSubscriptions_Update_Call) RunAndReturn(run func(ctx context.Context, sub *microservice.Subscription) error) *MockSubscriptions_Update_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateBulk provides a mock function for the type MockSubscriptions
+func (_mock *MockSubscriptions) UpdateBulk(ctx context.Context, subs []*microservice.Subscription, allOrNothing bool) (*service.BulkResults, error) {
+	ret := _mock.Called(ctx, subs, allOrNothing)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateBulk")
+	}
+
+	var r0 *service.BulkResults
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []*microservice.Subscription, bool) (*service.BulkResults, error)); ok {
+		return returnFunc(ctx, subs, allOrNothing)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []*microservice.Subscription, bool) *service.BulkResults); ok {
+		r0 = returnFunc(ctx, subs, allOrNothing)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*service.BulkResults)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, []*microservice.Subscription, bool) error); ok {
+		r1 = returnFunc(ctx, subs, allOrNothing)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockSubscriptions_UpdateBulk_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateBulk'
+type MockSubscriptions_UpdateBulk_Call struct {
+	*mock.Call
+}
+
+// UpdateBulk is a helper method to define mock.On call
+//   - ctx context.Context
+//   - subs []*microservice.Subscription
+//   - allOrNothing bool
+func (_e *MockSubscriptions_Expecter) UpdateBulk(ctx interface{}, subs interface{}, allOrNothing interface{}) *MockSubscriptions_UpdateBulk_Call {
+	return &MockSubscriptions_UpdateBulk_Call{Call: _e.mock.On("UpdateBulk", ctx, subs, allOrNothing)}
+}
+
+func (_c *MockSubscriptions_UpdateBulk_Call) Run(run func(ctx context.Context, subs []*microservice.Subscription, allOrNothing bool)) *MockSubscriptions_UpdateBulk_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 []*microservice.Subscription
+		if args[1] != nil {
+			arg1 = args[1].([]*microservice.Subscription)
+		}
+		var arg2 bool
+		if args[2] != nil {
+			arg2 = args[2].(bool)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockSubscriptions_UpdateBulk_Call) Return(res *service.BulkResults, err error) *MockSubscriptions_UpdateBulk_Call {
+	_c.Call.Return(res, err)
+	return _c
+}
+
+func (_c *MockSubscriptions_UpdateBulk_Call) RunAndReturn(run func(ctx context.Context, subs []*microservice.Subscription, allOrNothing bool) (*service.BulkResults, error)) *MockSubscriptions_UpdateBulk_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Upsert provides a mock function for the type MockSubscriptions
+func (_mock *MockSubscriptions) Upsert(ctx context.Context, sub *microservice.Subscription) (microservice.SubscriptionID, bool, error) {
+	ret := _mock.Called(ctx, sub)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Upsert")
+	}
+
+	var r0 microservice.SubscriptionID
+	var r1 bool
+	var r2 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *microservice.Subscription) (microservice.SubscriptionID, bool, error)); ok {
+		return returnFunc(ctx, sub)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *microservice.Subscription) microservice.SubscriptionID); ok {
+		r0 = returnFunc(ctx, sub)
+	} else {
+		r0 = ret.Get(0).(microservice.SubscriptionID)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *microservice.Subscription) bool); ok {
+		r1 = returnFunc(ctx, sub)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+	if returnFunc, ok := ret.Get(2).(func(context.Context, *microservice.Subscription) error); ok {
+		r2 = returnFunc(ctx, sub)
+	} else {
+		r2 = ret.Error(2)
+	}
+	return r0, r1, r2
+}
+
+// MockSubscriptions_Upsert_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Upsert'
+type MockSubscriptions_Upsert_Call struct {
+	*mock.Call
+}
+
+// Upsert is a helper method to define mock.On call
+//   - ctx context.Context
+//   - sub *microservice.Subscription
+func (_e *MockSubscriptions_Expecter) Upsert(ctx interface{}, sub interface{}) *MockSubscriptions_Upsert_Call {
+	return &MockSubscriptions_Upsert_Call{Call: _e.mock.On("Upsert", ctx, sub)}
+}
+
+func (_c *MockSubscriptions_Upsert_Call) Run(run func(ctx context.Context, sub *microservice.Subscription)) *MockSubscriptions_Upsert_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *microservice.Subscription
+		if args[1] != nil {
+			arg1 = args[1].(*microservice.Subscription)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockSubscriptions_Upsert_Call) Return(id microservice.SubscriptionID, created bool, err error) *MockSubscriptions_Upsert_Call {
+	_c.Call.Return(id, created, err)
+	return _c
+}
+
+func (_c *MockSubscriptions_Upsert_Call) RunAndReturn(run func(ctx context.Context, sub *microservice.Subscription) (microservice.SubscriptionID, bool, error)) *MockSubscriptions_Upsert_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockTags creates a new instance of MockTags. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockTags(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockTags {
+	mock := &MockTags{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockTags is an autogenerated mock type for the Tags type
+type MockTags struct {
+	mock.Mock
+}
+
+type MockTags_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockTags) EXPECT() *MockTags_Expecter {
+	return &MockTags_Expecter{mock: &_m.Mock}
+}
+
+// AddToSubscription provides a mock function for the type MockTags
+func (_mock *MockTags) AddToSubscription(ctx context.Context, id microservice.SubscriptionID, names []string) ([]*microservice.Tag, error) {
+	ret := _mock.Called(ctx, id, names)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddToSubscription")
+	}
+
+	var r0 []*microservice.Tag
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, microservice.SubscriptionID, []string) ([]*microservice.Tag, error)); ok {
+		return returnFunc(ctx, id, names)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, microservice.SubscriptionID, []string) []*microservice.Tag); ok {
+		r0 = returnFunc(ctx, id, names)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*microservice.Tag)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, microservice.SubscriptionID, []string) error); ok {
+		r1 = returnFunc(ctx, id, names)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockTags_AddToSubscription_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddToSubscription'
+type MockTags_AddToSubscription_Call struct {
+	*mock.Call
+}
+
+// AddToSubscription is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id microservice.SubscriptionID
+//   - names []string
+func (_e *MockTags_Expecter) AddToSubscription(ctx interface{}, id interface{}, names interface{}) *MockTags_AddToSubscription_Call {
+	return &MockTags_AddToSubscription_Call{Call: _e.mock.On("AddToSubscription", ctx, id, names)}
+}
+
+func (_c *MockTags_AddToSubscription_Call) Run(run func(ctx context.Context, id microservice.SubscriptionID, names []string)) *MockTags_AddToSubscription_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 microservice.SubscriptionID
+		if args[1] != nil {
+			arg1 = args[1].(microservice.SubscriptionID)
+		}
+		var arg2 []string
+		if args[2] != nil {
+			arg2 = args[2].([]string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockTags_AddToSubscription_Call) Return(tags []*microservice.Tag, err error) *MockTags_AddToSubscription_Call {
+	_c.Call.Return(tags, err)
+	return _c
+}
+
+func (_c *MockTags_AddToSubscription_Call) RunAndReturn(run func(ctx context.Context, id microservice.SubscriptionID, names []string) ([]*microservice.Tag, error)) *MockTags_AddToSubscription_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListBySubscription provides a mock function for the type MockTags
+func (_mock *MockTags) ListBySubscription(ctx context.Context, id microservice.SubscriptionID) ([]*microservice.Tag, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListBySubscription")
+	}
+
+	var r0 []*microservice.Tag
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, microservice.SubscriptionID) ([]*microservice.Tag, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, microservice.SubscriptionID) []*microservice.Tag); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*microservice.Tag)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, microservice.SubscriptionID) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockTags_ListBySubscription_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListBySubscription'
+type MockTags_ListBySubscription_Call struct {
+	*mock.Call
+}
+
+// ListBySubscription is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id microservice.SubscriptionID
+func (_e *MockTags_Expecter) ListBySubscription(ctx interface{}, id interface{}) *MockTags_ListBySubscription_Call {
+	return &MockTags_ListBySubscription_Call{Call: _e.mock.On("ListBySubscription", ctx, id)}
+}
+
+func (_c *MockTags_ListBySubscription_Call) Run(run func(ctx context.Context, id microservice.SubscriptionID)) *MockTags_ListBySubscription_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 microservice.SubscriptionID
+		if args[1] != nil {
+			arg1 = args[1].(microservice.SubscriptionID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockTags_ListBySubscription_Call) Return(tags []*microservice.Tag, err error) *MockTags_ListBySubscription_Call {
+	_c.Call.Return(tags, err)
+	return _c
+}
+
+func (_c *MockTags_ListBySubscription_Call) RunAndReturn(run func(ctx context.Context, id microservice.SubscriptionID) ([]*microservice.Tag, error)) *MockTags_ListBySubscription_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListByUser provides a mock function for the type MockTags
+func (_mock *MockTags) ListByUser(ctx context.Context, userID microservice.UserID) ([]*microservice.Tag, error) {
+	ret := _mock.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListByUser")
+	}
+
+	var r0 []*microservice.Tag
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, microservice.UserID) ([]*microservice.Tag, error)); ok {
+		return returnFunc(ctx, userID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, microservice.UserID) []*microservice.Tag); ok {
+		r0 = returnFunc(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*microservice.Tag)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, microservice.UserID) error); ok {
+		r1 = returnFunc(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockTags_ListByUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListByUser'
+type MockTags_ListByUser_Call struct {
+	*mock.Call
+}
+
+// ListByUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID microservice.UserID
+func (_e *MockTags_Expecter) ListByUser(ctx interface{}, userID interface{}) *MockTags_ListByUser_Call {
+	return &MockTags_ListByUser_Call{Call: _e.mock.On("ListByUser", ctx, userID)}
+}
+
+func (_c *MockTags_ListByUser_Call) Run(run func(ctx context.Context, userID microservice.UserID)) *MockTags_ListByUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 microservice.UserID
+		if args[1] != nil {
+			arg1 = args[1].(microservice.UserID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockTags_ListByUser_Call) Return(tags []*microservice.Tag, err error) *MockTags_ListByUser_Call {
+	_c.Call.Return(tags, err)
+	return _c
+}
+
+func (_c *MockTags_ListByUser_Call) RunAndReturn(run func(ctx context.Context, userID microservice.UserID) ([]*microservice.Tag, error)) *MockTags_ListByUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RemoveFromSubscription provides a mock function for the type MockTags
+func (_mock *MockTags) RemoveFromSubscription(ctx context.Context, id microservice.SubscriptionID, name string) error {
+	ret := _mock.Called(ctx, id, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveFromSubscription")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, microservice.SubscriptionID, string) error); ok {
+		r0 = returnFunc(ctx, id, name)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockTags_RemoveFromSubscription_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RemoveFromSubscription'
+type MockTags_RemoveFromSubscription_Call struct {
+	*mock.Call
+}
+
+// RemoveFromSubscription is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id microservice.SubscriptionID
+//   - name string
+func (_e *MockTags_Expecter) RemoveFromSubscription(ctx interface{}, id interface{}, name interface{}) *MockTags_RemoveFromSubscription_Call {
+	return &MockTags_RemoveFromSubscription_Call{Call: _e.mock.On("RemoveFromSubscription", ctx, id, name)}
+}
+
+func (_c *MockTags_RemoveFromSubscription_Call) Run(run func(ctx context.Context, id microservice.SubscriptionID, name string)) *MockTags_RemoveFromSubscription_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 microservice.SubscriptionID
+		if args[1] != nil {
+			arg1 = args[1].(microservice.SubscriptionID)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockTags_RemoveFromSubscription_Call) Return(err error) *MockTags_RemoveFromSubscription_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockTags_RemoveFromSubscription_Call) RunAndReturn(run func(ctx context.Context, id microservice.SubscriptionID, name string) error) *MockTags_RemoveFromSubscription_Call {
 	_c.Call.Return(run)
 	return _c
 }
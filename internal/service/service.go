@@ -2,8 +2,13 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
 
 	microservice "github.com/ikotiki/go-rest-api-service-subscriptions"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/auth"
 	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/storage"
 )
 
@@ -12,6 +17,32 @@ var (
 	ErrUserSubscriptionPairAlreadyExists = storage.ErrUserSubscriptionPairAlreadyExists
 	ErrNoUserID                          = storage.ErrNoUserID
 	ErrNoSubscriptionID                  = storage.ErrNoSubscriptionID
+	ErrNoSuchService                     = storage.ErrNoSuchService
+	ErrUnknownService                    = fmt.Errorf("%w in the catalog", storage.ErrNoSuchService)
+	ErrServiceAlreadyExists              = storage.ErrServiceAlreadyExists
+	ErrInvalidServiceName                = errors.New("service name must contain letters or digits")
+	ErrNoSuchCategory                    = storage.ErrNoSuchCategory
+	ErrCategoryAlreadyExists             = storage.ErrCategoryAlreadyExists
+	ErrInvalidCategoryName               = errors.New("category name must not be empty")
+	ErrNoSuchTag                         = storage.ErrNoSuchTag
+	ErrInvalidTagName                    = errors.New("tag must not be empty or longer than 64 characters")
+	ErrNoSuchMember                      = storage.ErrNoSuchMember
+	ErrInvalidShare                      = errors.New("member should have either share percent in (0, 100] or non-negative share amount")
+	ErrSharesExceedPrice                 = errors.New("shares of members exceed the subscription price")
+	ErrInvalidPrice                      = storage.ErrInvalidPrice
+	ErrAlreadyExists                     = storage.ErrAlreadyExists
+	ErrConflict                          = storage.ErrConflict
+	ErrReferenceNotFound                 = storage.ErrReferenceNotFound
+	ErrInvalidValue                      = storage.ErrInvalidValue
+	ErrSerialization                     = storage.ErrSerialization
+	ErrQueryCanceled                     = storage.ErrQueryCanceled
+	ErrForbidden                         = auth.ErrForbidden
+	ErrNoSuchAPIKey                      = storage.ErrNoSuchAPIKey
+	ErrInvalidAPIKeyName                 = errors.New("api key name must not be empty")
+	ErrInvalidScope                      = fmt.Errorf("api key should have scopes of %s", strings.Join(auth.Scopes, ", "))
+	ErrInvalidRole                       = errors.New("role is not defined by the access policy")
+	ErrBulkSize                          = fmt.Errorf("bulk request should contain from 1 to %d items", MaxBulkSize)
+	ErrDuplicateBulkItem                 = errors.New("subscription is repeated in the request")
 )
 
 type Subscriptions interface {
@@ -19,17 +50,76 @@ type Subscriptions interface {
 	GetByID(ctx context.Context, id microservice.SubscriptionID) (sub *microservice.Subscription, err error)
 	Update(ctx context.Context, sub *microservice.Subscription) (err error)
 	DeleteByID(ctx context.Context, id microservice.SubscriptionID) (err error)
+	Upsert(ctx context.Context, sub *microservice.Subscription) (id microservice.SubscriptionID, created bool, err error)
+
+	CreateBulk(ctx context.Context, subs []*microservice.Subscription, allOrNothing bool) (res *BulkResults, err error)
+	UpdateBulk(ctx context.Context, subs []*microservice.Subscription, allOrNothing bool) (res *BulkResults, err error)
+	DeleteBulk(ctx context.Context, ids []microservice.SubscriptionID, allOrNothing bool) (res *BulkResults, err error)
+	Import(ctx context.Context, r io.Reader, opts *ImportOptions) (report *ImportReport, err error)
 
 	Query(ctx context.Context, args *SubscriptionQueryArgs) (subs []*microservice.Subscription, err error)
+	QueryEach(ctx context.Context, args *SubscriptionQueryArgs, fn func(sub *microservice.Subscription) error) (err error)
 	Sum(ctx context.Context, args *SubscriptionQueryArgs) (sum microservice.Price, err error)
+	SumByCategory(ctx context.Context, args *SubscriptionQueryArgs) (sums []*microservice.CategorySum, err error)
+}
+
+type Services interface {
+	Create(ctx context.Context, svc *microservice.Service) (id microservice.ServiceID, err error)
+	GetByID(ctx context.Context, id microservice.ServiceID) (svc *microservice.Service, err error)
+	GetByName(ctx context.Context, name string) (svc *microservice.Service, err error)
+	Update(ctx context.Context, svc *microservice.Service) (err error)
+	DeleteByID(ctx context.Context, id microservice.ServiceID) (err error)
+
+	List(ctx context.Context) (svcs []*microservice.Service, err error)
+}
+
+type Categories interface {
+	Create(ctx context.Context, cat *microservice.Category) (id microservice.CategoryID, err error)
+	GetByID(ctx context.Context, id microservice.CategoryID) (cat *microservice.Category, err error)
+	Update(ctx context.Context, cat *microservice.Category) (err error)
+	DeleteByID(ctx context.Context, id microservice.CategoryID) (err error)
+
+	List(ctx context.Context) (cats []*microservice.Category, err error)
+}
+
+type Tags interface {
+	AddToSubscription(ctx context.Context, id microservice.SubscriptionID, names []string) (tags []*microservice.Tag, err error)
+	RemoveFromSubscription(ctx context.Context, id microservice.SubscriptionID, name string) (err error)
+
+	ListBySubscription(ctx context.Context, id microservice.SubscriptionID) (tags []*microservice.Tag, err error)
+	ListByUser(ctx context.Context, userID microservice.UserID) (tags []*microservice.Tag, err error)
+}
+
+type Members interface {
+	Add(ctx context.Context, m *microservice.Member) (members []*microservice.Member, err error)
+	Remove(ctx context.Context, id microservice.SubscriptionID, userID microservice.UserID) (err error)
+
+	ListBySubscription(ctx context.Context, id microservice.SubscriptionID) (members []*microservice.Member, err error)
+}
+
+type APIKeys interface {
+	Create(ctx context.Context, key *microservice.APIKey) (secret string, err error)
+	Revoke(ctx context.Context, id microservice.APIKeyID) (err error)
+	List(ctx context.Context) (keys []*microservice.APIKey, err error)
+	auth.APIKeyVerifier
 }
 
 type Service struct {
 	Subscriptions
+	Services
+	Categories
+	Tags
+	Members
+	APIKeys
 }
 
-func NewService(store storage.Subscriptions) *Service {
+func NewService(store storage.Storage, policy *auth.Policy) *Service {
 	return &Service{
-		Subscriptions: NewSubscriptionService(store),
+		Subscriptions: NewSubscriptionService(store.Subscriptions, store.Services, store.Transactor),
+		Services:      NewCatalogService(store.Services),
+		Categories:    NewCategoryService(store.Categories),
+		Tags:          NewTagService(store.Tags, store.Subscriptions),
+		Members:       NewMemberService(store.Members, store.Subscriptions, store.Transactor),
+		APIKeys:       NewAPIKeyService(store.APIKeys, policy),
 	}
 }
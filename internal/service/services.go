@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode"
+
+	microservice "github.com/ikotiki/go-rest-api-service-subscriptions"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/storage"
+)
+
+type CatalogService struct {
+	store storage.Services
+}
+
+func NewCatalogService(store storage.Services) *CatalogService {
+	return &CatalogService{store: store}
+}
+
+// NormalizeServiceName makes a lookup key from a service name, so
+// "Yandex Plus", "yandex plus" and "YandexPlus" refer to the same service.
+func NormalizeServiceName(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
+
+func (s *CatalogService) GetByID(ctx context.Context, id microservice.ServiceID) (svc *microservice.Service, err error) {
+	return s.store.GetByID(ctx, id)
+}
+
+func (s *CatalogService) GetByName(ctx context.Context, name string) (svc *microservice.Service, err error) {
+	key := NormalizeServiceName(name)
+	if key == "" {
+		return nil, ErrNoSuchService
+	}
+	return s.store.GetByKey(ctx, key)
+}
+
+func (s *CatalogService) Create(ctx context.Context, svc *microservice.Service) (id microservice.ServiceID, err error) {
+	if err = s.prepare(ctx, svc); err != nil {
+		return 0, err
+	}
+	return s.store.Create(ctx, svc)
+}
+
+func (s *CatalogService) Update(ctx context.Context, svc *microservice.Service) (err error) {
+	if err = s.prepare(ctx, svc); err != nil {
+		return err
+	}
+	return s.store.Update(ctx, svc)
+}
+
+func (s *CatalogService) DeleteByID(ctx context.Context, id microservice.ServiceID) (err error) {
+	return s.store.DeleteByID(ctx, id)
+}
+
+func (s *CatalogService) List(ctx context.Context) (svcs []*microservice.Service, err error) {
+	return s.store.List(ctx)
+}
+
+// prepare fills normalized keys of the service and checks, that neither its
+// name nor aliases are taken by another catalog entry. The storage enforces
+// it as well, so concurrent writes fail with ErrServiceAlreadyExists too.
+func (s *CatalogService) prepare(ctx context.Context, svc *microservice.Service) error {
+	svc.Name = strings.TrimSpace(svc.Name)
+	svc.NameKey = NormalizeServiceName(svc.Name)
+	if svc.NameKey == "" {
+		return ErrInvalidServiceName
+	}
+
+	aliases := make([]string, 0, len(svc.Aliases))
+	keys := make([]string, 0, len(svc.Aliases))
+	seen := map[string]bool{svc.NameKey: true}
+	for _, alias := range svc.Aliases {
+		key := NormalizeServiceName(alias)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		aliases = append(aliases, strings.TrimSpace(alias))
+		keys = append(keys, key)
+	}
+	svc.Aliases = aliases
+	svc.AliasKeys = keys
+
+	for _, key := range append([]string{svc.NameKey}, keys...) {
+		existing, err := s.store.GetByKey(ctx, key)
+		if errors.Is(err, ErrNoSuchService) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if existing.ID != svc.ID {
+			return ErrServiceAlreadyExists
+		}
+	}
+
+	return nil
+}
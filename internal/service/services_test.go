@@ -0,0 +1,155 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	microservice "github.com/ikotiki/go-rest-api-service-subscriptions"
+	mock_storage "github.com/ikotiki/go-rest-api-service-subscriptions/internal/storage/mocks"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_NormalizeServiceName(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "Yandex Plus", want: "yandexplus"},
+		{input: "yandex plus", want: "yandexplus"},
+		{input: "YandexPlus", want: "yandexplus"},
+		{input: "  Yandex-Plus! ", want: "yandexplus"},
+		{input: "Яндекс Плюс", want: "яндексплюс"},
+		{input: "  - ", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.want, NormalizeServiceName(tt.input))
+		})
+	}
+}
+
+func TestCatalogService_Create(t *testing.T) {
+	logger.InitLoggerByFlag("trace", false)
+
+	tests := []struct {
+		name    string
+		mock    func(store *mock_storage.MockServices)
+		input   *microservice.Service
+		want    *microservice.Service
+		wantErr error
+	}{
+		{
+			name: "Ok",
+			mock: func(store *mock_storage.MockServices) {
+				store.EXPECT().GetByKey(mock.Anything, mock.Anything).Return(nil, ErrNoSuchService)
+				store.EXPECT().Create(mock.Anything, mock.Anything).Return(1, nil)
+			},
+			input: &microservice.Service{Name: " Yandex Plus ", Aliases: []string{"YandexPlus", "Яндекс Плюс", ""}},
+			want: &microservice.Service{
+				Name:      "Yandex Plus",
+				NameKey:   "yandexplus",
+				Aliases:   []string{"Яндекс Плюс"},
+				AliasKeys: []string{"яндексплюс"},
+			},
+		},
+		{
+			name: "Error (alias taken)",
+			mock: func(store *mock_storage.MockServices) {
+				store.EXPECT().GetByKey(mock.Anything, "yandexplus").Return(nil, ErrNoSuchService)
+				store.EXPECT().GetByKey(mock.Anything, "kinopoisk").Return(&microservice.Service{ID: 2}, nil)
+			},
+			input:   &microservice.Service{Name: "Yandex Plus", Aliases: []string{"Kinopoisk"}},
+			wantErr: ErrServiceAlreadyExists,
+		},
+		{
+			name:    "Error (empty name)",
+			mock:    func(store *mock_storage.MockServices) {},
+			input:   &microservice.Service{Name: " + "},
+			wantErr: ErrInvalidServiceName,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := mock_storage.NewMockServices(t)
+			tt.mock(store)
+			srv := NewCatalogService(store)
+
+			_, err := srv.Create(t.Context(), tt.input)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, tt.input)
+			}
+		})
+	}
+}
+
+func TestSubscriptionService_resolveService(t *testing.T) {
+	logger.InitLoggerByFlag("trace", false)
+
+	catalogID := microservice.ServiceID(7)
+	catalogSvc := &microservice.Service{ID: catalogID, Name: "Yandex Plus", DefaultPrice: 399}
+	userID := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
+
+	tests := []struct {
+		name    string
+		mock    func(catalog *mock_storage.MockServices)
+		input   *microservice.Subscription
+		want    *microservice.Subscription
+		wantErr error
+	}{
+		{
+			name: "Ok (by name)",
+			mock: func(catalog *mock_storage.MockServices) {
+				catalog.EXPECT().GetByKey(mock.Anything, "yandexplus").Return(catalogSvc, nil)
+			},
+			input: &microservice.Subscription{UserID: userID, ServiceName: "yandex plus", MonthlyPrice: 299},
+			want:  &microservice.Subscription{UserID: userID, ServiceID: &catalogID, ServiceName: "Yandex Plus", MonthlyPrice: 299},
+		},
+		{
+			name: "Ok (by id, default price)",
+			mock: func(catalog *mock_storage.MockServices) {
+				catalog.EXPECT().GetByID(mock.Anything, catalogID).Return(catalogSvc, nil)
+			},
+			input: &microservice.Subscription{UserID: userID, ServiceID: &catalogID},
+			want:  &microservice.Subscription{UserID: userID, ServiceID: &catalogID, ServiceName: "Yandex Plus", MonthlyPrice: 399},
+		},
+		{
+			name: "Ok (unknown name)",
+			mock: func(catalog *mock_storage.MockServices) {
+				catalog.EXPECT().GetByKey(mock.Anything, "localgym").Return(nil, ErrNoSuchService)
+			},
+			input: &microservice.Subscription{UserID: userID, ServiceName: "Local Gym", MonthlyPrice: 1500},
+			want:  &microservice.Subscription{UserID: userID, ServiceName: "Local Gym", MonthlyPrice: 1500},
+		},
+		{
+			name: "Error (unknown id)",
+			mock: func(catalog *mock_storage.MockServices) {
+				catalog.EXPECT().GetByID(mock.Anything, catalogID).Return(nil, ErrNoSuchService)
+			},
+			input:   &microservice.Subscription{UserID: userID, ServiceID: &catalogID},
+			wantErr: ErrNoSuchService,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			catalog := mock_storage.NewMockServices(t)
+			tt.mock(catalog)
+			srv := NewSubscriptionService(mock_storage.NewMockSubscriptions(t), catalog, nil)
+
+			err := srv.resolveService(t.Context(), tt.input)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, tt.input)
+			}
+		})
+	}
+}
@@ -2,26 +2,32 @@ package service
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"time"
 
 	microservice "github.com/ikotiki/go-rest-api-service-subscriptions"
 	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/storage"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/tracing"
 
 	"github.com/google/uuid"
-	"github.com/rs/zerolog/log"
+	"github.com/rs/zerolog"
 )
 
 type SubscriptionService struct {
-	store storage.Subscriptions
+	store   storage.Subscriptions
+	catalog storage.Services
+	tx      storage.Transactor
 }
 
 type SubscriptionQueryArgs struct {
-	UserID      string  `json:"user_id" example:"123e4567-e89b-12d3-a456-426614174000"`
-	ServiceName string  `json:"service_name" example:"Yandex Taxi"`
-	StartDate   string  `json:"start_date" example:"2006-01-02"`
-	EndDate     string  `json:"end_date" example:"2006-01-02"`
-	Order       []Order `json:"order"`
+	UserID      string   `json:"user_id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	ServiceName string   `json:"service_name" example:"Yandex Taxi"`
+	StartDate   string   `json:"start_date" example:"2006-01-02"`
+	EndDate     string   `json:"end_date" example:"2006-01-02"`
+	CategoryID  int64    `json:"category_id" example:"1"`
+	Tags        []string `json:"tags" example:"family"`
+	Order       []Order  `json:"order"`
 }
 
 type Order struct {
@@ -29,27 +35,244 @@ type Order struct {
 	Order   string `json:"order" example:"ASC"`
 }
 
-func NewSubscriptionService(store storage.Subscriptions) *SubscriptionService {
-	return &SubscriptionService{store: store}
+func NewSubscriptionService(store storage.Subscriptions, catalog storage.Services, tx storage.Transactor) *SubscriptionService {
+	return &SubscriptionService{store: store, catalog: catalog, tx: tx}
+}
+
+// withStorage returns the service working over the given storage,
+// e.g. bound to a transaction.
+func (s *SubscriptionService) withStorage(store storage.Storage) *SubscriptionService {
+	return NewSubscriptionService(store.Subscriptions, store.Services, store.Transactor)
 }
 
 func (s *SubscriptionService) GetByID(ctx context.Context, id microservice.SubscriptionID) (sub *microservice.Subscription, err error) {
-	return s.store.GetByID(ctx, id)
+	ctx, span := tracing.Start(ctx, "SubscriptionService.GetByID")
+	defer tracing.End(span, &err)
+
+	return getReadable(ctx, s.store, id)
 }
 
 func (s *SubscriptionService) Create(ctx context.Context, sub *microservice.Subscription) (id microservice.SubscriptionID, err error) {
+	ctx, span := tracing.Start(ctx, "SubscriptionService.Create")
+	defer tracing.End(span, &err)
+
+	if err = s.prepareCreate(ctx, sub); err != nil {
+		return 0, err
+	}
 	return s.store.Create(ctx, sub)
 }
 
 func (s *SubscriptionService) Update(ctx context.Context, sub *microservice.Subscription) (err error) {
-	return s.store.Update(ctx, sub)
+	ctx, span := tracing.Start(ctx, "SubscriptionService.Update")
+	defer tracing.End(span, &err)
+
+	return s.tx.WithTx(ctx, func(tx storage.Storage) error {
+		if err := s.withStorage(tx).prepareUpdate(ctx, tx.Members, sub); err != nil {
+			return err
+		}
+		return tx.Subscriptions.Update(ctx, sub)
+	})
+}
+
+// Upsert creates the subscription of the user to the service or updates
+// the open one, for callers not knowing ids of subscriptions. The
+// subscription with end date ends the open one, if there is such. The open
+// one is updated by id within a transaction like on Update, so shares of its
+// members are checked against the new price.
+func (s *SubscriptionService) Upsert(ctx context.Context, sub *microservice.Subscription) (id microservice.SubscriptionID, created bool, err error) {
+	ctx, span := tracing.Start(ctx, "SubscriptionService.Upsert")
+	defer tracing.End(span, &err)
+
+	if err = validateSubscription(sub); err != nil {
+		return 0, false, err
+	}
+	if err = authorize(ctx, sub.UserID); err != nil {
+		return 0, false, err
+	}
+	if err = s.resolveService(ctx, sub); err != nil {
+		return 0, false, err
+	}
+
+	err = s.tx.WithTx(ctx, func(tx storage.Storage) error {
+		txs := s.withStorage(tx)
+		open, err := txs.findOpen(ctx, sub)
+		if err != nil {
+			return err
+		}
+		if open == nil {
+			id, created, err = tx.Subscriptions.Upsert(ctx, sub)
+			return err
+		}
+		sub.ID, id, created = open.ID, open.ID, false
+		if err = txs.prepareUpdate(ctx, tx.Members, sub); err != nil {
+			return err
+		}
+		return tx.Subscriptions.Update(ctx, sub)
+	})
+	if err != nil {
+		return 0, false, err
+	}
+	return id, created, nil
+}
+
+// queryByKey returns subscriptions of the same user to the same service.
+func (s *SubscriptionService) queryByKey(ctx context.Context, sub *microservice.Subscription) ([]*microservice.Subscription, error) {
+	return s.store.Query(ctx, &storage.QueryArgs{
+		Where: []storage.Where{
+			{Column: "user_id", Operator: storage.OpEqual, Value: sub.UserID.String()},
+			{Column: "service_name", Operator: storage.OpEqual, Value: sub.ServiceName},
+		},
+	})
+}
+
+// findOpen returns the subscription without end date of the same user
+// and service, or nil.
+func (s *SubscriptionService) findOpen(ctx context.Context, sub *microservice.Subscription) (*microservice.Subscription, error) {
+	subs, err := s.queryByKey(ctx, sub)
+	if err != nil {
+		return nil, err
+	}
+	for _, other := range subs {
+		if !other.EndDate.Valid {
+			return other, nil
+		}
+	}
+	return nil, nil
+}
+
+// prepareCreate validates the new subscription and links it to the catalog.
+func (s *SubscriptionService) prepareCreate(ctx context.Context, sub *microservice.Subscription) error {
+	if err := s.prepareCreateFields(ctx, sub); err != nil {
+		return err
+	}
+	return s.checkOverlap(ctx, sub)
+}
+
+// prepareCreateFields does the checks of prepareCreate, which don't need
+// other subscriptions.
+func (s *SubscriptionService) prepareCreateFields(ctx context.Context, sub *microservice.Subscription) error {
+	if err := validateSubscription(sub); err != nil {
+		return err
+	}
+	if err := authorize(ctx, sub.UserID); err != nil {
+		return err
+	}
+	return s.resolveService(ctx, sub)
+}
+
+// prepareUpdate validates changes of the existing subscription and links
+// it to the catalog. It must run within a transaction, as the subscription
+// is locked, so shares of members are checked against the new price without
+// races with changes of members.
+func (s *SubscriptionService) prepareUpdate(ctx context.Context, members storage.Members, sub *microservice.Subscription) error {
+	if err := validateSubscription(sub); err != nil {
+		return err
+	}
+
+	cur, err := lockOwned(ctx, s.store, sub.ID)
+	if err != nil {
+		return err
+	}
+	// Owner of the subscription can't be changed.
+	sub.UserID = cur.UserID
+
+	if err = s.resolveService(ctx, sub); err != nil {
+		return err
+	}
+	if sub.MonthlyPrice != cur.MonthlyPrice {
+		shares, err := members.ListBySubscription(ctx, sub.ID)
+		if err != nil {
+			return err
+		}
+		if err = checkShares(shares, sub.MonthlyPrice); err != nil {
+			return err
+		}
+	}
+	return s.checkOverlap(ctx, sub)
+}
+
+// checkOverlap forbids the user to have several subscriptions to the same
+// service at the same time, while subscribing again after the previous one
+// has ended is fine. Postgres storage enforces it with an exclusion
+// constraint too, the check is for storages lacking such constraints.
+func (s *SubscriptionService) checkOverlap(ctx context.Context, sub *microservice.Subscription) error {
+	subs, err := s.queryByKey(ctx, sub)
+	if err != nil {
+		return err
+	}
+
+	for _, other := range subs {
+		if other.ID != sub.ID && periodsOverlap(sub, other) {
+			return ErrUserSubscriptionPairAlreadyExists
+		}
+	}
+	return nil
+}
+
+// periodsOverlap reports whether the half-open periods [start_date, end_date)
+// of the subscriptions intersect. Missing end date means the subscription is
+// still active.
+func periodsOverlap(a, b *microservice.Subscription) bool {
+	aStartsBeforeBEnds := !b.EndDate.Valid || a.StartDate.Time.Before(b.EndDate.Time)
+	bStartsBeforeAEnds := !a.EndDate.Valid || b.StartDate.Time.Before(a.EndDate.Time)
+	return aStartsBeforeBEnds && bStartsBeforeAEnds
+}
+
+// resolveService links the subscription to a catalog entry given either by
+// service_id or by one of the service names, replacing the name with the
+// canonical one and the missing price with the default one. Names unknown to
+// the catalog are kept as is for backward compatibility.
+func (s *SubscriptionService) resolveService(ctx context.Context, sub *microservice.Subscription) error {
+	var svc *microservice.Service
+	var err error
+	if sub.ServiceID != nil {
+		svc, err = s.catalog.GetByID(ctx, *sub.ServiceID)
+		if errors.Is(err, ErrNoSuchService) {
+			return ErrUnknownService
+		}
+	} else {
+		key := NormalizeServiceName(sub.ServiceName)
+		if key == "" {
+			return ErrInvalidServiceName
+		}
+		svc, err = s.catalog.GetByKey(ctx, key)
+		if errors.Is(err, ErrNoSuchService) {
+			return nil
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	sub.ServiceID = &svc.ID
+	sub.ServiceName = svc.Name
+	if sub.MonthlyPrice == 0 {
+		sub.MonthlyPrice = svc.DefaultPrice
+	}
+	if sub.CategoryID == nil {
+		sub.CategoryID = svc.CategoryID
+	}
+	return nil
 }
 
 func (s *SubscriptionService) DeleteByID(ctx context.Context, id microservice.SubscriptionID) (err error) {
+	ctx, span := tracing.Start(ctx, "SubscriptionService.DeleteByID")
+	defer tracing.End(span, &err)
+
+	if _, err = getOwned(ctx, s.store, id); err != nil {
+		return err
+	}
 	return s.store.DeleteByID(ctx, id)
 }
 
 func (s *SubscriptionService) Query(ctx context.Context, args *SubscriptionQueryArgs) (subs []*microservice.Subscription, err error) {
+	ctx, span := tracing.Start(ctx, "SubscriptionService.Query")
+	defer tracing.End(span, &err)
+
+	args, err = scopeQuery(ctx, args)
+	if err != nil {
+		return nil, err
+	}
 	queryArgs, err := s.parseQueryArgs(args)
 	if err != nil {
 		return nil, err
@@ -58,17 +281,58 @@ func (s *SubscriptionService) Query(ctx context.Context, args *SubscriptionQuery
 	return s.store.Query(ctx, queryArgs)
 }
 
+// QueryEach calls fn for every subscription of the query without loading
+// all of them at once, an error of fn stops the query and is returned.
+func (s *SubscriptionService) QueryEach(ctx context.Context, args *SubscriptionQueryArgs, fn func(sub *microservice.Subscription) error) (err error) {
+	ctx, span := tracing.Start(ctx, "SubscriptionService.QueryEach")
+	defer tracing.End(span, &err)
+
+	args, err = scopeQuery(ctx, args)
+	if err != nil {
+		return err
+	}
+	queryArgs, err := s.parseQueryArgs(args)
+	if err != nil {
+		return err
+	}
+
+	return s.store.QueryEach(ctx, queryArgs, fn)
+}
+
 func (s *SubscriptionService) Sum(ctx context.Context, args *SubscriptionQueryArgs) (sum microservice.Price, err error) {
+	ctx, span := tracing.Start(ctx, "SubscriptionService.Sum")
+	defer tracing.End(span, &err)
+
+	args, err = scopeQuery(ctx, args)
+	if err != nil {
+		return 0, err
+	}
 	queryArgs, err := s.parseQueryArgs(args)
 	if err != nil {
 		return 0, err
 	}
 
-	log.Debug().Interface("queryArgs", queryArgs).Msg("query args to summation")
+	zerolog.Ctx(ctx).Debug().Interface("queryArgs", queryArgs).Msg("query args to summation")
 
 	return s.store.Sum(ctx, queryArgs)
 }
 
+func (s *SubscriptionService) SumByCategory(ctx context.Context, args *SubscriptionQueryArgs) (sums []*microservice.CategorySum, err error) {
+	ctx, span := tracing.Start(ctx, "SubscriptionService.SumByCategory")
+	defer tracing.End(span, &err)
+
+	args, err = scopeQuery(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	queryArgs, err := s.parseQueryArgs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.store.SumByCategory(ctx, queryArgs)
+}
+
 func (s *SubscriptionService) parseQueryArgs(args *SubscriptionQueryArgs) (*storage.QueryArgs, error) {
 	var queryArgs storage.QueryArgs
 
@@ -110,11 +374,33 @@ func (s *SubscriptionService) parseQueryArgs(args *SubscriptionQueryArgs) (*stor
 		})
 	}
 
+	// Category
+	if args.CategoryID != 0 {
+		queryArgs.Where = append(queryArgs.Where, storage.Where{
+			Column:   "category_id",
+			Operator: storage.OpEqual,
+			Value:    args.CategoryID,
+		})
+	}
+
+	// Tags, subscription should have all of them
+	for _, name := range args.Tags {
+		tag, err := NormalizeTagName(name)
+		if err != nil {
+			return nil, err
+		}
+		queryArgs.Where = append(queryArgs.Where, storage.Where{
+			Column:   storage.ColumnTag,
+			Operator: storage.OpEqual,
+			Value:    tag,
+		})
+	}
+
 	// Order By
 	orders := make([]storage.OrderStruct, 0, len(args.Order))
 	for _, o := range args.Order {
 		switch o.OrderBy {
-		case "user_id", "service_name", "start_date", "end_date":
+		case "user_id", "service_name", "category_id", "start_date", "end_date":
 			queryArgsOrder := storage.OrderStruct{
 				OrderBy: o.OrderBy,
 			}
@@ -1,12 +1,17 @@
 package service
 
 import (
+	"context"
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
+	microservice "github.com/ikotiki/go-rest-api-service-subscriptions"
 	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/storage"
+	mock_storage "github.com/ikotiki/go-rest-api-service-subscriptions/internal/storage/mocks"
 	"github.com/ikotiki/go-rest-api-service-subscriptions/logger"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func Test_parseQueryArgs(t *testing.T) {
@@ -72,6 +77,42 @@ func Test_parseQueryArgs(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Ok (category and tags)",
+			mock: func() {},
+			input: &SubscriptionQueryArgs{
+				CategoryID: 3,
+				Tags:       []string{" Family ", "work  laptop"},
+			},
+			want: &storage.QueryArgs{
+				Where: []storage.Where{
+					{
+						Column:   "category_id",
+						Operator: storage.OpEqual,
+						Value:    int64(3),
+					},
+					{
+						Column:   storage.ColumnTag,
+						Operator: storage.OpEqual,
+						Value:    "family",
+					},
+					{
+						Column:   storage.ColumnTag,
+						Operator: storage.OpEqual,
+						Value:    "work laptop",
+					},
+				},
+				Order: []storage.OrderStruct{},
+			},
+		},
+		{
+			name: "Error (empty tag)",
+			mock: func() {},
+			input: &SubscriptionQueryArgs{
+				Tags: []string{"  "},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -89,3 +130,260 @@ func Test_parseQueryArgs(t *testing.T) {
 		})
 	}
 }
+
+func Test_periodsOverlap(t *testing.T) {
+	date := func(s string) microservice.Date {
+		d, _ := time.Parse("2006-01-02", s)
+		return microservice.NewDate(d)
+	}
+	period := func(start, end string) *microservice.Subscription {
+		sub := &microservice.Subscription{StartDate: date(start)}
+		if end != "" {
+			sub.EndDate = date(end)
+		}
+		return sub
+	}
+
+	tests := []struct {
+		name string
+		a, b *microservice.Subscription
+		want bool
+	}{
+		{name: "Before", a: period("2020-01-01", "2020-02-01"), b: period("2020-03-01", "2020-04-01"), want: false},
+		{name: "Resubscribed same day", a: period("2020-01-01", "2020-02-01"), b: period("2020-02-01", ""), want: false},
+		{name: "Intersects", a: period("2020-01-01", "2020-03-01"), b: period("2020-02-01", "2020-04-01"), want: true},
+		{name: "Inside", a: period("2020-01-01", "2020-05-01"), b: period("2020-02-01", "2020-03-01"), want: true},
+		{name: "Both active", a: period("2020-01-01", ""), b: period("2021-01-01", ""), want: true},
+		{name: "Active after ended", a: period("2020-01-01", "2020-02-01"), b: period("2019-01-01", ""), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, periodsOverlap(tt.a, tt.b))
+			assert.Equal(t, tt.want, periodsOverlap(tt.b, tt.a))
+		})
+	}
+}
+
+func TestSubscriptionService_Create(t *testing.T) {
+	logger.InitLoggerByFlag("trace", false)
+
+	userID := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
+	start, _ := time.Parse("2006-01-02", "2020-03-01")
+	ended := &microservice.Subscription{ID: 1, UserID: userID, ServiceName: "Local Gym",
+		StartDate: microservice.NewDate(start.AddDate(0, -2, 0)), EndDate: microservice.NewDate(start)}
+	active := &microservice.Subscription{ID: 2, UserID: userID, ServiceName: "Local Gym",
+		StartDate: microservice.NewDate(start.AddDate(0, -1, 0))}
+
+	tests := []struct {
+		name    string
+		mock    func(store *mock_storage.MockSubscriptions)
+		want    microservice.SubscriptionID
+		wantErr error
+	}{
+		{
+			name: "Ok (resubscribe)",
+			mock: func(store *mock_storage.MockSubscriptions) {
+				store.EXPECT().Query(mock.Anything, mock.Anything).Return([]*microservice.Subscription{ended}, nil)
+				store.EXPECT().Create(mock.Anything, mock.Anything).Return(3, nil)
+			},
+			want: 3,
+		},
+		{
+			name: "Error (overlap)",
+			mock: func(store *mock_storage.MockSubscriptions) {
+				store.EXPECT().Query(mock.Anything, mock.Anything).Return([]*microservice.Subscription{ended, active}, nil)
+			},
+			wantErr: ErrUserSubscriptionPairAlreadyExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := mock_storage.NewMockSubscriptions(t)
+			catalog := mock_storage.NewMockServices(t)
+			catalog.EXPECT().GetByKey(mock.Anything, "localgym").Return(nil, ErrNoSuchService)
+			tt.mock(store)
+			srv := NewSubscriptionService(store, catalog, nil)
+
+			got, err := srv.Create(t.Context(), &microservice.Subscription{
+				UserID: userID, ServiceName: "Local Gym", MonthlyPrice: 1500, StartDate: microservice.NewDate(start),
+			})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSubscriptionService_Upsert(t *testing.T) {
+	logger.InitLoggerByFlag("trace", false)
+
+	userID := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
+	memberID := uuid.MustParse("223e4567-e89b-12d3-a456-426614174000")
+	start, _ := time.Parse("2006-01-02", "2020-03-01")
+	open := &microservice.Subscription{ID: 2, UserID: userID, ServiceName: "Local Gym", MonthlyPrice: 2000,
+		StartDate: microservice.NewDate(start.AddDate(0, -1, 0))}
+	amount := func(v microservice.Price) *microservice.Price { return &v }
+
+	tests := []struct {
+		name        string
+		endDate     microservice.Date
+		mock        func(store *mock_storage.MockSubscriptions, members *mock_storage.MockMembers)
+		want        microservice.SubscriptionID
+		wantCreated bool
+		wantErr     error
+	}{
+		{
+			name: "Ok (no open)",
+			mock: func(store *mock_storage.MockSubscriptions, members *mock_storage.MockMembers) {
+				store.EXPECT().Query(mock.Anything, mock.Anything).Return(nil, nil)
+				store.EXPECT().Upsert(mock.Anything, mock.Anything).Return(3, true, nil)
+			},
+			want:        3,
+			wantCreated: true,
+		},
+		{
+			name: "Ok (updates open)",
+			mock: func(store *mock_storage.MockSubscriptions, members *mock_storage.MockMembers) {
+				store.EXPECT().Query(mock.Anything, mock.Anything).Return([]*microservice.Subscription{open}, nil)
+				store.EXPECT().GetByIDForUpdate(mock.Anything, open.ID).Return(open, nil)
+				members.EXPECT().ListBySubscription(mock.Anything, open.ID).Return([]*microservice.Member{
+					{SubscriptionID: open.ID, UserID: memberID, ShareAmount: amount(1000)},
+				}, nil)
+				store.EXPECT().Update(mock.Anything, mock.MatchedBy(func(sub *microservice.Subscription) bool {
+					return sub.ID == open.ID && sub.MonthlyPrice == 1500
+				})).Return(nil)
+			},
+			want: 2,
+		},
+		{
+			name:    "Ok (ends open)",
+			endDate: microservice.NewDate(start.AddDate(0, 1, 0)),
+			mock: func(store *mock_storage.MockSubscriptions, members *mock_storage.MockMembers) {
+				store.EXPECT().Query(mock.Anything, mock.Anything).Return([]*microservice.Subscription{open}, nil)
+				store.EXPECT().GetByIDForUpdate(mock.Anything, open.ID).Return(open, nil)
+				members.EXPECT().ListBySubscription(mock.Anything, open.ID).Return(nil, nil)
+				store.EXPECT().Update(mock.Anything, mock.MatchedBy(func(sub *microservice.Subscription) bool {
+					return sub.ID == open.ID && sub.EndDate.Valid
+				})).Return(nil)
+			},
+			want: 2,
+		},
+		{
+			name:    "Ok (ended, no open)",
+			endDate: microservice.NewDate(start.AddDate(0, 1, 0)),
+			mock: func(store *mock_storage.MockSubscriptions, members *mock_storage.MockMembers) {
+				store.EXPECT().Query(mock.Anything, mock.Anything).Return(nil, nil)
+				store.EXPECT().Upsert(mock.Anything, mock.Anything).Return(4, true, nil)
+			},
+			want:        4,
+			wantCreated: true,
+		},
+		{
+			name: "Error (shares exceed price)",
+			mock: func(store *mock_storage.MockSubscriptions, members *mock_storage.MockMembers) {
+				store.EXPECT().Query(mock.Anything, mock.Anything).Return([]*microservice.Subscription{open}, nil)
+				store.EXPECT().GetByIDForUpdate(mock.Anything, open.ID).Return(open, nil)
+				members.EXPECT().ListBySubscription(mock.Anything, open.ID).Return([]*microservice.Member{
+					{SubscriptionID: open.ID, UserID: memberID, ShareAmount: amount(1800)},
+				}, nil)
+			},
+			wantErr: ErrSharesExceedPrice,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := mock_storage.NewMockSubscriptions(t)
+			catalog := mock_storage.NewMockServices(t)
+			catalog.EXPECT().GetByKey(mock.Anything, "localgym").Return(nil, ErrNoSuchService)
+			members := mock_storage.NewMockMembers(t)
+			tx := mock_storage.NewMockTransactor(t)
+			tx.EXPECT().WithTx(mock.Anything, mock.Anything).RunAndReturn(func(ctx context.Context, fn func(tx storage.Storage) error) error {
+				return fn(storage.Storage{Subscriptions: store, Services: catalog, Members: members, Transactor: tx})
+			})
+			tt.mock(store, members)
+			srv := NewSubscriptionService(store, catalog, tx)
+
+			got, created, err := srv.Upsert(t.Context(), &microservice.Subscription{
+				UserID: userID, ServiceName: "Local Gym", MonthlyPrice: 1500,
+				StartDate: microservice.NewDate(start), EndDate: tt.endDate,
+			})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantCreated, created)
+		})
+	}
+}
+
+func TestSubscriptionService_Update(t *testing.T) {
+	logger.InitLoggerByFlag("trace", false)
+
+	userID := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
+	memberID := uuid.MustParse("223e4567-e89b-12d3-a456-426614174000")
+	start, _ := time.Parse("2006-01-02", "2020-03-01")
+	cur := &microservice.Subscription{ID: 1, UserID: userID, ServiceName: "Local Gym", MonthlyPrice: 1500, StartDate: microservice.NewDate(start)}
+	amount := func(v microservice.Price) *microservice.Price { return &v }
+
+	tests := []struct {
+		name    string
+		price   microservice.Price
+		mock    func(store *mock_storage.MockSubscriptions, members *mock_storage.MockMembers)
+		wantErr error
+	}{
+		{
+			name:  "Ok (shares fit)",
+			price: 1000,
+			mock: func(store *mock_storage.MockSubscriptions, members *mock_storage.MockMembers) {
+				members.EXPECT().ListBySubscription(mock.Anything, cur.ID).Return([]*microservice.Member{
+					{SubscriptionID: 1, UserID: memberID, ShareAmount: amount(1000)},
+				}, nil)
+				store.EXPECT().Query(mock.Anything, mock.Anything).Return([]*microservice.Subscription{cur}, nil)
+				store.EXPECT().Update(mock.Anything, mock.Anything).Return(nil)
+			},
+		},
+		{
+			name:  "Error (shares exceed price)",
+			price: 900,
+			mock: func(store *mock_storage.MockSubscriptions, members *mock_storage.MockMembers) {
+				members.EXPECT().ListBySubscription(mock.Anything, cur.ID).Return([]*microservice.Member{
+					{SubscriptionID: 1, UserID: memberID, ShareAmount: amount(1000)},
+				}, nil)
+			},
+			wantErr: ErrSharesExceedPrice,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := mock_storage.NewMockSubscriptions(t)
+			store.EXPECT().GetByIDForUpdate(mock.Anything, cur.ID).Return(cur, nil)
+			catalog := mock_storage.NewMockServices(t)
+			catalog.EXPECT().GetByKey(mock.Anything, "localgym").Return(nil, ErrNoSuchService)
+			members := mock_storage.NewMockMembers(t)
+			tx := mock_storage.NewMockTransactor(t)
+			tx.EXPECT().WithTx(mock.Anything, mock.Anything).RunAndReturn(func(ctx context.Context, fn func(tx storage.Storage) error) error {
+				return fn(storage.Storage{Subscriptions: store, Services: catalog, Members: members, Transactor: tx})
+			})
+			tt.mock(store, members)
+			srv := NewSubscriptionService(store, catalog, tx)
+
+			err := srv.Update(t.Context(), &microservice.Subscription{
+				ID: cur.ID, UserID: userID, ServiceName: "Local Gym", MonthlyPrice: tt.price, StartDate: cur.StartDate,
+			})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	microservice "github.com/ikotiki/go-rest-api-service-subscriptions"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/storage"
+)
+
+const maxTagLength = 64
+
+type TagService struct {
+	store storage.Tags
+	subs  storage.Subscriptions
+}
+
+func NewTagService(store storage.Tags, subs storage.Subscriptions) *TagService {
+	return &TagService{store: store, subs: subs}
+}
+
+// NormalizeTagName makes tags case insensitive and collapses inner spaces.
+func NormalizeTagName(name string) (string, error) {
+	name = strings.ToLower(strings.Join(strings.Fields(name), " "))
+	if name == "" || utf8.RuneCountInString(name) > maxTagLength {
+		return "", ErrInvalidTagName
+	}
+	return name, nil
+}
+
+// AddToSubscription attaches tags to the subscription on behalf of its owner
+// and returns all tags of the subscription.
+func (s *TagService) AddToSubscription(ctx context.Context, id microservice.SubscriptionID, names []string) (tags []*microservice.Tag, err error) {
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		tag, err := NormalizeTagName(name)
+		if err != nil {
+			return nil, err
+		}
+		normalized = append(normalized, tag)
+	}
+
+	sub, err := getOwned(ctx, s.subs, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(normalized) > 0 {
+		if err = s.store.AddToSubscription(ctx, id, sub.UserID, normalized); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.store.ListBySubscription(ctx, id)
+}
+
+func (s *TagService) RemoveFromSubscription(ctx context.Context, id microservice.SubscriptionID, name string) (err error) {
+	tag, err := NormalizeTagName(name)
+	if err != nil {
+		return err
+	}
+	if _, err = getOwned(ctx, s.subs, id); err != nil {
+		return err
+	}
+	return s.store.RemoveFromSubscription(ctx, id, tag)
+}
+
+func (s *TagService) ListBySubscription(ctx context.Context, id microservice.SubscriptionID) (tags []*microservice.Tag, err error) {
+	if _, err = getReadable(ctx, s.subs, id); err != nil {
+		return nil, err
+	}
+	return s.store.ListBySubscription(ctx, id)
+}
+
+func (s *TagService) ListByUser(ctx context.Context, userID microservice.UserID) (tags []*microservice.Tag, err error) {
+	if err = authorizeRead(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.store.ListByUser(ctx, userID)
+}
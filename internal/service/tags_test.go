@@ -0,0 +1,70 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	microservice "github.com/ikotiki/go-rest-api-service-subscriptions"
+	mock_storage "github.com/ikotiki/go-rest-api-service-subscriptions/internal/storage/mocks"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTagService_AddToSubscription(t *testing.T) {
+	logger.InitLoggerByFlag("trace", false)
+
+	userID := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
+	tags := []*microservice.Tag{{ID: 1, UserID: userID, Name: "family"}}
+
+	tests := []struct {
+		name    string
+		mock    func(store *mock_storage.MockTags, subs *mock_storage.MockSubscriptions)
+		input   []string
+		want    []*microservice.Tag
+		wantErr error
+	}{
+		{
+			name: "Ok",
+			mock: func(store *mock_storage.MockTags, subs *mock_storage.MockSubscriptions) {
+				subs.EXPECT().GetByID(mock.Anything, microservice.SubscriptionID(1)).
+					Return(&microservice.Subscription{ID: 1, UserID: userID}, nil)
+				store.EXPECT().AddToSubscription(mock.Anything, microservice.SubscriptionID(1), userID, []string{"family"}).Return(nil)
+				store.EXPECT().ListBySubscription(mock.Anything, microservice.SubscriptionID(1)).Return(tags, nil)
+			},
+			input: []string{" Family"},
+			want:  tags,
+		},
+		{
+			name: "Error (no subscription)",
+			mock: func(store *mock_storage.MockTags, subs *mock_storage.MockSubscriptions) {
+				subs.EXPECT().GetByID(mock.Anything, microservice.SubscriptionID(1)).Return(nil, ErrNoSuchSubscription)
+			},
+			input:   []string{"family"},
+			wantErr: ErrNoSuchSubscription,
+		},
+		{
+			name:    "Error (invalid tag)",
+			mock:    func(store *mock_storage.MockTags, subs *mock_storage.MockSubscriptions) {},
+			input:   []string{"family", " "},
+			wantErr: ErrInvalidTagName,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := mock_storage.NewMockTags(t)
+			subs := mock_storage.NewMockSubscriptions(t)
+			tt.mock(store, subs)
+			srv := NewTagService(store, subs)
+
+			got, err := srv.AddToSubscription(t.Context(), 1, tt.input)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	microservice "github.com/ikotiki/go-rest-api-service-subscriptions"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxServiceNameLen matches the service_name column size.
+	MaxServiceNameLen = 120
+	// MaxMonthlyPrice is an upper bound for a sane monthly price.
+	MaxMonthlyPrice microservice.Price = 1_000_000
+)
+
+// FieldError describes a single invalid field of the input.
+type FieldError struct {
+	Field   string `json:"field" example:"end_date"`
+	Message string `json:"message" example:"must be after start_date"`
+}
+
+// ValidationError is returned when the input breaks domain rules.
+// It lists every failing field, not only the first one.
+type ValidationError struct {
+	Fields []FieldError `json:"fields"`
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		msgs = append(msgs, f.Field+" "+f.Message)
+	}
+	return "validation failed: " + strings.Join(msgs, "; ")
+}
+
+func (e *ValidationError) add(field, msg string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Message: msg})
+}
+
+// err returns nil, if there are no failing fields.
+func (e *ValidationError) err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// validateSubscription checks the subscription given by the user,
+// before the service name is resolved by the catalog.
+func validateSubscription(sub *microservice.Subscription) error {
+	verr := &ValidationError{}
+
+	if sub.UserID == uuid.Nil {
+		verr.add("user_id", "must be a non-nil UUID")
+	}
+
+	name := strings.TrimSpace(sub.ServiceName)
+	switch {
+	case name == "" && sub.ServiceID == nil:
+		verr.add("service_name", "must not be empty")
+	case utf8.RuneCountInString(name) > MaxServiceNameLen:
+		verr.add("service_name", fmt.Sprintf("must be at most %d characters", MaxServiceNameLen))
+	}
+
+	switch {
+	case sub.MonthlyPrice < 0:
+		verr.add("monthly_price", "must not be negative")
+	case sub.MonthlyPrice > MaxMonthlyPrice:
+		verr.add("monthly_price", fmt.Sprintf("must not exceed %d", MaxMonthlyPrice))
+	}
+
+	if !sub.StartDate.Valid {
+		verr.add("start_date", "is required")
+	}
+	if sub.StartDate.Valid && sub.EndDate.Valid && !sub.EndDate.Time.After(sub.StartDate.Time) {
+		verr.add("end_date", "must be after start_date")
+	}
+
+	return verr.err()
+}
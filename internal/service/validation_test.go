@@ -0,0 +1,68 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	microservice "github.com/ikotiki/go-rest-api-service-subscriptions"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_validateSubscription(t *testing.T) {
+	userID := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
+	start := microservice.NewDate(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	serviceID := microservice.ServiceID(1)
+
+	tests := []struct {
+		name       string
+		input      *microservice.Subscription
+		wantFields []string
+	}{
+		{
+			name:  "Ok",
+			input: &microservice.Subscription{UserID: userID, ServiceName: "Yandex Plus", MonthlyPrice: 399, StartDate: start, EndDate: start.Add(time.Hour)},
+		},
+		{
+			name:  "Ok (by service id)",
+			input: &microservice.Subscription{UserID: userID, ServiceID: &serviceID, StartDate: start},
+		},
+		{
+			name:       "Error (all fields)",
+			input:      &microservice.Subscription{ServiceName: " ", MonthlyPrice: -1},
+			wantFields: []string{"user_id", "service_name", "monthly_price", "start_date"},
+		},
+		{
+			name: "Error (long name, absurd price, end before start)",
+			input: &microservice.Subscription{UserID: userID, ServiceName: strings.Repeat("я", MaxServiceNameLen+1),
+				MonthlyPrice: MaxMonthlyPrice + 1, StartDate: start, EndDate: start.Add(-time.Hour)},
+			wantFields: []string{"service_name", "monthly_price", "end_date"},
+		},
+		{
+			name:       "Error (end on start)",
+			input:      &microservice.Subscription{UserID: userID, ServiceName: "Yandex Plus", StartDate: start, EndDate: start},
+			wantFields: []string{"end_date"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSubscription(tt.input)
+			if tt.wantFields == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			verr, ok := err.(*ValidationError)
+			if !assert.True(t, ok, "want ValidationError, got %v", err) {
+				return
+			}
+			fields := make([]string, 0, len(verr.Fields))
+			for _, f := range verr.Fields {
+				fields = append(fields, f.Field)
+			}
+			assert.Equal(t, tt.wantFields, fields)
+		})
+	}
+}
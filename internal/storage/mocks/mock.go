@@ -10,44 +10,2410 @@ import (
 	mock "github.com/stretchr/testify/mock"
 )
 
-// NewMockSubscriptions creates a new instance of MockSubscriptions. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// NewMockAPIKeys creates a new instance of MockAPIKeys. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
-func NewMockSubscriptions(t interface {
+func NewMockAPIKeys(t interface {
 	mock.TestingT
 	Cleanup(func())
-}) *MockSubscriptions {
-	mock := &MockSubscriptions{}
+}) *MockAPIKeys {
+	mock := &MockAPIKeys{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockAPIKeys is an autogenerated mock type for the APIKeys type
+type MockAPIKeys struct {
+	mock.Mock
+}
+
+type MockAPIKeys_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockAPIKeys) EXPECT() *MockAPIKeys_Expecter {
+	return &MockAPIKeys_Expecter{mock: &_m.Mock}
+}
+
+// Create provides a mock function for the type MockAPIKeys
+func (_mock *MockAPIKeys) Create(ctx context.Context, key *storage.APIKey) (storage.APIKeyID, error) {
+	ret := _mock.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 storage.APIKeyID
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *storage.APIKey) (storage.APIKeyID, error)); ok {
+		return returnFunc(ctx, key)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *storage.APIKey) storage.APIKeyID); ok {
+		r0 = returnFunc(ctx, key)
+	} else {
+		r0 = ret.Get(0).(storage.APIKeyID)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *storage.APIKey) error); ok {
+		r1 = returnFunc(ctx, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAPIKeys_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockAPIKeys_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key *storage.APIKey
+func (_e *MockAPIKeys_Expecter) Create(ctx interface{}, key interface{}) *MockAPIKeys_Create_Call {
+	return &MockAPIKeys_Create_Call{Call: _e.mock.On("Create", ctx, key)}
+}
+
+func (_c *MockAPIKeys_Create_Call) Run(run func(ctx context.Context, key *storage.APIKey)) *MockAPIKeys_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *storage.APIKey
+		if args[1] != nil {
+			arg1 = args[1].(*storage.APIKey)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAPIKeys_Create_Call) Return(id storage.APIKeyID, err error) *MockAPIKeys_Create_Call {
+	_c.Call.Return(id, err)
+	return _c
+}
+
+func (_c *MockAPIKeys_Create_Call) RunAndReturn(run func(ctx context.Context, key *storage.APIKey) (storage.APIKeyID, error)) *MockAPIKeys_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByHash provides a mock function for the type MockAPIKeys
+func (_mock *MockAPIKeys) GetByHash(ctx context.Context, hash string) (*storage.APIKey, error) {
+	ret := _mock.Called(ctx, hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByHash")
+	}
+
+	var r0 *storage.APIKey
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*storage.APIKey, error)); ok {
+		return returnFunc(ctx, hash)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *storage.APIKey); ok {
+		r0 = returnFunc(ctx, hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*storage.APIKey)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAPIKeys_GetByHash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByHash'
+type MockAPIKeys_GetByHash_Call struct {
+	*mock.Call
+}
+
+// GetByHash is a helper method to define mock.On call
+//   - ctx context.Context
+//   - hash string
+func (_e *MockAPIKeys_Expecter) GetByHash(ctx interface{}, hash interface{}) *MockAPIKeys_GetByHash_Call {
+	return &MockAPIKeys_GetByHash_Call{Call: _e.mock.On("GetByHash", ctx, hash)}
+}
+
+func (_c *MockAPIKeys_GetByHash_Call) Run(run func(ctx context.Context, hash string)) *MockAPIKeys_GetByHash_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAPIKeys_GetByHash_Call) Return(key *storage.APIKey, err error) *MockAPIKeys_GetByHash_Call {
+	_c.Call.Return(key, err)
+	return _c
+}
+
+func (_c *MockAPIKeys_GetByHash_Call) RunAndReturn(run func(ctx context.Context, hash string) (*storage.APIKey, error)) *MockAPIKeys_GetByHash_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// List provides a mock function for the type MockAPIKeys
+func (_mock *MockAPIKeys) List(ctx context.Context) ([]*storage.APIKey, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []*storage.APIKey
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]*storage.APIKey, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) []*storage.APIKey); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*storage.APIKey)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAPIKeys_List_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'List'
+type MockAPIKeys_List_Call struct {
+	*mock.Call
+}
+
+// List is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockAPIKeys_Expecter) List(ctx interface{}) *MockAPIKeys_List_Call {
+	return &MockAPIKeys_List_Call{Call: _e.mock.On("List", ctx)}
+}
+
+func (_c *MockAPIKeys_List_Call) Run(run func(ctx context.Context)) *MockAPIKeys_List_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAPIKeys_List_Call) Return(keys []*storage.APIKey, err error) *MockAPIKeys_List_Call {
+	_c.Call.Return(keys, err)
+	return _c
+}
+
+func (_c *MockAPIKeys_List_Call) RunAndReturn(run func(ctx context.Context) ([]*storage.APIKey, error)) *MockAPIKeys_List_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Revoke provides a mock function for the type MockAPIKeys
+func (_mock *MockAPIKeys) Revoke(ctx context.Context, id storage.APIKeyID) error {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Revoke")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, storage.APIKeyID) error); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAPIKeys_Revoke_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Revoke'
+type MockAPIKeys_Revoke_Call struct {
+	*mock.Call
+}
+
+// Revoke is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id storage.APIKeyID
+func (_e *MockAPIKeys_Expecter) Revoke(ctx interface{}, id interface{}) *MockAPIKeys_Revoke_Call {
+	return &MockAPIKeys_Revoke_Call{Call: _e.mock.On("Revoke", ctx, id)}
+}
+
+func (_c *MockAPIKeys_Revoke_Call) Run(run func(ctx context.Context, id storage.APIKeyID)) *MockAPIKeys_Revoke_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 storage.APIKeyID
+		if args[1] != nil {
+			arg1 = args[1].(storage.APIKeyID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAPIKeys_Revoke_Call) Return(err error) *MockAPIKeys_Revoke_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAPIKeys_Revoke_Call) RunAndReturn(run func(ctx context.Context, id storage.APIKeyID) error) *MockAPIKeys_Revoke_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockCategories creates a new instance of MockCategories. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockCategories(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockCategories {
+	mock := &MockCategories{}
 	mock.Mock.Test(t)
 
-	t.Cleanup(func() { mock.AssertExpectations(t) })
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockCategories is an autogenerated mock type for the Categories type
+type MockCategories struct {
+	mock.Mock
+}
+
+type MockCategories_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockCategories) EXPECT() *MockCategories_Expecter {
+	return &MockCategories_Expecter{mock: &_m.Mock}
+}
+
+// Create provides a mock function for the type MockCategories
+func (_mock *MockCategories) Create(ctx context.Context, cat *storage.Category) (storage.CategoryID, error) {
+	ret := _mock.Called(ctx, cat)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 storage.CategoryID
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *storage.Category) (storage.CategoryID, error)); ok {
+		return returnFunc(ctx, cat)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *storage.Category) storage.CategoryID); ok {
+		r0 = returnFunc(ctx, cat)
+	} else {
+		r0 = ret.Get(0).(storage.CategoryID)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *storage.Category) error); ok {
+		r1 = returnFunc(ctx, cat)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCategories_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockCategories_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - ctx context.Context
+//   - cat *storage.Category
+func (_e *MockCategories_Expecter) Create(ctx interface{}, cat interface{}) *MockCategories_Create_Call {
+	return &MockCategories_Create_Call{Call: _e.mock.On("Create", ctx, cat)}
+}
+
+func (_c *MockCategories_Create_Call) Run(run func(ctx context.Context, cat *storage.Category)) *MockCategories_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *storage.Category
+		if args[1] != nil {
+			arg1 = args[1].(*storage.Category)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCategories_Create_Call) Return(id storage.CategoryID, err error) *MockCategories_Create_Call {
+	_c.Call.Return(id, err)
+	return _c
+}
+
+func (_c *MockCategories_Create_Call) RunAndReturn(run func(ctx context.Context, cat *storage.Category) (storage.CategoryID, error)) *MockCategories_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteByID provides a mock function for the type MockCategories
+func (_mock *MockCategories) DeleteByID(ctx context.Context, id storage.CategoryID) error {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteByID")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, storage.CategoryID) error); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockCategories_DeleteByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteByID'
+type MockCategories_DeleteByID_Call struct {
+	*mock.Call
+}
+
+// DeleteByID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id storage.CategoryID
+func (_e *MockCategories_Expecter) DeleteByID(ctx interface{}, id interface{}) *MockCategories_DeleteByID_Call {
+	return &MockCategories_DeleteByID_Call{Call: _e.mock.On("DeleteByID", ctx, id)}
+}
+
+func (_c *MockCategories_DeleteByID_Call) Run(run func(ctx context.Context, id storage.CategoryID)) *MockCategories_DeleteByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 storage.CategoryID
+		if args[1] != nil {
+			arg1 = args[1].(storage.CategoryID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCategories_DeleteByID_Call) Return(err error) *MockCategories_DeleteByID_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockCategories_DeleteByID_Call) RunAndReturn(run func(ctx context.Context, id storage.CategoryID) error) *MockCategories_DeleteByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByID provides a mock function for the type MockCategories
+func (_mock *MockCategories) GetByID(ctx context.Context, id storage.CategoryID) (*storage.Category, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByID")
+	}
+
+	var r0 *storage.Category
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, storage.CategoryID) (*storage.Category, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, storage.CategoryID) *storage.Category); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*storage.Category)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, storage.CategoryID) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCategories_GetByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByID'
+type MockCategories_GetByID_Call struct {
+	*mock.Call
+}
+
+// GetByID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id storage.CategoryID
+func (_e *MockCategories_Expecter) GetByID(ctx interface{}, id interface{}) *MockCategories_GetByID_Call {
+	return &MockCategories_GetByID_Call{Call: _e.mock.On("GetByID", ctx, id)}
+}
+
+func (_c *MockCategories_GetByID_Call) Run(run func(ctx context.Context, id storage.CategoryID)) *MockCategories_GetByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 storage.CategoryID
+		if args[1] != nil {
+			arg1 = args[1].(storage.CategoryID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCategories_GetByID_Call) Return(cat *storage.Category, err error) *MockCategories_GetByID_Call {
+	_c.Call.Return(cat, err)
+	return _c
+}
+
+func (_c *MockCategories_GetByID_Call) RunAndReturn(run func(ctx context.Context, id storage.CategoryID) (*storage.Category, error)) *MockCategories_GetByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// List provides a mock function for the type MockCategories
+func (_mock *MockCategories) List(ctx context.Context) ([]*storage.Category, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []*storage.Category
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]*storage.Category, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) []*storage.Category); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*storage.Category)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCategories_List_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'List'
+type MockCategories_List_Call struct {
+	*mock.Call
+}
+
+// List is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockCategories_Expecter) List(ctx interface{}) *MockCategories_List_Call {
+	return &MockCategories_List_Call{Call: _e.mock.On("List", ctx)}
+}
+
+func (_c *MockCategories_List_Call) Run(run func(ctx context.Context)) *MockCategories_List_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCategories_List_Call) Return(cats []*storage.Category, err error) *MockCategories_List_Call {
+	_c.Call.Return(cats, err)
+	return _c
+}
+
+func (_c *MockCategories_List_Call) RunAndReturn(run func(ctx context.Context) ([]*storage.Category, error)) *MockCategories_List_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Update provides a mock function for the type MockCategories
+func (_mock *MockCategories) Update(ctx context.Context, cat *storage.Category) error {
+	ret := _mock.Called(ctx, cat)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *storage.Category) error); ok {
+		r0 = returnFunc(ctx, cat)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockCategories_Update_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Update'
+type MockCategories_Update_Call struct {
+	*mock.Call
+}
+
+// Update is a helper method to define mock.On call
+//   - ctx context.Context
+//   - cat *storage.Category
+func (_e *MockCategories_Expecter) Update(ctx interface{}, cat interface{}) *MockCategories_Update_Call {
+	return &MockCategories_Update_Call{Call: _e.mock.On("Update", ctx, cat)}
+}
+
+func (_c *MockCategories_Update_Call) Run(run func(ctx context.Context, cat *storage.Category)) *MockCategories_Update_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *storage.Category
+		if args[1] != nil {
+			arg1 = args[1].(*storage.Category)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCategories_Update_Call) Return(err error) *MockCategories_Update_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockCategories_Update_Call) RunAndReturn(run func(ctx context.Context, cat *storage.Category) error) *MockCategories_Update_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockIdempotencyKeys creates a new instance of MockIdempotencyKeys. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockIdempotencyKeys(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockIdempotencyKeys {
+	mock := &MockIdempotencyKeys{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockIdempotencyKeys is an autogenerated mock type for the IdempotencyKeys type
+type MockIdempotencyKeys struct {
+	mock.Mock
+}
+
+type MockIdempotencyKeys_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockIdempotencyKeys) EXPECT() *MockIdempotencyKeys_Expecter {
+	return &MockIdempotencyKeys_Expecter{mock: &_m.Mock}
+}
+
+// Acquire provides a mock function for the type MockIdempotencyKeys
+func (_mock *MockIdempotencyKeys) Acquire(ctx context.Context, rec *storage.IdempotencyRecord) (*storage.IdempotencyRecord, error) {
+	ret := _mock.Called(ctx, rec)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Acquire")
+	}
+
+	var r0 *storage.IdempotencyRecord
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *storage.IdempotencyRecord) (*storage.IdempotencyRecord, error)); ok {
+		return returnFunc(ctx, rec)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *storage.IdempotencyRecord) *storage.IdempotencyRecord); ok {
+		r0 = returnFunc(ctx, rec)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*storage.IdempotencyRecord)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *storage.IdempotencyRecord) error); ok {
+		r1 = returnFunc(ctx, rec)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockIdempotencyKeys_Acquire_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Acquire'
+type MockIdempotencyKeys_Acquire_Call struct {
+	*mock.Call
+}
+
+// Acquire is a helper method to define mock.On call
+//   - ctx context.Context
+//   - rec *storage.IdempotencyRecord
+func (_e *MockIdempotencyKeys_Expecter) Acquire(ctx interface{}, rec interface{}) *MockIdempotencyKeys_Acquire_Call {
+	return &MockIdempotencyKeys_Acquire_Call{Call: _e.mock.On("Acquire", ctx, rec)}
+}
+
+func (_c *MockIdempotencyKeys_Acquire_Call) Run(run func(ctx context.Context, rec *storage.IdempotencyRecord)) *MockIdempotencyKeys_Acquire_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *storage.IdempotencyRecord
+		if args[1] != nil {
+			arg1 = args[1].(*storage.IdempotencyRecord)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockIdempotencyKeys_Acquire_Call) Return(existing *storage.IdempotencyRecord, err error) *MockIdempotencyKeys_Acquire_Call {
+	_c.Call.Return(existing, err)
+	return _c
+}
+
+func (_c *MockIdempotencyKeys_Acquire_Call) RunAndReturn(run func(ctx context.Context, rec *storage.IdempotencyRecord) (*storage.IdempotencyRecord, error)) *MockIdempotencyKeys_Acquire_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Complete provides a mock function for the type MockIdempotencyKeys
+func (_mock *MockIdempotencyKeys) Complete(ctx context.Context, rec *storage.IdempotencyRecord) error {
+	ret := _mock.Called(ctx, rec)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Complete")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *storage.IdempotencyRecord) error); ok {
+		r0 = returnFunc(ctx, rec)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockIdempotencyKeys_Complete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Complete'
+type MockIdempotencyKeys_Complete_Call struct {
+	*mock.Call
+}
+
+// Complete is a helper method to define mock.On call
+//   - ctx context.Context
+//   - rec *storage.IdempotencyRecord
+func (_e *MockIdempotencyKeys_Expecter) Complete(ctx interface{}, rec interface{}) *MockIdempotencyKeys_Complete_Call {
+	return &MockIdempotencyKeys_Complete_Call{Call: _e.mock.On("Complete", ctx, rec)}
+}
+
+func (_c *MockIdempotencyKeys_Complete_Call) Run(run func(ctx context.Context, rec *storage.IdempotencyRecord)) *MockIdempotencyKeys_Complete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *storage.IdempotencyRecord
+		if args[1] != nil {
+			arg1 = args[1].(*storage.IdempotencyRecord)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockIdempotencyKeys_Complete_Call) Return(err error) *MockIdempotencyKeys_Complete_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockIdempotencyKeys_Complete_Call) RunAndReturn(run func(ctx context.Context, rec *storage.IdempotencyRecord) error) *MockIdempotencyKeys_Complete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteExpired provides a mock function for the type MockIdempotencyKeys
+func (_mock *MockIdempotencyKeys) DeleteExpired(ctx context.Context) (int64, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteExpired")
+	}
+
+	var r0 int64
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) (int64, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) int64); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockIdempotencyKeys_DeleteExpired_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteExpired'
+type MockIdempotencyKeys_DeleteExpired_Call struct {
+	*mock.Call
+}
+
+// DeleteExpired is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockIdempotencyKeys_Expecter) DeleteExpired(ctx interface{}) *MockIdempotencyKeys_DeleteExpired_Call {
+	return &MockIdempotencyKeys_DeleteExpired_Call{Call: _e.mock.On("DeleteExpired", ctx)}
+}
+
+func (_c *MockIdempotencyKeys_DeleteExpired_Call) Run(run func(ctx context.Context)) *MockIdempotencyKeys_DeleteExpired_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockIdempotencyKeys_DeleteExpired_Call) Return(n int64, err error) *MockIdempotencyKeys_DeleteExpired_Call {
+	_c.Call.Return(n, err)
+	return _c
+}
+
+func (_c *MockIdempotencyKeys_DeleteExpired_Call) RunAndReturn(run func(ctx context.Context) (int64, error)) *MockIdempotencyKeys_DeleteExpired_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Release provides a mock function for the type MockIdempotencyKeys
+func (_mock *MockIdempotencyKeys) Release(ctx context.Context, scope string, key string) error {
+	ret := _mock.Called(ctx, scope, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Release")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = returnFunc(ctx, scope, key)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockIdempotencyKeys_Release_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Release'
+type MockIdempotencyKeys_Release_Call struct {
+	*mock.Call
+}
+
+// Release is a helper method to define mock.On call
+//   - ctx context.Context
+//   - scope string
+//   - key string
+func (_e *MockIdempotencyKeys_Expecter) Release(ctx interface{}, scope interface{}, key interface{}) *MockIdempotencyKeys_Release_Call {
+	return &MockIdempotencyKeys_Release_Call{Call: _e.mock.On("Release", ctx, scope, key)}
+}
+
+func (_c *MockIdempotencyKeys_Release_Call) Run(run func(ctx context.Context, scope string, key string)) *MockIdempotencyKeys_Release_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockIdempotencyKeys_Release_Call) Return(err error) *MockIdempotencyKeys_Release_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockIdempotencyKeys_Release_Call) RunAndReturn(run func(ctx context.Context, scope string, key string) error) *MockIdempotencyKeys_Release_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockMembers creates a new instance of MockMembers. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockMembers(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockMembers {
+	mock := &MockMembers{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockMembers is an autogenerated mock type for the Members type
+type MockMembers struct {
+	mock.Mock
+}
+
+type MockMembers_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockMembers) EXPECT() *MockMembers_Expecter {
+	return &MockMembers_Expecter{mock: &_m.Mock}
+}
+
+// Add provides a mock function for the type MockMembers
+func (_mock *MockMembers) Add(ctx context.Context, m *storage.Member) error {
+	ret := _mock.Called(ctx, m)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Add")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *storage.Member) error); ok {
+		r0 = returnFunc(ctx, m)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockMembers_Add_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Add'
+type MockMembers_Add_Call struct {
+	*mock.Call
+}
+
+// Add is a helper method to define mock.On call
+//   - ctx context.Context
+//   - m *storage.Member
+func (_e *MockMembers_Expecter) Add(ctx interface{}, m interface{}) *MockMembers_Add_Call {
+	return &MockMembers_Add_Call{Call: _e.mock.On("Add", ctx, m)}
+}
+
+func (_c *MockMembers_Add_Call) Run(run func(ctx context.Context, m *storage.Member)) *MockMembers_Add_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *storage.Member
+		if args[1] != nil {
+			arg1 = args[1].(*storage.Member)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockMembers_Add_Call) Return(err error) *MockMembers_Add_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockMembers_Add_Call) RunAndReturn(run func(ctx context.Context, m *storage.Member) error) *MockMembers_Add_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListBySubscription provides a mock function for the type MockMembers
+func (_mock *MockMembers) ListBySubscription(ctx context.Context, id storage.SubscriptionID) ([]*storage.Member, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListBySubscription")
+	}
+
+	var r0 []*storage.Member
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, storage.SubscriptionID) ([]*storage.Member, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, storage.SubscriptionID) []*storage.Member); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*storage.Member)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, storage.SubscriptionID) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockMembers_ListBySubscription_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListBySubscription'
+type MockMembers_ListBySubscription_Call struct {
+	*mock.Call
+}
+
+// ListBySubscription is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id storage.SubscriptionID
+func (_e *MockMembers_Expecter) ListBySubscription(ctx interface{}, id interface{}) *MockMembers_ListBySubscription_Call {
+	return &MockMembers_ListBySubscription_Call{Call: _e.mock.On("ListBySubscription", ctx, id)}
+}
+
+func (_c *MockMembers_ListBySubscription_Call) Run(run func(ctx context.Context, id storage.SubscriptionID)) *MockMembers_ListBySubscription_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 storage.SubscriptionID
+		if args[1] != nil {
+			arg1 = args[1].(storage.SubscriptionID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockMembers_ListBySubscription_Call) Return(members []*storage.Member, err error) *MockMembers_ListBySubscription_Call {
+	_c.Call.Return(members, err)
+	return _c
+}
+
+func (_c *MockMembers_ListBySubscription_Call) RunAndReturn(run func(ctx context.Context, id storage.SubscriptionID) ([]*storage.Member, error)) *MockMembers_ListBySubscription_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListBySubscriptions provides a mock function for the type MockMembers
+func (_mock *MockMembers) ListBySubscriptions(ctx context.Context, ids []storage.SubscriptionID) ([]*storage.Member, error) {
+	ret := _mock.Called(ctx, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListBySubscriptions")
+	}
+
+	var r0 []*storage.Member
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []storage.SubscriptionID) ([]*storage.Member, error)); ok {
+		return returnFunc(ctx, ids)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []storage.SubscriptionID) []*storage.Member); ok {
+		r0 = returnFunc(ctx, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*storage.Member)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, []storage.SubscriptionID) error); ok {
+		r1 = returnFunc(ctx, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockMembers_ListBySubscriptions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListBySubscriptions'
+type MockMembers_ListBySubscriptions_Call struct {
+	*mock.Call
+}
+
+// ListBySubscriptions is a helper method to define mock.On call
+//   - ctx context.Context
+//   - ids []storage.SubscriptionID
+func (_e *MockMembers_Expecter) ListBySubscriptions(ctx interface{}, ids interface{}) *MockMembers_ListBySubscriptions_Call {
+	return &MockMembers_ListBySubscriptions_Call{Call: _e.mock.On("ListBySubscriptions", ctx, ids)}
+}
+
+func (_c *MockMembers_ListBySubscriptions_Call) Run(run func(ctx context.Context, ids []storage.SubscriptionID)) *MockMembers_ListBySubscriptions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 []storage.SubscriptionID
+		if args[1] != nil {
+			arg1 = args[1].([]storage.SubscriptionID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockMembers_ListBySubscriptions_Call) Return(members []*storage.Member, err error) *MockMembers_ListBySubscriptions_Call {
+	_c.Call.Return(members, err)
+	return _c
+}
+
+func (_c *MockMembers_ListBySubscriptions_Call) RunAndReturn(run func(ctx context.Context, ids []storage.SubscriptionID) ([]*storage.Member, error)) *MockMembers_ListBySubscriptions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Remove provides a mock function for the type MockMembers
+func (_mock *MockMembers) Remove(ctx context.Context, id storage.SubscriptionID, userID storage.UserID) error {
+	ret := _mock.Called(ctx, id, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Remove")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, storage.SubscriptionID, storage.UserID) error); ok {
+		r0 = returnFunc(ctx, id, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockMembers_Remove_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Remove'
+type MockMembers_Remove_Call struct {
+	*mock.Call
+}
+
+// Remove is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id storage.SubscriptionID
+//   - userID storage.UserID
+func (_e *MockMembers_Expecter) Remove(ctx interface{}, id interface{}, userID interface{}) *MockMembers_Remove_Call {
+	return &MockMembers_Remove_Call{Call: _e.mock.On("Remove", ctx, id, userID)}
+}
+
+func (_c *MockMembers_Remove_Call) Run(run func(ctx context.Context, id storage.SubscriptionID, userID storage.UserID)) *MockMembers_Remove_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 storage.SubscriptionID
+		if args[1] != nil {
+			arg1 = args[1].(storage.SubscriptionID)
+		}
+		var arg2 storage.UserID
+		if args[2] != nil {
+			arg2 = args[2].(storage.UserID)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockMembers_Remove_Call) Return(err error) *MockMembers_Remove_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockMembers_Remove_Call) RunAndReturn(run func(ctx context.Context, id storage.SubscriptionID, userID storage.UserID) error) *MockMembers_Remove_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockServices creates a new instance of MockServices. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockServices(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockServices {
+	mock := &MockServices{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockServices is an autogenerated mock type for the Services type
+type MockServices struct {
+	mock.Mock
+}
+
+type MockServices_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockServices) EXPECT() *MockServices_Expecter {
+	return &MockServices_Expecter{mock: &_m.Mock}
+}
+
+// Create provides a mock function for the type MockServices
+func (_mock *MockServices) Create(ctx context.Context, svc *storage.Service) (storage.ServiceID, error) {
+	ret := _mock.Called(ctx, svc)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 storage.ServiceID
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *storage.Service) (storage.ServiceID, error)); ok {
+		return returnFunc(ctx, svc)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *storage.Service) storage.ServiceID); ok {
+		r0 = returnFunc(ctx, svc)
+	} else {
+		r0 = ret.Get(0).(storage.ServiceID)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *storage.Service) error); ok {
+		r1 = returnFunc(ctx, svc)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockServices_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockServices_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - ctx context.Context
+//   - svc *storage.Service
+func (_e *MockServices_Expecter) Create(ctx interface{}, svc interface{}) *MockServices_Create_Call {
+	return &MockServices_Create_Call{Call: _e.mock.On("Create", ctx, svc)}
+}
+
+func (_c *MockServices_Create_Call) Run(run func(ctx context.Context, svc *storage.Service)) *MockServices_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *storage.Service
+		if args[1] != nil {
+			arg1 = args[1].(*storage.Service)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockServices_Create_Call) Return(id storage.ServiceID, err error) *MockServices_Create_Call {
+	_c.Call.Return(id, err)
+	return _c
+}
+
+func (_c *MockServices_Create_Call) RunAndReturn(run func(ctx context.Context, svc *storage.Service) (storage.ServiceID, error)) *MockServices_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteByID provides a mock function for the type MockServices
+func (_mock *MockServices) DeleteByID(ctx context.Context, id storage.ServiceID) error {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteByID")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, storage.ServiceID) error); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockServices_DeleteByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteByID'
+type MockServices_DeleteByID_Call struct {
+	*mock.Call
+}
+
+// DeleteByID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id storage.ServiceID
+func (_e *MockServices_Expecter) DeleteByID(ctx interface{}, id interface{}) *MockServices_DeleteByID_Call {
+	return &MockServices_DeleteByID_Call{Call: _e.mock.On("DeleteByID", ctx, id)}
+}
+
+func (_c *MockServices_DeleteByID_Call) Run(run func(ctx context.Context, id storage.ServiceID)) *MockServices_DeleteByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 storage.ServiceID
+		if args[1] != nil {
+			arg1 = args[1].(storage.ServiceID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockServices_DeleteByID_Call) Return(err error) *MockServices_DeleteByID_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockServices_DeleteByID_Call) RunAndReturn(run func(ctx context.Context, id storage.ServiceID) error) *MockServices_DeleteByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByID provides a mock function for the type MockServices
+func (_mock *MockServices) GetByID(ctx context.Context, id storage.ServiceID) (*storage.Service, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByID")
+	}
+
+	var r0 *storage.Service
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, storage.ServiceID) (*storage.Service, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, storage.ServiceID) *storage.Service); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*storage.Service)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, storage.ServiceID) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockServices_GetByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByID'
+type MockServices_GetByID_Call struct {
+	*mock.Call
+}
+
+// GetByID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id storage.ServiceID
+func (_e *MockServices_Expecter) GetByID(ctx interface{}, id interface{}) *MockServices_GetByID_Call {
+	return &MockServices_GetByID_Call{Call: _e.mock.On("GetByID", ctx, id)}
+}
+
+func (_c *MockServices_GetByID_Call) Run(run func(ctx context.Context, id storage.ServiceID)) *MockServices_GetByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 storage.ServiceID
+		if args[1] != nil {
+			arg1 = args[1].(storage.ServiceID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockServices_GetByID_Call) Return(svc *storage.Service, err error) *MockServices_GetByID_Call {
+	_c.Call.Return(svc, err)
+	return _c
+}
+
+func (_c *MockServices_GetByID_Call) RunAndReturn(run func(ctx context.Context, id storage.ServiceID) (*storage.Service, error)) *MockServices_GetByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByKey provides a mock function for the type MockServices
+func (_mock *MockServices) GetByKey(ctx context.Context, key string) (*storage.Service, error) {
+	ret := _mock.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByKey")
+	}
+
+	var r0 *storage.Service
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*storage.Service, error)); ok {
+		return returnFunc(ctx, key)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *storage.Service); ok {
+		r0 = returnFunc(ctx, key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*storage.Service)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockServices_GetByKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByKey'
+type MockServices_GetByKey_Call struct {
+	*mock.Call
+}
+
+// GetByKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+func (_e *MockServices_Expecter) GetByKey(ctx interface{}, key interface{}) *MockServices_GetByKey_Call {
+	return &MockServices_GetByKey_Call{Call: _e.mock.On("GetByKey", ctx, key)}
+}
+
+func (_c *MockServices_GetByKey_Call) Run(run func(ctx context.Context, key string)) *MockServices_GetByKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockServices_GetByKey_Call) Return(svc *storage.Service, err error) *MockServices_GetByKey_Call {
+	_c.Call.Return(svc, err)
+	return _c
+}
+
+func (_c *MockServices_GetByKey_Call) RunAndReturn(run func(ctx context.Context, key string) (*storage.Service, error)) *MockServices_GetByKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// List provides a mock function for the type MockServices
+func (_mock *MockServices) List(ctx context.Context) ([]*storage.Service, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []*storage.Service
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]*storage.Service, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) []*storage.Service); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*storage.Service)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockServices_List_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'List'
+type MockServices_List_Call struct {
+	*mock.Call
+}
+
+// List is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockServices_Expecter) List(ctx interface{}) *MockServices_List_Call {
+	return &MockServices_List_Call{Call: _e.mock.On("List", ctx)}
+}
+
+func (_c *MockServices_List_Call) Run(run func(ctx context.Context)) *MockServices_List_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockServices_List_Call) Return(svcs []*storage.Service, err error) *MockServices_List_Call {
+	_c.Call.Return(svcs, err)
+	return _c
+}
+
+func (_c *MockServices_List_Call) RunAndReturn(run func(ctx context.Context) ([]*storage.Service, error)) *MockServices_List_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Update provides a mock function for the type MockServices
+func (_mock *MockServices) Update(ctx context.Context, svc *storage.Service) error {
+	ret := _mock.Called(ctx, svc)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *storage.Service) error); ok {
+		r0 = returnFunc(ctx, svc)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockServices_Update_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Update'
+type MockServices_Update_Call struct {
+	*mock.Call
+}
+
+// Update is a helper method to define mock.On call
+//   - ctx context.Context
+//   - svc *storage.Service
+func (_e *MockServices_Expecter) Update(ctx interface{}, svc interface{}) *MockServices_Update_Call {
+	return &MockServices_Update_Call{Call: _e.mock.On("Update", ctx, svc)}
+}
+
+func (_c *MockServices_Update_Call) Run(run func(ctx context.Context, svc *storage.Service)) *MockServices_Update_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *storage.Service
+		if args[1] != nil {
+			arg1 = args[1].(*storage.Service)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockServices_Update_Call) Return(err error) *MockServices_Update_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockServices_Update_Call) RunAndReturn(run func(ctx context.Context, svc *storage.Service) error) *MockServices_Update_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockSubscriptions creates a new instance of MockSubscriptions. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockSubscriptions(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockSubscriptions {
+	mock := &MockSubscriptions{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockSubscriptions is an autogenerated mock type for the Subscriptions type
+type MockSubscriptions struct {
+	mock.Mock
+}
+
+type MockSubscriptions_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockSubscriptions) EXPECT() *MockSubscriptions_Expecter {
+	return &MockSubscriptions_Expecter{mock: &_m.Mock}
+}
+
+// Create provides a mock function for the type MockSubscriptions
+func (_mock *MockSubscriptions) Create(ctx context.Context, sub *storage.Subscription) (storage.SubscriptionID, error) {
+	ret := _mock.Called(ctx, sub)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 storage.SubscriptionID
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *storage.Subscription) (storage.SubscriptionID, error)); ok {
+		return returnFunc(ctx, sub)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *storage.Subscription) storage.SubscriptionID); ok {
+		r0 = returnFunc(ctx, sub)
+	} else {
+		r0 = ret.Get(0).(storage.SubscriptionID)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *storage.Subscription) error); ok {
+		r1 = returnFunc(ctx, sub)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockSubscriptions_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockSubscriptions_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - ctx context.Context
+//   - sub *storage.Subscription
+func (_e *MockSubscriptions_Expecter) Create(ctx interface{}, sub interface{}) *MockSubscriptions_Create_Call {
+	return &MockSubscriptions_Create_Call{Call: _e.mock.On("Create", ctx, sub)}
+}
+
+func (_c *MockSubscriptions_Create_Call) Run(run func(ctx context.Context, sub *storage.Subscription)) *MockSubscriptions_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *storage.Subscription
+		if args[1] != nil {
+			arg1 = args[1].(*storage.Subscription)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockSubscriptions_Create_Call) Return(id storage.SubscriptionID, err error) *MockSubscriptions_Create_Call {
+	_c.Call.Return(id, err)
+	return _c
+}
+
+func (_c *MockSubscriptions_Create_Call) RunAndReturn(run func(ctx context.Context, sub *storage.Subscription) (storage.SubscriptionID, error)) *MockSubscriptions_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateMany provides a mock function for the type MockSubscriptions
+func (_mock *MockSubscriptions) CreateMany(ctx context.Context, subs []*storage.Subscription) ([]storage.SubscriptionID, error) {
+	ret := _mock.Called(ctx, subs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateMany")
+	}
+
+	var r0 []storage.SubscriptionID
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []*storage.Subscription) ([]storage.SubscriptionID, error)); ok {
+		return returnFunc(ctx, subs)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []*storage.Subscription) []storage.SubscriptionID); ok {
+		r0 = returnFunc(ctx, subs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]storage.SubscriptionID)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, []*storage.Subscription) error); ok {
+		r1 = returnFunc(ctx, subs)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockSubscriptions_CreateMany_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateMany'
+type MockSubscriptions_CreateMany_Call struct {
+	*mock.Call
+}
+
+// CreateMany is a helper method to define mock.On call
+//   - ctx context.Context
+//   - subs []*storage.Subscription
+func (_e *MockSubscriptions_Expecter) CreateMany(ctx interface{}, subs interface{}) *MockSubscriptions_CreateMany_Call {
+	return &MockSubscriptions_CreateMany_Call{Call: _e.mock.On("CreateMany", ctx, subs)}
+}
+
+func (_c *MockSubscriptions_CreateMany_Call) Run(run func(ctx context.Context, subs []*storage.Subscription)) *MockSubscriptions_CreateMany_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 []*storage.Subscription
+		if args[1] != nil {
+			arg1 = args[1].([]*storage.Subscription)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockSubscriptions_CreateMany_Call) Return(ids []storage.SubscriptionID, err error) *MockSubscriptions_CreateMany_Call {
+	_c.Call.Return(ids, err)
+	return _c
+}
+
+func (_c *MockSubscriptions_CreateMany_Call) RunAndReturn(run func(ctx context.Context, subs []*storage.Subscription) ([]storage.SubscriptionID, error)) *MockSubscriptions_CreateMany_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteByID provides a mock function for the type MockSubscriptions
+func (_mock *MockSubscriptions) DeleteByID(ctx context.Context, id storage.SubscriptionID) error {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteByID")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, storage.SubscriptionID) error); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockSubscriptions_DeleteByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteByID'
+type MockSubscriptions_DeleteByID_Call struct {
+	*mock.Call
+}
+
+// DeleteByID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id storage.SubscriptionID
+func (_e *MockSubscriptions_Expecter) DeleteByID(ctx interface{}, id interface{}) *MockSubscriptions_DeleteByID_Call {
+	return &MockSubscriptions_DeleteByID_Call{Call: _e.mock.On("DeleteByID", ctx, id)}
+}
+
+func (_c *MockSubscriptions_DeleteByID_Call) Run(run func(ctx context.Context, id storage.SubscriptionID)) *MockSubscriptions_DeleteByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 storage.SubscriptionID
+		if args[1] != nil {
+			arg1 = args[1].(storage.SubscriptionID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockSubscriptions_DeleteByID_Call) Return(err error) *MockSubscriptions_DeleteByID_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockSubscriptions_DeleteByID_Call) RunAndReturn(run func(ctx context.Context, id storage.SubscriptionID) error) *MockSubscriptions_DeleteByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteMany provides a mock function for the type MockSubscriptions
+func (_mock *MockSubscriptions) DeleteMany(ctx context.Context, ids []storage.SubscriptionID) ([]storage.SubscriptionID, error) {
+	ret := _mock.Called(ctx, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteMany")
+	}
+
+	var r0 []storage.SubscriptionID
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []storage.SubscriptionID) ([]storage.SubscriptionID, error)); ok {
+		return returnFunc(ctx, ids)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []storage.SubscriptionID) []storage.SubscriptionID); ok {
+		r0 = returnFunc(ctx, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]storage.SubscriptionID)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, []storage.SubscriptionID) error); ok {
+		r1 = returnFunc(ctx, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockSubscriptions_DeleteMany_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteMany'
+type MockSubscriptions_DeleteMany_Call struct {
+	*mock.Call
+}
+
+// DeleteMany is a helper method to define mock.On call
+//   - ctx context.Context
+//   - ids []storage.SubscriptionID
+func (_e *MockSubscriptions_Expecter) DeleteMany(ctx interface{}, ids interface{}) *MockSubscriptions_DeleteMany_Call {
+	return &MockSubscriptions_DeleteMany_Call{Call: _e.mock.On("DeleteMany", ctx, ids)}
+}
+
+func (_c *MockSubscriptions_DeleteMany_Call) Run(run func(ctx context.Context, ids []storage.SubscriptionID)) *MockSubscriptions_DeleteMany_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 []storage.SubscriptionID
+		if args[1] != nil {
+			arg1 = args[1].([]storage.SubscriptionID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockSubscriptions_DeleteMany_Call) Return(deleted []storage.SubscriptionID, err error) *MockSubscriptions_DeleteMany_Call {
+	_c.Call.Return(deleted, err)
+	return _c
+}
+
+func (_c *MockSubscriptions_DeleteMany_Call) RunAndReturn(run func(ctx context.Context, ids []storage.SubscriptionID) ([]storage.SubscriptionID, error)) *MockSubscriptions_DeleteMany_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByID provides a mock function for the type MockSubscriptions
+func (_mock *MockSubscriptions) GetByID(ctx context.Context, id storage.SubscriptionID) (*storage.Subscription, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByID")
+	}
+
+	var r0 *storage.Subscription
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, storage.SubscriptionID) (*storage.Subscription, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, storage.SubscriptionID) *storage.Subscription); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*storage.Subscription)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, storage.SubscriptionID) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockSubscriptions_GetByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByID'
+type MockSubscriptions_GetByID_Call struct {
+	*mock.Call
+}
+
+// GetByID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id storage.SubscriptionID
+func (_e *MockSubscriptions_Expecter) GetByID(ctx interface{}, id interface{}) *MockSubscriptions_GetByID_Call {
+	return &MockSubscriptions_GetByID_Call{Call: _e.mock.On("GetByID", ctx, id)}
+}
+
+func (_c *MockSubscriptions_GetByID_Call) Run(run func(ctx context.Context, id storage.SubscriptionID)) *MockSubscriptions_GetByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 storage.SubscriptionID
+		if args[1] != nil {
+			arg1 = args[1].(storage.SubscriptionID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockSubscriptions_GetByID_Call) Return(sub *storage.Subscription, err error) *MockSubscriptions_GetByID_Call {
+	_c.Call.Return(sub, err)
+	return _c
+}
+
+func (_c *MockSubscriptions_GetByID_Call) RunAndReturn(run func(ctx context.Context, id storage.SubscriptionID) (*storage.Subscription, error)) *MockSubscriptions_GetByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByIDForUpdate provides a mock function for the type MockSubscriptions
+func (_mock *MockSubscriptions) GetByIDForUpdate(ctx context.Context, id storage.SubscriptionID) (*storage.Subscription, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByIDForUpdate")
+	}
+
+	var r0 *storage.Subscription
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, storage.SubscriptionID) (*storage.Subscription, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, storage.SubscriptionID) *storage.Subscription); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*storage.Subscription)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, storage.SubscriptionID) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockSubscriptions_GetByIDForUpdate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByIDForUpdate'
+type MockSubscriptions_GetByIDForUpdate_Call struct {
+	*mock.Call
+}
+
+// GetByIDForUpdate is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id storage.SubscriptionID
+func (_e *MockSubscriptions_Expecter) GetByIDForUpdate(ctx interface{}, id interface{}) *MockSubscriptions_GetByIDForUpdate_Call {
+	return &MockSubscriptions_GetByIDForUpdate_Call{Call: _e.mock.On("GetByIDForUpdate", ctx, id)}
+}
+
+func (_c *MockSubscriptions_GetByIDForUpdate_Call) Run(run func(ctx context.Context, id storage.SubscriptionID)) *MockSubscriptions_GetByIDForUpdate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 storage.SubscriptionID
+		if args[1] != nil {
+			arg1 = args[1].(storage.SubscriptionID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockSubscriptions_GetByIDForUpdate_Call) Return(sub *storage.Subscription, err error) *MockSubscriptions_GetByIDForUpdate_Call {
+	_c.Call.Return(sub, err)
+	return _c
+}
+
+func (_c *MockSubscriptions_GetByIDForUpdate_Call) RunAndReturn(run func(ctx context.Context, id storage.SubscriptionID) (*storage.Subscription, error)) *MockSubscriptions_GetByIDForUpdate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetManyForUpdate provides a mock function for the type MockSubscriptions
+func (_mock *MockSubscriptions) GetManyForUpdate(ctx context.Context, ids []storage.SubscriptionID) ([]*storage.Subscription, error) {
+	ret := _mock.Called(ctx, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetManyForUpdate")
+	}
+
+	var r0 []*storage.Subscription
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []storage.SubscriptionID) ([]*storage.Subscription, error)); ok {
+		return returnFunc(ctx, ids)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []storage.SubscriptionID) []*storage.Subscription); ok {
+		r0 = returnFunc(ctx, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*storage.Subscription)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, []storage.SubscriptionID) error); ok {
+		r1 = returnFunc(ctx, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockSubscriptions_GetManyForUpdate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetManyForUpdate'
+type MockSubscriptions_GetManyForUpdate_Call struct {
+	*mock.Call
+}
+
+// GetManyForUpdate is a helper method to define mock.On call
+//   - ctx context.Context
+//   - ids []storage.SubscriptionID
+func (_e *MockSubscriptions_Expecter) GetManyForUpdate(ctx interface{}, ids interface{}) *MockSubscriptions_GetManyForUpdate_Call {
+	return &MockSubscriptions_GetManyForUpdate_Call{Call: _e.mock.On("GetManyForUpdate", ctx, ids)}
+}
+
+func (_c *MockSubscriptions_GetManyForUpdate_Call) Run(run func(ctx context.Context, ids []storage.SubscriptionID)) *MockSubscriptions_GetManyForUpdate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 []storage.SubscriptionID
+		if args[1] != nil {
+			arg1 = args[1].([]storage.SubscriptionID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockSubscriptions_GetManyForUpdate_Call) Return(subs []*storage.Subscription, err error) *MockSubscriptions_GetManyForUpdate_Call {
+	_c.Call.Return(subs, err)
+	return _c
+}
+
+func (_c *MockSubscriptions_GetManyForUpdate_Call) RunAndReturn(run func(ctx context.Context, ids []storage.SubscriptionID) ([]*storage.Subscription, error)) *MockSubscriptions_GetManyForUpdate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Query provides a mock function for the type MockSubscriptions
+func (_mock *MockSubscriptions) Query(ctx context.Context, args *storage.QueryArgs) ([]*storage.Subscription, error) {
+	ret := _mock.Called(ctx, args)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Query")
+	}
+
+	var r0 []*storage.Subscription
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *storage.QueryArgs) ([]*storage.Subscription, error)); ok {
+		return returnFunc(ctx, args)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *storage.QueryArgs) []*storage.Subscription); ok {
+		r0 = returnFunc(ctx, args)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*storage.Subscription)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *storage.QueryArgs) error); ok {
+		r1 = returnFunc(ctx, args)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockSubscriptions_Query_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Query'
+type MockSubscriptions_Query_Call struct {
+	*mock.Call
+}
+
+// Query is a helper method to define mock.On call
+//   - ctx context.Context
+//   - args *storage.QueryArgs
+func (_e *MockSubscriptions_Expecter) Query(ctx interface{}, args interface{}) *MockSubscriptions_Query_Call {
+	return &MockSubscriptions_Query_Call{Call: _e.mock.On("Query", ctx, args)}
+}
+
+func (_c *MockSubscriptions_Query_Call) Run(run func(ctx context.Context, args *storage.QueryArgs)) *MockSubscriptions_Query_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *storage.QueryArgs
+		if args[1] != nil {
+			arg1 = args[1].(*storage.QueryArgs)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockSubscriptions_Query_Call) Return(subs []*storage.Subscription, err error) *MockSubscriptions_Query_Call {
+	_c.Call.Return(subs, err)
+	return _c
+}
+
+func (_c *MockSubscriptions_Query_Call) RunAndReturn(run func(ctx context.Context, args *storage.QueryArgs) ([]*storage.Subscription, error)) *MockSubscriptions_Query_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// QueryEach provides a mock function for the type MockSubscriptions
+func (_mock *MockSubscriptions) QueryEach(ctx context.Context, args *storage.QueryArgs, fn func(sub *storage.Subscription) error) error {
+	ret := _mock.Called(ctx, args, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for QueryEach")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *storage.QueryArgs, func(sub *storage.Subscription) error) error); ok {
+		r0 = returnFunc(ctx, args, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockSubscriptions_QueryEach_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'QueryEach'
+type MockSubscriptions_QueryEach_Call struct {
+	*mock.Call
+}
+
+// QueryEach is a helper method to define mock.On call
+//   - ctx context.Context
+//   - args *storage.QueryArgs
+//   - fn func(sub *storage.Subscription) error
+func (_e *MockSubscriptions_Expecter) QueryEach(ctx interface{}, args interface{}, fn interface{}) *MockSubscriptions_QueryEach_Call {
+	return &MockSubscriptions_QueryEach_Call{Call: _e.mock.On("QueryEach", ctx, args, fn)}
+}
+
+func (_c *MockSubscriptions_QueryEach_Call) Run(run func(ctx context.Context, args *storage.QueryArgs, fn func(sub *storage.Subscription) error)) *MockSubscriptions_QueryEach_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *storage.QueryArgs
+		if args[1] != nil {
+			arg1 = args[1].(*storage.QueryArgs)
+		}
+		var arg2 func(sub *storage.Subscription) error
+		if args[2] != nil {
+			arg2 = args[2].(func(sub *storage.Subscription) error)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockSubscriptions_QueryEach_Call) Return(err error) *MockSubscriptions_QueryEach_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockSubscriptions_QueryEach_Call) RunAndReturn(run func(ctx context.Context, args *storage.QueryArgs, fn func(sub *storage.Subscription) error) error) *MockSubscriptions_QueryEach_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Sum provides a mock function for the type MockSubscriptions
+func (_mock *MockSubscriptions) Sum(ctx context.Context, args *storage.QueryArgs) (storage.Price, error) {
+	ret := _mock.Called(ctx, args)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Sum")
+	}
+
+	var r0 storage.Price
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *storage.QueryArgs) (storage.Price, error)); ok {
+		return returnFunc(ctx, args)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *storage.QueryArgs) storage.Price); ok {
+		r0 = returnFunc(ctx, args)
+	} else {
+		r0 = ret.Get(0).(storage.Price)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *storage.QueryArgs) error); ok {
+		r1 = returnFunc(ctx, args)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockSubscriptions_Sum_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Sum'
+type MockSubscriptions_Sum_Call struct {
+	*mock.Call
+}
+
+// Sum is a helper method to define mock.On call
+//   - ctx context.Context
+//   - args *storage.QueryArgs
+func (_e *MockSubscriptions_Expecter) Sum(ctx interface{}, args interface{}) *MockSubscriptions_Sum_Call {
+	return &MockSubscriptions_Sum_Call{Call: _e.mock.On("Sum", ctx, args)}
+}
+
+func (_c *MockSubscriptions_Sum_Call) Run(run func(ctx context.Context, args *storage.QueryArgs)) *MockSubscriptions_Sum_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *storage.QueryArgs
+		if args[1] != nil {
+			arg1 = args[1].(*storage.QueryArgs)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockSubscriptions_Sum_Call) Return(sum storage.Price, err error) *MockSubscriptions_Sum_Call {
+	_c.Call.Return(sum, err)
+	return _c
+}
+
+func (_c *MockSubscriptions_Sum_Call) RunAndReturn(run func(ctx context.Context, args *storage.QueryArgs) (storage.Price, error)) *MockSubscriptions_Sum_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SumByCategory provides a mock function for the type MockSubscriptions
+func (_mock *MockSubscriptions) SumByCategory(ctx context.Context, args *storage.QueryArgs) ([]*storage.CategorySum, error) {
+	ret := _mock.Called(ctx, args)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SumByCategory")
+	}
+
+	var r0 []*storage.CategorySum
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *storage.QueryArgs) ([]*storage.CategorySum, error)); ok {
+		return returnFunc(ctx, args)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *storage.QueryArgs) []*storage.CategorySum); ok {
+		r0 = returnFunc(ctx, args)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*storage.CategorySum)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *storage.QueryArgs) error); ok {
+		r1 = returnFunc(ctx, args)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockSubscriptions_SumByCategory_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SumByCategory'
+type MockSubscriptions_SumByCategory_Call struct {
+	*mock.Call
+}
 
-	return mock
+// SumByCategory is a helper method to define mock.On call
+//   - ctx context.Context
+//   - args *storage.QueryArgs
+func (_e *MockSubscriptions_Expecter) SumByCategory(ctx interface{}, args interface{}) *MockSubscriptions_SumByCategory_Call {
+	return &MockSubscriptions_SumByCategory_Call{Call: _e.mock.On("SumByCategory", ctx, args)}
 }
 
-// MockSubscriptions is an autogenerated mock type for the Subscriptions type
-type MockSubscriptions struct {
-	mock.Mock
+func (_c *MockSubscriptions_SumByCategory_Call) Run(run func(ctx context.Context, args *storage.QueryArgs)) *MockSubscriptions_SumByCategory_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *storage.QueryArgs
+		if args[1] != nil {
+			arg1 = args[1].(*storage.QueryArgs)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
 }
 
-type MockSubscriptions_Expecter struct {
-	mock *mock.Mock
+func (_c *MockSubscriptions_SumByCategory_Call) Return(sums []*storage.CategorySum, err error) *MockSubscriptions_SumByCategory_Call {
+	_c.Call.Return(sums, err)
+	return _c
 }
 
-func (_m *MockSubscriptions) EXPECT() *MockSubscriptions_Expecter {
-	return &MockSubscriptions_Expecter{mock: &_m.Mock}
+func (_c *MockSubscriptions_SumByCategory_Call) RunAndReturn(run func(ctx context.Context, args *storage.QueryArgs) ([]*storage.CategorySum, error)) *MockSubscriptions_SumByCategory_Call {
+	_c.Call.Return(run)
+	return _c
 }
 
-// Create provides a mock function for the type MockSubscriptions
-func (_mock *MockSubscriptions) Create(ctx context.Context, sub *storage.Subscription) (storage.SubscriptionID, error) {
+// Update provides a mock function for the type MockSubscriptions
+func (_mock *MockSubscriptions) Update(ctx context.Context, sub *storage.Subscription) error {
 	ret := _mock.Called(ctx, sub)
 
 	if len(ret) == 0 {
-		panic("no return value specified for Create")
+		panic("no return value specified for Update")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *storage.Subscription) error); ok {
+		r0 = returnFunc(ctx, sub)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockSubscriptions_Update_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Update'
+type MockSubscriptions_Update_Call struct {
+	*mock.Call
+}
+
+// Update is a helper method to define mock.On call
+//   - ctx context.Context
+//   - sub *storage.Subscription
+func (_e *MockSubscriptions_Expecter) Update(ctx interface{}, sub interface{}) *MockSubscriptions_Update_Call {
+	return &MockSubscriptions_Update_Call{Call: _e.mock.On("Update", ctx, sub)}
+}
+
+func (_c *MockSubscriptions_Update_Call) Run(run func(ctx context.Context, sub *storage.Subscription)) *MockSubscriptions_Update_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *storage.Subscription
+		if args[1] != nil {
+			arg1 = args[1].(*storage.Subscription)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockSubscriptions_Update_Call) Return(err error) *MockSubscriptions_Update_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockSubscriptions_Update_Call) RunAndReturn(run func(ctx context.Context, sub *storage.Subscription) error) *MockSubscriptions_Update_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Upsert provides a mock function for the type MockSubscriptions
+func (_mock *MockSubscriptions) Upsert(ctx context.Context, sub *storage.Subscription) (storage.SubscriptionID, bool, error) {
+	ret := _mock.Called(ctx, sub)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Upsert")
 	}
 
 	var r0 storage.SubscriptionID
-	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *storage.Subscription) (storage.SubscriptionID, error)); ok {
+	var r1 bool
+	var r2 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *storage.Subscription) (storage.SubscriptionID, bool, error)); ok {
 		return returnFunc(ctx, sub)
 	}
 	if returnFunc, ok := ret.Get(0).(func(context.Context, *storage.Subscription) storage.SubscriptionID); ok {
@@ -55,27 +2421,32 @@ func (_mock *MockSubscriptions) Create(ctx context.Context, sub *storage.Subscri
 	} else {
 		r0 = ret.Get(0).(storage.SubscriptionID)
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, *storage.Subscription) error); ok {
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *storage.Subscription) bool); ok {
 		r1 = returnFunc(ctx, sub)
 	} else {
-		r1 = ret.Error(1)
+		r1 = ret.Get(1).(bool)
 	}
-	return r0, r1
+	if returnFunc, ok := ret.Get(2).(func(context.Context, *storage.Subscription) error); ok {
+		r2 = returnFunc(ctx, sub)
+	} else {
+		r2 = ret.Error(2)
+	}
+	return r0, r1, r2
 }
 
-// MockSubscriptions_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
-type MockSubscriptions_Create_Call struct {
+// MockSubscriptions_Upsert_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Upsert'
+type MockSubscriptions_Upsert_Call struct {
 	*mock.Call
 }
 
-// Create is a helper method to define mock.On call
+// Upsert is a helper method to define mock.On call
 //   - ctx context.Context
 //   - sub *storage.Subscription
-func (_e *MockSubscriptions_Expecter) Create(ctx interface{}, sub interface{}) *MockSubscriptions_Create_Call {
-	return &MockSubscriptions_Create_Call{Call: _e.mock.On("Create", ctx, sub)}
+func (_e *MockSubscriptions_Expecter) Upsert(ctx interface{}, sub interface{}) *MockSubscriptions_Upsert_Call {
+	return &MockSubscriptions_Upsert_Call{Call: _e.mock.On("Upsert", ctx, sub)}
 }
 
-func (_c *MockSubscriptions_Create_Call) Run(run func(ctx context.Context, sub *storage.Subscription)) *MockSubscriptions_Create_Call {
+func (_c *MockSubscriptions_Upsert_Call) Run(run func(ctx context.Context, sub *storage.Subscription)) *MockSubscriptions_Upsert_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -93,46 +2464,75 @@ func (_c *MockSubscriptions_Create_Call) Run(run func(ctx context.Context, sub *
 	return _c
 }
 
-func (_c *MockSubscriptions_Create_Call) Return(id storage.SubscriptionID, err error) *MockSubscriptions_Create_Call {
-	_c.Call.Return(id, err)
+func (_c *MockSubscriptions_Upsert_Call) Return(id storage.SubscriptionID, created bool, err error) *MockSubscriptions_Upsert_Call {
+	_c.Call.Return(id, created, err)
 	return _c
 }
 
-func (_c *MockSubscriptions_Create_Call) RunAndReturn(run func(ctx context.Context, sub *storage.Subscription) (storage.SubscriptionID, error)) *MockSubscriptions_Create_Call {
+func (_c *MockSubscriptions_Upsert_Call) RunAndReturn(run func(ctx context.Context, sub *storage.Subscription) (storage.SubscriptionID, bool, error)) *MockSubscriptions_Upsert_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// DeleteByID provides a mock function for the type MockSubscriptions
-func (_mock *MockSubscriptions) DeleteByID(ctx context.Context, id storage.SubscriptionID) error {
-	ret := _mock.Called(ctx, id)
+// NewMockTags creates a new instance of MockTags. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockTags(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockTags {
+	mock := &MockTags{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockTags is an autogenerated mock type for the Tags type
+type MockTags struct {
+	mock.Mock
+}
+
+type MockTags_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockTags) EXPECT() *MockTags_Expecter {
+	return &MockTags_Expecter{mock: &_m.Mock}
+}
+
+// AddToSubscription provides a mock function for the type MockTags
+func (_mock *MockTags) AddToSubscription(ctx context.Context, id storage.SubscriptionID, userID storage.UserID, names []string) error {
+	ret := _mock.Called(ctx, id, userID, names)
 
 	if len(ret) == 0 {
-		panic("no return value specified for DeleteByID")
+		panic("no return value specified for AddToSubscription")
 	}
 
 	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, storage.SubscriptionID) error); ok {
-		r0 = returnFunc(ctx, id)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, storage.SubscriptionID, storage.UserID, []string) error); ok {
+		r0 = returnFunc(ctx, id, userID, names)
 	} else {
 		r0 = ret.Error(0)
 	}
 	return r0
 }
 
-// MockSubscriptions_DeleteByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteByID'
-type MockSubscriptions_DeleteByID_Call struct {
+// MockTags_AddToSubscription_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddToSubscription'
+type MockTags_AddToSubscription_Call struct {
 	*mock.Call
 }
 
-// DeleteByID is a helper method to define mock.On call
+// AddToSubscription is a helper method to define mock.On call
 //   - ctx context.Context
 //   - id storage.SubscriptionID
-func (_e *MockSubscriptions_Expecter) DeleteByID(ctx interface{}, id interface{}) *MockSubscriptions_DeleteByID_Call {
-	return &MockSubscriptions_DeleteByID_Call{Call: _e.mock.On("DeleteByID", ctx, id)}
+//   - userID storage.UserID
+//   - names []string
+func (_e *MockTags_Expecter) AddToSubscription(ctx interface{}, id interface{}, userID interface{}, names interface{}) *MockTags_AddToSubscription_Call {
+	return &MockTags_AddToSubscription_Call{Call: _e.mock.On("AddToSubscription", ctx, id, userID, names)}
 }
 
-func (_c *MockSubscriptions_DeleteByID_Call) Run(run func(ctx context.Context, id storage.SubscriptionID)) *MockSubscriptions_DeleteByID_Call {
+func (_c *MockTags_AddToSubscription_Call) Run(run func(ctx context.Context, id storage.SubscriptionID, userID storage.UserID, names []string)) *MockTags_AddToSubscription_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -142,42 +2542,52 @@ func (_c *MockSubscriptions_DeleteByID_Call) Run(run func(ctx context.Context, i
 		if args[1] != nil {
 			arg1 = args[1].(storage.SubscriptionID)
 		}
+		var arg2 storage.UserID
+		if args[2] != nil {
+			arg2 = args[2].(storage.UserID)
+		}
+		var arg3 []string
+		if args[3] != nil {
+			arg3 = args[3].([]string)
+		}
 		run(
 			arg0,
 			arg1,
+			arg2,
+			arg3,
 		)
 	})
 	return _c
 }
 
-func (_c *MockSubscriptions_DeleteByID_Call) Return(err error) *MockSubscriptions_DeleteByID_Call {
+func (_c *MockTags_AddToSubscription_Call) Return(err error) *MockTags_AddToSubscription_Call {
 	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *MockSubscriptions_DeleteByID_Call) RunAndReturn(run func(ctx context.Context, id storage.SubscriptionID) error) *MockSubscriptions_DeleteByID_Call {
+func (_c *MockTags_AddToSubscription_Call) RunAndReturn(run func(ctx context.Context, id storage.SubscriptionID, userID storage.UserID, names []string) error) *MockTags_AddToSubscription_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetByID provides a mock function for the type MockSubscriptions
-func (_mock *MockSubscriptions) GetByID(ctx context.Context, id storage.SubscriptionID) (*storage.Subscription, error) {
+// ListBySubscription provides a mock function for the type MockTags
+func (_mock *MockTags) ListBySubscription(ctx context.Context, id storage.SubscriptionID) ([]*storage.Tag, error) {
 	ret := _mock.Called(ctx, id)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetByID")
+		panic("no return value specified for ListBySubscription")
 	}
 
-	var r0 *storage.Subscription
+	var r0 []*storage.Tag
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, storage.SubscriptionID) (*storage.Subscription, error)); ok {
+	if returnFunc, ok := ret.Get(0).(func(context.Context, storage.SubscriptionID) ([]*storage.Tag, error)); ok {
 		return returnFunc(ctx, id)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, storage.SubscriptionID) *storage.Subscription); ok {
+	if returnFunc, ok := ret.Get(0).(func(context.Context, storage.SubscriptionID) []*storage.Tag); ok {
 		r0 = returnFunc(ctx, id)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*storage.Subscription)
+			r0 = ret.Get(0).([]*storage.Tag)
 		}
 	}
 	if returnFunc, ok := ret.Get(1).(func(context.Context, storage.SubscriptionID) error); ok {
@@ -188,19 +2598,19 @@ func (_mock *MockSubscriptions) GetByID(ctx context.Context, id storage.Subscrip
 	return r0, r1
 }
 
-// MockSubscriptions_GetByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByID'
-type MockSubscriptions_GetByID_Call struct {
+// MockTags_ListBySubscription_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListBySubscription'
+type MockTags_ListBySubscription_Call struct {
 	*mock.Call
 }
 
-// GetByID is a helper method to define mock.On call
+// ListBySubscription is a helper method to define mock.On call
 //   - ctx context.Context
 //   - id storage.SubscriptionID
-func (_e *MockSubscriptions_Expecter) GetByID(ctx interface{}, id interface{}) *MockSubscriptions_GetByID_Call {
-	return &MockSubscriptions_GetByID_Call{Call: _e.mock.On("GetByID", ctx, id)}
+func (_e *MockTags_Expecter) ListBySubscription(ctx interface{}, id interface{}) *MockTags_ListBySubscription_Call {
+	return &MockTags_ListBySubscription_Call{Call: _e.mock.On("ListBySubscription", ctx, id)}
 }
 
-func (_c *MockSubscriptions_GetByID_Call) Run(run func(ctx context.Context, id storage.SubscriptionID)) *MockSubscriptions_GetByID_Call {
+func (_c *MockTags_ListBySubscription_Call) Run(run func(ctx context.Context, id storage.SubscriptionID)) *MockTags_ListBySubscription_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -218,65 +2628,65 @@ func (_c *MockSubscriptions_GetByID_Call) Run(run func(ctx context.Context, id s
 	return _c
 }
 
-func (_c *MockSubscriptions_GetByID_Call) Return(sub *storage.Subscription, err error) *MockSubscriptions_GetByID_Call {
-	_c.Call.Return(sub, err)
+func (_c *MockTags_ListBySubscription_Call) Return(tags []*storage.Tag, err error) *MockTags_ListBySubscription_Call {
+	_c.Call.Return(tags, err)
 	return _c
 }
 
-func (_c *MockSubscriptions_GetByID_Call) RunAndReturn(run func(ctx context.Context, id storage.SubscriptionID) (*storage.Subscription, error)) *MockSubscriptions_GetByID_Call {
+func (_c *MockTags_ListBySubscription_Call) RunAndReturn(run func(ctx context.Context, id storage.SubscriptionID) ([]*storage.Tag, error)) *MockTags_ListBySubscription_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// Query provides a mock function for the type MockSubscriptions
-func (_mock *MockSubscriptions) Query(ctx context.Context, args *storage.QueryArgs) ([]*storage.Subscription, error) {
-	ret := _mock.Called(ctx, args)
+// ListByUser provides a mock function for the type MockTags
+func (_mock *MockTags) ListByUser(ctx context.Context, userID storage.UserID) ([]*storage.Tag, error) {
+	ret := _mock.Called(ctx, userID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for Query")
+		panic("no return value specified for ListByUser")
 	}
 
-	var r0 []*storage.Subscription
+	var r0 []*storage.Tag
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *storage.QueryArgs) ([]*storage.Subscription, error)); ok {
-		return returnFunc(ctx, args)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, storage.UserID) ([]*storage.Tag, error)); ok {
+		return returnFunc(ctx, userID)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *storage.QueryArgs) []*storage.Subscription); ok {
-		r0 = returnFunc(ctx, args)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, storage.UserID) []*storage.Tag); ok {
+		r0 = returnFunc(ctx, userID)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*storage.Subscription)
+			r0 = ret.Get(0).([]*storage.Tag)
 		}
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, *storage.QueryArgs) error); ok {
-		r1 = returnFunc(ctx, args)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, storage.UserID) error); ok {
+		r1 = returnFunc(ctx, userID)
 	} else {
 		r1 = ret.Error(1)
 	}
 	return r0, r1
 }
 
-// MockSubscriptions_Query_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Query'
-type MockSubscriptions_Query_Call struct {
+// MockTags_ListByUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListByUser'
+type MockTags_ListByUser_Call struct {
 	*mock.Call
 }
 
-// Query is a helper method to define mock.On call
+// ListByUser is a helper method to define mock.On call
 //   - ctx context.Context
-//   - args *storage.QueryArgs
-func (_e *MockSubscriptions_Expecter) Query(ctx interface{}, args interface{}) *MockSubscriptions_Query_Call {
-	return &MockSubscriptions_Query_Call{Call: _e.mock.On("Query", ctx, args)}
+//   - userID storage.UserID
+func (_e *MockTags_Expecter) ListByUser(ctx interface{}, userID interface{}) *MockTags_ListByUser_Call {
+	return &MockTags_ListByUser_Call{Call: _e.mock.On("ListByUser", ctx, userID)}
 }
 
-func (_c *MockSubscriptions_Query_Call) Run(run func(ctx context.Context, args *storage.QueryArgs)) *MockSubscriptions_Query_Call {
+func (_c *MockTags_ListByUser_Call) Run(run func(ctx context.Context, userID storage.UserID)) *MockTags_ListByUser_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
 			arg0 = args[0].(context.Context)
 		}
-		var arg1 *storage.QueryArgs
+		var arg1 storage.UserID
 		if args[1] != nil {
-			arg1 = args[1].(*storage.QueryArgs)
+			arg1 = args[1].(storage.UserID)
 		}
 		run(
 			arg0,
@@ -286,120 +2696,144 @@ func (_c *MockSubscriptions_Query_Call) Run(run func(ctx context.Context, args *
 	return _c
 }
 
-func (_c *MockSubscriptions_Query_Call) Return(subs []*storage.Subscription, err error) *MockSubscriptions_Query_Call {
-	_c.Call.Return(subs, err)
+func (_c *MockTags_ListByUser_Call) Return(tags []*storage.Tag, err error) *MockTags_ListByUser_Call {
+	_c.Call.Return(tags, err)
 	return _c
 }
 
-func (_c *MockSubscriptions_Query_Call) RunAndReturn(run func(ctx context.Context, args *storage.QueryArgs) ([]*storage.Subscription, error)) *MockSubscriptions_Query_Call {
+func (_c *MockTags_ListByUser_Call) RunAndReturn(run func(ctx context.Context, userID storage.UserID) ([]*storage.Tag, error)) *MockTags_ListByUser_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// Sum provides a mock function for the type MockSubscriptions
-func (_mock *MockSubscriptions) Sum(ctx context.Context, args *storage.QueryArgs) (storage.Price, error) {
-	ret := _mock.Called(ctx, args)
+// RemoveFromSubscription provides a mock function for the type MockTags
+func (_mock *MockTags) RemoveFromSubscription(ctx context.Context, id storage.SubscriptionID, name string) error {
+	ret := _mock.Called(ctx, id, name)
 
 	if len(ret) == 0 {
-		panic("no return value specified for Sum")
+		panic("no return value specified for RemoveFromSubscription")
 	}
 
-	var r0 storage.Price
-	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *storage.QueryArgs) (storage.Price, error)); ok {
-		return returnFunc(ctx, args)
-	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *storage.QueryArgs) storage.Price); ok {
-		r0 = returnFunc(ctx, args)
-	} else {
-		r0 = ret.Get(0).(storage.Price)
-	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, *storage.QueryArgs) error); ok {
-		r1 = returnFunc(ctx, args)
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, storage.SubscriptionID, string) error); ok {
+		r0 = returnFunc(ctx, id, name)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
-	return r0, r1
+	return r0
 }
 
-// MockSubscriptions_Sum_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Sum'
-type MockSubscriptions_Sum_Call struct {
+// MockTags_RemoveFromSubscription_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RemoveFromSubscription'
+type MockTags_RemoveFromSubscription_Call struct {
 	*mock.Call
 }
 
-// Sum is a helper method to define mock.On call
+// RemoveFromSubscription is a helper method to define mock.On call
 //   - ctx context.Context
-//   - args *storage.QueryArgs
-func (_e *MockSubscriptions_Expecter) Sum(ctx interface{}, args interface{}) *MockSubscriptions_Sum_Call {
-	return &MockSubscriptions_Sum_Call{Call: _e.mock.On("Sum", ctx, args)}
+//   - id storage.SubscriptionID
+//   - name string
+func (_e *MockTags_Expecter) RemoveFromSubscription(ctx interface{}, id interface{}, name interface{}) *MockTags_RemoveFromSubscription_Call {
+	return &MockTags_RemoveFromSubscription_Call{Call: _e.mock.On("RemoveFromSubscription", ctx, id, name)}
 }
 
-func (_c *MockSubscriptions_Sum_Call) Run(run func(ctx context.Context, args *storage.QueryArgs)) *MockSubscriptions_Sum_Call {
+func (_c *MockTags_RemoveFromSubscription_Call) Run(run func(ctx context.Context, id storage.SubscriptionID, name string)) *MockTags_RemoveFromSubscription_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
 			arg0 = args[0].(context.Context)
 		}
-		var arg1 *storage.QueryArgs
+		var arg1 storage.SubscriptionID
 		if args[1] != nil {
-			arg1 = args[1].(*storage.QueryArgs)
+			arg1 = args[1].(storage.SubscriptionID)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
 		}
 		run(
 			arg0,
 			arg1,
+			arg2,
 		)
 	})
 	return _c
 }
 
-func (_c *MockSubscriptions_Sum_Call) Return(sum storage.Price, err error) *MockSubscriptions_Sum_Call {
-	_c.Call.Return(sum, err)
+func (_c *MockTags_RemoveFromSubscription_Call) Return(err error) *MockTags_RemoveFromSubscription_Call {
+	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *MockSubscriptions_Sum_Call) RunAndReturn(run func(ctx context.Context, args *storage.QueryArgs) (storage.Price, error)) *MockSubscriptions_Sum_Call {
+func (_c *MockTags_RemoveFromSubscription_Call) RunAndReturn(run func(ctx context.Context, id storage.SubscriptionID, name string) error) *MockTags_RemoveFromSubscription_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// Update provides a mock function for the type MockSubscriptions
-func (_mock *MockSubscriptions) Update(ctx context.Context, sub *storage.Subscription) error {
-	ret := _mock.Called(ctx, sub)
+// NewMockTransactor creates a new instance of MockTransactor. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockTransactor(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockTransactor {
+	mock := &MockTransactor{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockTransactor is an autogenerated mock type for the Transactor type
+type MockTransactor struct {
+	mock.Mock
+}
+
+type MockTransactor_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockTransactor) EXPECT() *MockTransactor_Expecter {
+	return &MockTransactor_Expecter{mock: &_m.Mock}
+}
+
+// WithTx provides a mock function for the type MockTransactor
+func (_mock *MockTransactor) WithTx(ctx context.Context, fn func(tx storage.Storage) error) error {
+	ret := _mock.Called(ctx, fn)
 
 	if len(ret) == 0 {
-		panic("no return value specified for Update")
+		panic("no return value specified for WithTx")
 	}
 
 	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *storage.Subscription) error); ok {
-		r0 = returnFunc(ctx, sub)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, func(tx storage.Storage) error) error); ok {
+		r0 = returnFunc(ctx, fn)
 	} else {
 		r0 = ret.Error(0)
 	}
 	return r0
 }
 
-// MockSubscriptions_Update_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Update'
-type MockSubscriptions_Update_Call struct {
+// MockTransactor_WithTx_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'WithTx'
+type MockTransactor_WithTx_Call struct {
 	*mock.Call
 }
 
-// Update is a helper method to define mock.On call
+// WithTx is a helper method to define mock.On call
 //   - ctx context.Context
-//   - sub *storage.Subscription
-func (_e *MockSubscriptions_Expecter) Update(ctx interface{}, sub interface{}) *MockSubscriptions_Update_Call {
-	return &MockSubscriptions_Update_Call{Call: _e.mock.On("Update", ctx, sub)}
+//   - fn func(tx storage.Storage) error
+func (_e *MockTransactor_Expecter) WithTx(ctx interface{}, fn interface{}) *MockTransactor_WithTx_Call {
+	return &MockTransactor_WithTx_Call{Call: _e.mock.On("WithTx", ctx, fn)}
 }
 
-func (_c *MockSubscriptions_Update_Call) Run(run func(ctx context.Context, sub *storage.Subscription)) *MockSubscriptions_Update_Call {
+func (_c *MockTransactor_WithTx_Call) Run(run func(ctx context.Context, fn func(tx storage.Storage) error)) *MockTransactor_WithTx_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
 			arg0 = args[0].(context.Context)
 		}
-		var arg1 *storage.Subscription
+		var arg1 func(tx storage.Storage) error
 		if args[1] != nil {
-			arg1 = args[1].(*storage.Subscription)
+			arg1 = args[1].(func(tx storage.Storage) error)
 		}
 		run(
 			arg0,
@@ -409,12 +2843,12 @@ func (_c *MockSubscriptions_Update_Call) Run(run func(ctx context.Context, sub *
 	return _c
 }
 
-func (_c *MockSubscriptions_Update_Call) Return(err error) *MockSubscriptions_Update_Call {
+func (_c *MockTransactor_WithTx_Call) Return(err error) *MockTransactor_WithTx_Call {
 	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *MockSubscriptions_Update_Call) RunAndReturn(run func(ctx context.Context, sub *storage.Subscription) error) *MockSubscriptions_Update_Call {
+func (_c *MockTransactor_WithTx_Call) RunAndReturn(run func(ctx context.Context, fn func(tx storage.Storage) error) error) *MockTransactor_WithTx_Call {
 	_c.Call.Return(run)
 	return _c
 }
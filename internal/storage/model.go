@@ -2,6 +2,7 @@ package storage
 
 import (
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -10,20 +11,123 @@ var ErrNoSuchSubscription = errors.New("no such subscription")
 var ErrSubscriptionAlreadyExists = errors.New("subscription already exists")
 var ErrNoUserID = errors.New("no user is provided or its invalid")
 var ErrNoSubscriptionID = errors.New("no subscription is provided or its invalid")
-var ErrUserSubscriptionPairAlreadyExists = errors.New("user-subscription pair already exists")
+var ErrUserSubscriptionPairAlreadyExists = errors.New("user already has a subscription to the service within the period")
+var ErrNoSuchService = errors.New("no such service")
+var ErrServiceAlreadyExists = errors.New("service with such name or alias already exists")
+var ErrNoSuchCategory = errors.New("no such category")
+var ErrCategoryAlreadyExists = errors.New("category already exists")
+var ErrNoSuchTag = errors.New("no such tag")
+var ErrNoSuchMember = errors.New("no such subscription member")
+var ErrInvalidPrice = errors.New("price must not be negative")
+var ErrNoSuchAPIKey = errors.New("no such api key")
+
+// Generic errors of storages, returned when no specific error applies.
+var ErrAlreadyExists = errors.New("already exists")
+var ErrConflict = errors.New("conflicts with existing data")
+var ErrReferenceNotFound = errors.New("referenced entity does not exist")
+var ErrInvalidValue = errors.New("invalid value")
+var ErrSerialization = errors.New("concurrent update, transaction should be retried")
+var ErrQueryCanceled = errors.New("query canceled")
 
 type UserID = uuid.UUID
 type SubscriptionID = int64
+type ServiceID = int64
+type CategoryID = int64
+type TagID = int64
+type APIKeyID = int64
 type Price int
 
 /* ---- Subscription Type ---- */
 type Subscription struct {
 	ID           SubscriptionID `json:"id" db:"id" swaggerignore:"true"`
 	UserID       UserID         `json:"user_id" db:"user_id" binding:"required"`
-	ServiceName  string         `json:"service_name" db:"service_name" binding:"required"`
-	MonthlyPrice Price          `json:"monthly_price" db:"monthly_price" binding:"required"`
+	ServiceID    *ServiceID     `json:"service_id,omitempty" db:"service_id"`
+	ServiceName  string         `json:"service_name" db:"service_name" binding:"required_without=ServiceID"`
+	CategoryID   *CategoryID    `json:"category_id,omitempty" db:"category_id"`
+	MonthlyPrice Price          `json:"monthly_price" db:"monthly_price" binding:"required_without=ServiceID"`
 	StartDate    Date           `json:"start_date" db:"start_date" binding:"required"`
 	EndDate      Date           `json:"end_date,omitempty,omitzero" db:"end_date"`
+	// TenantID is set by storages from the context, it's never taken from input.
+	TenantID string `json:"-" db:"tenant_id" swaggerignore:"true"`
+}
+
+/* ---- Service Catalog Type ---- */
+// Service is a catalog entry with a canonical name. Subscriptions reference
+// it by ServiceID, while NameKey and AliasKeys hold normalized names used to
+// match free-text service names to the entry.
+type Service struct {
+	ID           ServiceID   `json:"id" db:"id" swaggerignore:"true"`
+	Name         string      `json:"name" db:"name" binding:"required"`
+	NameKey      string      `json:"-" db:"name_key"`
+	Aliases      []string    `json:"aliases" db:"aliases"`
+	AliasKeys    []string    `json:"-" db:"alias_keys"`
+	CategoryID   *CategoryID `json:"category_id,omitempty" db:"category_id"`
+	DefaultPrice Price       `json:"default_price" db:"default_price"`
+}
+
+/* ---- Category Type ---- */
+type Category struct {
+	ID   CategoryID `json:"id" db:"id" swaggerignore:"true"`
+	Name string     `json:"name" db:"name" binding:"required"`
+}
+
+// CategorySum is a row of the cost report grouped by category. Subscriptions
+// without category are reported with nil CategoryID.
+type CategorySum struct {
+	CategoryID *CategoryID `json:"category_id" db:"category_id"`
+	Category   string      `json:"category" db:"category"`
+	Sum        Price       `json:"sum" db:"sum"`
+}
+
+/* ---- Tag Type ---- */
+// Tag is a user-defined label, tags of different users are independent.
+type Tag struct {
+	ID     TagID  `json:"id" db:"id"`
+	UserID UserID `json:"user_id" db:"user_id"`
+	Name   string `json:"name" db:"name"`
+}
+
+/* ---- Subscription Member Type ---- */
+// Member is a user sharing the subscription cost with its owner. Exactly one
+// of SharePercent and ShareAmount is set. The owner pays the rest of the price.
+type Member struct {
+	SubscriptionID SubscriptionID `json:"subscription_id" db:"subscription_id" swaggerignore:"true"`
+	UserID         UserID         `json:"user_id" db:"user_id" binding:"required"`
+	SharePercent   *float64       `json:"share_percent,omitempty" db:"share_percent" example:"50"`
+	ShareAmount    *Price         `json:"share_amount,omitempty" db:"share_amount" example:"200"`
+}
+
+/* ---- Idempotency Record Type ---- */
+// IdempotencyRecord is a response of a mutating request made with the
+// Idempotency-Key header. Keys are unique within the scope, e.g. a user.
+// Status is zero while the first request is in progress.
+type IdempotencyRecord struct {
+	Scope       string    `db:"scope"`
+	Key         string    `db:"key"`
+	Method      string    `db:"method"`
+	Path        string    `db:"path"`
+	RequestHash string    `db:"request_hash"`
+	Status      int       `db:"status"`
+	ContentType string    `db:"content_type"`
+	Body        []byte    `db:"body"`
+	ExpiresAt   time.Time `db:"expires_at"`
+}
+
+/* ---- API Key Type ---- */
+// APIKey is a long-lived machine credential limited by scopes and roles. Only the hash
+// of the key is stored. Keys bound to a user act on its behalf, others act
+// on any user. Keys belong to the tenant they are created in.
+type APIKey struct {
+	ID        APIKeyID   `json:"id" db:"id"`
+	Name      string     `json:"name" db:"name"`
+	Prefix    string     `json:"prefix" db:"prefix"`
+	Hash      string     `json:"-" db:"key_hash"`
+	UserID    *UserID    `json:"user_id,omitempty" db:"user_id"`
+	Scopes    []string   `json:"scopes" db:"scopes"`
+	Roles     []string   `json:"roles" db:"roles"`
+	TenantID  string     `json:"tenant_id" db:"tenant_id"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
 }
 
 /* ---- Query ---- */
@@ -45,6 +149,12 @@ const (
 	FromSubscriptions From = "subscriptions"
 )
 
+// Pseudo-columns, which are not stored in the subscriptions table,
+// but can be used to filter subscriptions.
+const (
+	ColumnTag = "tag"
+)
+
 type Operator string
 
 const (
@@ -0,0 +1,136 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	microservice "github.com/ikotiki/go-rest-api-service-subscriptions"
+	"github.com/rs/zerolog"
+
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/storage"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/tenant"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/pkg/e"
+
+	"github.com/lib/pq"
+)
+
+const apiKeyColumns = `id, name, prefix, key_hash, user_id, scopes, roles, tenant_id, created_at, revoked_at`
+
+type APIKeysStore struct {
+	db dbtx
+}
+
+func NewAPIKeysStore(store *SQLStorage) *APIKeysStore {
+	return &APIKeysStore{db: store.conn()}
+}
+
+// apiKeyRow is a database representation of microservice.APIKey,
+// because driver can't scan arrays into plain slices.
+type apiKeyRow struct {
+	ID        microservice.APIKeyID `db:"id"`
+	Name      string                `db:"name"`
+	Prefix    string                `db:"prefix"`
+	Hash      string                `db:"key_hash"`
+	UserID    *microservice.UserID  `db:"user_id"`
+	Scopes    pq.StringArray        `db:"scopes"`
+	Roles     pq.StringArray        `db:"roles"`
+	TenantID  string                `db:"tenant_id"`
+	CreatedAt time.Time             `db:"created_at"`
+	RevokedAt *time.Time            `db:"revoked_at"`
+}
+
+func (r *apiKeyRow) toAPIKey() *microservice.APIKey {
+	return &microservice.APIKey{
+		ID:        r.ID,
+		Name:      r.Name,
+		Prefix:    r.Prefix,
+		Hash:      r.Hash,
+		UserID:    r.UserID,
+		Scopes:    []string(r.Scopes),
+		Roles:     []string(r.Roles),
+		TenantID:  r.TenantID,
+		CreatedAt: r.CreatedAt,
+		RevokedAt: r.RevokedAt,
+	}
+}
+
+func (s *APIKeysStore) Create(ctx context.Context, key *microservice.APIKey) (id microservice.APIKeyID, err error) {
+	const op = "storage.postgresql.apikeys.create"
+	q := sprintf(`
+		INSERT INTO %s (name, prefix, key_hash, user_id, scopes, roles, tenant_id) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`, TableAPIKeys)
+
+	zerolog.Ctx(ctx).Debug().Str("query", q).Str("name", key.Name).Str("prefix", key.Prefix).Msg(op)
+
+	err = s.db.QueryRowxContext(ctx, q, key.Name, key.Prefix, key.Hash, key.UserID, pq.StringArray(key.Scopes), pq.StringArray(key.Roles),
+		tenant.FromContext(ctx)).Scan(&id)
+	if err != nil {
+		return 0, e.Wrap(op, translateError(err))
+	}
+
+	return id, nil
+}
+
+func (s *APIKeysStore) GetByHash(ctx context.Context, hash string) (key *microservice.APIKey, err error) {
+	const op = "storage.postgresql.apikeys.getbyhash"
+	row := &apiKeyRow{}
+
+	q := sprintf(`SELECT %s FROM %s WHERE key_hash = $1 AND revoked_at IS NULL`, apiKeyColumns, TableAPIKeys)
+
+	zerolog.Ctx(ctx).Debug().Str("query", q).Msg(op)
+
+	err = s.db.GetContext(ctx, row, q, hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, storage.ErrNoSuchAPIKey
+	}
+	if err != nil {
+		return nil, e.Wrap(op, translateError(err))
+	}
+
+	return row.toAPIKey(), nil
+}
+
+func (s *APIKeysStore) Revoke(ctx context.Context, id microservice.APIKeyID) (err error) {
+	const op = "storage.postgresql.apikeys.revoke"
+	q := sprintf(`
+		UPDATE %s SET revoked_at = now() WHERE id = $1 AND tenant_id = $2 AND revoked_at IS NULL
+	`, TableAPIKeys)
+
+	zerolog.Ctx(ctx).Debug().Str("query", q).Int64("id", id).Msg(op)
+
+	res, err := s.db.ExecContext(ctx, q, id, tenant.FromContext(ctx))
+	if err != nil {
+		return e.Wrap(op, translateError(err))
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return e.Wrap(fmt.Sprintf("%s.rows_affected", op), err)
+	}
+	if n == 0 {
+		return e.Wrap(op, storage.ErrNoSuchAPIKey)
+	}
+
+	return nil
+}
+
+func (s *APIKeysStore) List(ctx context.Context) (keys []*microservice.APIKey, err error) {
+	const op = "storage.postgresql.apikeys.list"
+	q := sprintf(`SELECT %s FROM %s WHERE tenant_id = $1 ORDER BY id ASC`, apiKeyColumns, TableAPIKeys)
+
+	zerolog.Ctx(ctx).Debug().Str("query", q).Msg(op)
+
+	rows := []*apiKeyRow{}
+	if err = s.db.SelectContext(ctx, &rows, q, tenant.FromContext(ctx)); err != nil {
+		return nil, e.Wrap(op, translateError(err))
+	}
+
+	keys = make([]*microservice.APIKey, 0, len(rows))
+	for _, row := range rows {
+		keys = append(keys, row.toAPIKey())
+	}
+	return keys, nil
+}
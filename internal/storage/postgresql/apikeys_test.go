@@ -0,0 +1,84 @@
+package postgresql
+
+import (
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/storage"
+)
+
+func TestAPIKeys_GetByHash(t *testing.T) {
+	dbStore, db, mock, err := newMockSQLStorage()
+	if err != nil {
+		t.Fatal("can't crate mock storage", err)
+	}
+	defer db.Close()
+	st := NewAPIKeysStore(dbStore)
+
+	const q = "SELECT id, name, prefix, key_hash, user_id, scopes, roles, tenant_id, created_at, revoked_at FROM api_keys WHERE key_hash = $1 AND revoked_at IS NULL"
+	columns := []string{"id", "name", "prefix", "key_hash", "user_id", "scopes", "roles", "tenant_id", "created_at", "revoked_at"}
+	userID := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
+	createdAt := time.Now()
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    *storage.APIKey
+		wantErr error
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				mock.ExpectQuery(q).WithArgs("hash").WillReturnRows(sqlmock.NewRows(columns).
+					AddRow(1, "billing", "sk_abcdefgh", "hash", userID, "{subscriptions:read,reports:read}", "{viewer}", "acme", createdAt, nil))
+			},
+			want: &storage.APIKey{ID: 1, Name: "billing", Prefix: "sk_abcdefgh", Hash: "hash", UserID: &userID,
+				Scopes: []string{"subscriptions:read", "reports:read"}, Roles: []string{"viewer"}, TenantID: "acme", CreatedAt: createdAt},
+		},
+		{
+			name: "Error (no key)",
+			mock: func() {
+				mock.ExpectQuery(q).WithArgs("hash").WillReturnRows(sqlmock.NewRows(columns))
+			},
+			wantErr: storage.ErrNoSuchAPIKey,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := st.GetByHash(t.Context(), "hash")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAPIKeys_Revoke(t *testing.T) {
+	dbStore, db, mock, err := newMockSQLStorage()
+	if err != nil {
+		t.Fatal("can't crate mock storage", err)
+	}
+	defer db.Close()
+	st := NewAPIKeysStore(dbStore)
+
+	const q = "UPDATE api_keys SET revoked_at = now() WHERE id = $1 AND tenant_id = $2 AND revoked_at IS NULL"
+
+	mock.ExpectExec(q).WithArgs(1, "default").WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, st.Revoke(t.Context(), 1))
+
+	mock.ExpectExec(q).WithArgs(2, "default").WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, st.Revoke(t.Context(), 2), storage.ErrNoSuchAPIKey)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,116 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	microservice "github.com/ikotiki/go-rest-api-service-subscriptions"
+	"github.com/rs/zerolog"
+
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/storage"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/pkg/e"
+)
+
+type CategoriesStore struct {
+	db dbtx
+}
+
+func NewCategoriesStore(store *SQLStorage) *CategoriesStore {
+	return &CategoriesStore{db: store.conn()}
+}
+
+func (s *CategoriesStore) GetByID(ctx context.Context, id microservice.CategoryID) (cat *microservice.Category, err error) {
+	const op = "storage.postgresql.categories.getbyid"
+	cat = &microservice.Category{}
+
+	q := sprintf(`SELECT * FROM %s WHERE id = $1`, TableCategories)
+
+	zerolog.Ctx(ctx).Debug().Str("query", q).Int("id", int(id)).Msg(op)
+
+	err = s.db.GetContext(ctx, cat, q, id)
+	if err == sql.ErrNoRows {
+		return nil, storage.ErrNoSuchCategory
+	}
+	if err != nil {
+		return nil, e.Wrap(op, translateError(err))
+	}
+
+	return cat, nil
+}
+
+func (s *CategoriesStore) Create(ctx context.Context, cat *microservice.Category) (id microservice.CategoryID, err error) {
+	const op = "storage.postgresql.categories.create"
+	q := sprintf(`
+		INSERT INTO %s (name) VALUES ($1)
+		RETURNING id
+	`, TableCategories)
+
+	zerolog.Ctx(ctx).Debug().Str("query", q).Interface("category", cat).Msg(op)
+
+	if err = s.db.QueryRowxContext(ctx, q, cat.Name).Scan(&id); err != nil {
+		return 0, e.Wrap(op, translateError(err))
+	}
+
+	return id, nil
+}
+
+func (s *CategoriesStore) Update(ctx context.Context, cat *microservice.Category) (err error) {
+	const op = "storage.postgresql.categories.update"
+	q := sprintf(`
+		UPDATE %s SET name = $2 WHERE id = $1
+	`, TableCategories)
+
+	zerolog.Ctx(ctx).Debug().Str("query", q).Interface("category", cat).Msg(op)
+
+	res, err := s.db.ExecContext(ctx, q, cat.ID, cat.Name)
+	if err != nil {
+		return e.Wrap(op, translateError(err))
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return e.Wrap(fmt.Sprintf("%s.rows_affected", op), err)
+	}
+	if n == 0 {
+		return e.Wrap(op, storage.ErrNoSuchCategory)
+	}
+
+	return nil
+}
+
+func (s *CategoriesStore) DeleteByID(ctx context.Context, id microservice.CategoryID) (err error) {
+	const op = "storage.postgresql.categories.deletebyid"
+	q := sprintf(`
+		DELETE FROM %s WHERE id = $1
+	`, TableCategories)
+
+	zerolog.Ctx(ctx).Debug().Str("query", q).Int("id", int(id)).Msg(op)
+
+	res, err := s.db.ExecContext(ctx, q, id)
+	if err != nil {
+		return e.Wrap(op, translateError(err))
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return e.Wrap(fmt.Sprintf("%s.rows_affected", op), err)
+	}
+	if n == 0 {
+		return e.Wrap(op, storage.ErrNoSuchCategory)
+	}
+
+	return nil
+}
+
+func (s *CategoriesStore) List(ctx context.Context) (cats []*microservice.Category, err error) {
+	const op = "storage.postgresql.categories.list"
+	q := sprintf(`SELECT * FROM %s ORDER BY name ASC`, TableCategories)
+
+	zerolog.Ctx(ctx).Debug().Str("query", q).Msg(op)
+
+	cats = []*microservice.Category{}
+	err = s.db.SelectContext(ctx, &cats, q)
+	if err != nil {
+		return nil, e.Wrap(op, translateError(err))
+	}
+	return cats, nil
+}
@@ -0,0 +1,77 @@
+package postgresql
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/storage"
+
+	"github.com/lib/pq"
+)
+
+// SQLSTATE codes of errors translated to storage errors.
+// See https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	codeNotNullViolation     pq.ErrorCode = "23502"
+	codeForeignKeyViolation  pq.ErrorCode = "23503"
+	codeUniqueViolation      pq.ErrorCode = "23505"
+	codeCheckViolation       pq.ErrorCode = "23514"
+	codeExclusionViolation   pq.ErrorCode = "23P01"
+	codeSerializationFailure pq.ErrorCode = "40001"
+	codeDeadlockDetected     pq.ErrorCode = "40P01"
+	codeQueryCanceled        pq.ErrorCode = "57014"
+)
+
+// constraintErrors holds errors of constraints meaningful for the domain.
+// Names of unnamed constraints are generated by postgres.
+var constraintErrors = map[string]error{
+	"subscriptions_period_no_overlap":           storage.ErrUserSubscriptionPairAlreadyExists,
+	"subscriptions_open_key":                    storage.ErrUserSubscriptionPairAlreadyExists,
+	"subscriptions_monthly_price_check":         storage.ErrInvalidPrice,
+	"subscriptions_service_id_fkey":             storage.ErrNoSuchService,
+	"subscriptions_category_id_fkey":            storage.ErrNoSuchCategory,
+	"services_name_key_unique":                  storage.ErrServiceAlreadyExists,
+	"service_keys_key_unique":                   storage.ErrServiceAlreadyExists,
+	"services_default_price_check":              storage.ErrInvalidPrice,
+	"services_category_id_fkey":                 storage.ErrNoSuchCategory,
+	"categories_name_unique":                    storage.ErrCategoryAlreadyExists,
+	"subscription_tags_subscription_id_fkey":    storage.ErrNoSuchSubscription,
+	"subscription_members_subscription_id_fkey": storage.ErrNoSuchSubscription,
+	"subscription_members_share_percent_check":  storage.ErrInvalidValue,
+	"subscription_members_share_amount_check":   storage.ErrInvalidValue,
+	"subscription_members_one_share":            storage.ErrInvalidValue,
+	"api_keys_key_hash_unique":                  storage.ErrAlreadyExists,
+}
+
+// translateError maps postgres errors to storage errors by SQLSTATE and
+// the violated constraint, so callers don't depend on the driver and
+// error messages. Other errors are returned as is.
+func translateError(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+
+	var target error
+	switch pqErr.Code {
+	case codeUniqueViolation:
+		target = storage.ErrAlreadyExists
+	case codeExclusionViolation:
+		target = storage.ErrConflict
+	case codeForeignKeyViolation:
+		target = storage.ErrReferenceNotFound
+	case codeCheckViolation, codeNotNullViolation:
+		target = storage.ErrInvalidValue
+	case codeSerializationFailure, codeDeadlockDetected:
+		return fmt.Errorf("%w: %s", storage.ErrSerialization, pqErr.Message)
+	case codeQueryCanceled:
+		return fmt.Errorf("%w: %s", storage.ErrQueryCanceled, pqErr.Message)
+	default:
+		return err
+	}
+
+	if constraintErr, ok := constraintErrors[pqErr.Constraint]; ok {
+		target = constraintErr
+	}
+	return fmt.Errorf("%w: %s", target, pqErr.Message)
+}
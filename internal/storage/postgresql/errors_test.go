@@ -0,0 +1,70 @@
+package postgresql
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/storage"
+)
+
+func Test_translateError(t *testing.T) {
+	other := errors.New("connection refused")
+
+	tests := []struct {
+		name  string
+		input error
+		want  error
+	}{
+		{
+			name:  "Unique (known constraint)",
+			input: &pq.Error{Code: codeUniqueViolation, Constraint: "categories_name_unique"},
+			want:  storage.ErrCategoryAlreadyExists,
+		},
+		{
+			name:  "Unique (unknown constraint)",
+			input: &pq.Error{Code: codeUniqueViolation, Constraint: "tags_user_id_name_unique"},
+			want:  storage.ErrAlreadyExists,
+		},
+		{
+			name:  "Exclusion",
+			input: &pq.Error{Code: codeExclusionViolation, Constraint: "subscriptions_period_no_overlap"},
+			want:  storage.ErrUserSubscriptionPairAlreadyExists,
+		},
+		{
+			name:  "Check (price)",
+			input: &pq.Error{Code: codeCheckViolation, Constraint: "subscriptions_monthly_price_check"},
+			want:  storage.ErrInvalidPrice,
+		},
+		{
+			name:  "Foreign key",
+			input: &pq.Error{Code: codeForeignKeyViolation, Constraint: "subscriptions_service_id_fkey"},
+			want:  storage.ErrNoSuchService,
+		},
+		{
+			name:  "Serialization failure",
+			input: &pq.Error{Code: codeSerializationFailure},
+			want:  storage.ErrSerialization,
+		},
+		{
+			name:  "Query canceled",
+			input: &pq.Error{Code: codeQueryCanceled},
+			want:  storage.ErrQueryCanceled,
+		},
+		{
+			name:  "Other",
+			input: other,
+			want:  other,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, translateError(tt.input), tt.want)
+		})
+	}
+
+	assert.NoError(t, translateError(nil))
+}
@@ -0,0 +1,102 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog"
+
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/storage"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/pkg/e"
+)
+
+const idempotencyColumns = `scope, key, method, path, request_hash, COALESCE(status, 0) AS status, content_type, body, expires_at`
+
+type IdempotencyKeysStore struct {
+	db dbtx
+}
+
+func NewIdempotencyKeysStore(store *SQLStorage) *IdempotencyKeysStore {
+	return &IdempotencyKeysStore{db: store.conn()}
+}
+
+func (s *IdempotencyKeysStore) Acquire(ctx context.Context, rec *storage.IdempotencyRecord) (existing *storage.IdempotencyRecord, err error) {
+	const op = "storage.postgresql.idempotency.acquire"
+	// The conflicting live record is not updated, so nothing is returned.
+	q := sprintf(`
+		INSERT INTO %[1]s (scope, key, method, path, request_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (scope, key) DO UPDATE SET
+			(method, path, request_hash, status, content_type, body, created_at, expires_at) =
+			(EXCLUDED.method, EXCLUDED.path, EXCLUDED.request_hash, NULL, '', NULL, now(), EXCLUDED.expires_at)
+		WHERE %[1]s.expires_at <= now()
+		RETURNING key
+	`, TableIdempotencyKeys)
+
+	zerolog.Ctx(ctx).Debug().Str("query", q).Str("key", rec.Key).Msg(op)
+
+	var key string
+	err = s.db.GetContext(ctx, &key, q, rec.Scope, rec.Key, rec.Method, rec.Path, rec.RequestHash, rec.ExpiresAt)
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, e.Wrap(op, translateError(err))
+	}
+
+	q = sprintf(`SELECT %s FROM %s WHERE scope = $1 AND key = $2`, idempotencyColumns, TableIdempotencyKeys)
+
+	zerolog.Ctx(ctx).Debug().Str("query", q).Str("key", rec.Key).Msg(op)
+
+	existing = &storage.IdempotencyRecord{}
+	if err = s.db.GetContext(ctx, existing, q, rec.Scope, rec.Key); err != nil {
+		return nil, e.Wrap(op, translateError(err))
+	}
+	return existing, nil
+}
+
+func (s *IdempotencyKeysStore) Complete(ctx context.Context, rec *storage.IdempotencyRecord) (err error) {
+	const op = "storage.postgresql.idempotency.complete"
+	q := sprintf(`
+		UPDATE %s SET (status, content_type, body, expires_at) = ($3, $4, $5, $6)
+		WHERE scope = $1 AND key = $2
+	`, TableIdempotencyKeys)
+
+	zerolog.Ctx(ctx).Debug().Str("query", q).Str("key", rec.Key).Int("status", rec.Status).Msg(op)
+
+	_, err = s.db.ExecContext(ctx, q, rec.Scope, rec.Key, rec.Status, rec.ContentType, rec.Body, rec.ExpiresAt)
+	return e.WrapIfErr(op, translateError(err))
+}
+
+func (s *IdempotencyKeysStore) Release(ctx context.Context, scope, key string) (err error) {
+	const op = "storage.postgresql.idempotency.release"
+	q := sprintf(`
+		DELETE FROM %s WHERE scope = $1 AND key = $2 AND status IS NULL
+	`, TableIdempotencyKeys)
+
+	zerolog.Ctx(ctx).Debug().Str("query", q).Str("key", key).Msg(op)
+
+	_, err = s.db.ExecContext(ctx, q, scope, key)
+	return e.WrapIfErr(op, translateError(err))
+}
+
+func (s *IdempotencyKeysStore) DeleteExpired(ctx context.Context) (n int64, err error) {
+	const op = "storage.postgresql.idempotency.deleteexpired"
+	q := sprintf(`
+		DELETE FROM %s WHERE expires_at <= now()
+	`, TableIdempotencyKeys)
+
+	zerolog.Ctx(ctx).Debug().Str("query", q).Msg(op)
+
+	res, err := s.db.ExecContext(ctx, q)
+	if err != nil {
+		return 0, e.Wrap(op, translateError(err))
+	}
+	n, err = res.RowsAffected()
+	if err != nil {
+		return 0, e.Wrap(fmt.Sprintf("%s.rows_affected", op), err)
+	}
+	return n, nil
+}
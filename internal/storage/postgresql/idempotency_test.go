@@ -0,0 +1,69 @@
+package postgresql
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/storage"
+)
+
+func TestIdempotencyKeys_Acquire(t *testing.T) {
+	dbStore, db, mock, err := newMockSQLStorage()
+	if err != nil {
+		t.Fatal("can't crate mock storage", err)
+	}
+	defer db.Close()
+	st := NewIdempotencyKeysStore(dbStore)
+
+	const insert = "INSERT INTO idempotency_keys (scope, key, method, path, request_hash, expires_at) VALUES ($1, $2, $3, $4, $5, $6) " +
+		"ON CONFLICT (scope, key) DO UPDATE SET (method, path, request_hash, status, content_type, body, created_at, expires_at) = " +
+		"(EXCLUDED.method, EXCLUDED.path, EXCLUDED.request_hash, NULL, '', NULL, now(), EXCLUDED.expires_at) " +
+		"WHERE idempotency_keys.expires_at <= now() RETURNING key"
+	const sel = "SELECT scope, key, method, path, request_hash, COALESCE(status, 0) AS status, content_type, body, expires_at FROM idempotency_keys WHERE scope = $1 AND key = $2"
+
+	rec := &storage.IdempotencyRecord{Scope: "admin", Key: "k1", Method: "POST", Path: "/api/v1/subscription/", RequestHash: "hash", ExpiresAt: time.Now()}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    *storage.IdempotencyRecord
+		wantErr bool
+	}{
+		{
+			name: "Ok (new)",
+			mock: func() {
+				mock.ExpectQuery(insert).WillReturnRows(sqlmock.NewRows([]string{"key"}).AddRow("k1"))
+			},
+		},
+		{
+			name: "Ok (existing)",
+			mock: func() {
+				mock.ExpectQuery(insert).WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(sel).WithArgs("admin", "k1").WillReturnRows(
+					sqlmock.NewRows([]string{"scope", "key", "method", "path", "request_hash", "status", "content_type", "body", "expires_at"}).
+						AddRow("admin", "k1", "POST", "/api/v1/subscription/", "hash", 201, "application/json", []byte("{}"), rec.ExpiresAt))
+			},
+			want: &storage.IdempotencyRecord{Scope: "admin", Key: "k1", Method: "POST", Path: "/api/v1/subscription/", RequestHash: "hash",
+				Status: 201, ContentType: "application/json", Body: []byte("{}"), ExpiresAt: rec.ExpiresAt},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := st.Acquire(t.Context(), rec)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+
+	microservice "github.com/ikotiki/go-rest-api-service-subscriptions"
+	"github.com/rs/zerolog"
+
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/storage"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/tenant"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/pkg/e"
+
+	"github.com/lib/pq"
+)
+
+// MembersStore isolates members of tenants by their subscriptions: every
+// statement is limited to subscriptions of the tenant of the context.
+type MembersStore struct {
+	db dbtx
+}
+
+func NewMembersStore(store *SQLStorage) *MembersStore {
+	return &MembersStore{db: store.conn()}
+}
+
+func (s *MembersStore) Add(ctx context.Context, m *microservice.Member) (err error) {
+	const op = "storage.postgresql.members.add"
+	q := sprintf(`
+		INSERT INTO %s (subscription_id, user_id, share_percent, share_amount)
+		SELECT $1, $2, $3, $4 WHERE EXISTS (SELECT 1 FROM %s WHERE id = $1 AND tenant_id = $5)
+		ON CONFLICT (subscription_id, user_id) DO UPDATE
+		SET share_percent = EXCLUDED.share_percent, share_amount = EXCLUDED.share_amount
+	`, TableSubscriptionMembers, TableSubscriptions)
+
+	zerolog.Ctx(ctx).Debug().Str("query", q).Interface("member", m).Msg(op)
+
+	res, err := s.db.ExecContext(ctx, q, m.SubscriptionID, m.UserID, m.SharePercent, m.ShareAmount, tenant.FromContext(ctx))
+	if err != nil {
+		return e.Wrap(op, translateError(err))
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return e.Wrap(fmt.Sprintf("%s.rows_affected", op), err)
+	}
+	if n == 0 {
+		return e.Wrap(op, storage.ErrNoSuchSubscription)
+	}
+
+	return nil
+}
+
+func (s *MembersStore) Remove(ctx context.Context, id microservice.SubscriptionID, userID microservice.UserID) (err error) {
+	const op = "storage.postgresql.members.remove"
+	q := sprintf(`
+		DELETE FROM %s WHERE subscription_id = $1 AND user_id = $2
+		AND subscription_id IN (SELECT id FROM %s WHERE tenant_id = $3)
+	`, TableSubscriptionMembers, TableSubscriptions)
+
+	zerolog.Ctx(ctx).Debug().Str("query", q).Int("id", int(id)).Str("user_id", userID.String()).Msg(op)
+
+	res, err := s.db.ExecContext(ctx, q, id, userID, tenant.FromContext(ctx))
+	if err != nil {
+		return e.Wrap(op, translateError(err))
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return e.Wrap(fmt.Sprintf("%s.rows_affected", op), err)
+	}
+	if n == 0 {
+		return e.Wrap(op, storage.ErrNoSuchMember)
+	}
+
+	return nil
+}
+
+func (s *MembersStore) ListBySubscription(ctx context.Context, id microservice.SubscriptionID) (members []*microservice.Member, err error) {
+	const op = "storage.postgresql.members.listbysubscription"
+	q := sprintf(`
+		SELECT m.* FROM %s AS m
+		JOIN %s AS s ON s.id = m.subscription_id
+		WHERE m.subscription_id = $1 AND s.tenant_id = $2
+		ORDER BY m.user_id ASC
+	`, TableSubscriptionMembers, TableSubscriptions)
+
+	zerolog.Ctx(ctx).Debug().Str("query", q).Int("id", int(id)).Msg(op)
+
+	members = []*microservice.Member{}
+	err = s.db.SelectContext(ctx, &members, q, id, tenant.FromContext(ctx))
+	if err != nil {
+		return nil, e.Wrap(op, translateError(err))
+	}
+	return members, nil
+}
+
+func (s *MembersStore) ListBySubscriptions(ctx context.Context, ids []microservice.SubscriptionID) (members []*microservice.Member, err error) {
+	const op = "storage.postgresql.members.listbysubscriptions"
+	q := sprintf(`
+		SELECT m.* FROM %s AS m
+		JOIN %s AS s ON s.id = m.subscription_id
+		WHERE m.subscription_id = ANY($1) AND s.tenant_id = $2
+		ORDER BY m.subscription_id ASC, m.user_id ASC
+	`, TableSubscriptionMembers, TableSubscriptions)
+
+	zerolog.Ctx(ctx).Debug().Str("query", q).Interface("ids", ids).Msg(op)
+
+	members = []*microservice.Member{}
+	err = s.db.SelectContext(ctx, &members, q, pq.Int64Array(ids), tenant.FromContext(ctx))
+	if err != nil {
+		return nil, e.Wrap(op, translateError(err))
+	}
+	return members, nil
+}
@@ -0,0 +1,84 @@
+package postgresql
+
+import (
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/storage"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/tenant"
+)
+
+func TestMembers_Add(t *testing.T) {
+	dbStore, db, mock, err := newMockSQLStorage()
+	if err != nil {
+		t.Fatal("can't crate mock storage", err)
+	}
+	defer db.Close()
+	st := NewMembersStore(dbStore)
+
+	const q = "INSERT INTO subscription_members (subscription_id, user_id, share_percent, share_amount) " +
+		"SELECT $1, $2, $3, $4 WHERE EXISTS (SELECT 1 FROM subscriptions WHERE id = $1 AND tenant_id = $5) " +
+		"ON CONFLICT (subscription_id, user_id) DO UPDATE SET share_percent = EXCLUDED.share_percent, share_amount = EXCLUDED.share_amount"
+	amount := storage.Price(100)
+	member := &storage.Member{SubscriptionID: 1, UserID: uuid.MustParse("123e4567-e89b-12d3-a456-426614174000"), ShareAmount: &amount}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		wantErr error
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				mock.ExpectExec(q).WithArgs(member.SubscriptionID, member.UserID, member.SharePercent, member.ShareAmount, "acme").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "Error (subscription of another tenant)",
+			mock: func() {
+				mock.ExpectExec(q).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr: storage.ErrNoSuchSubscription,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err := st.Add(tenant.WithID(t.Context(), "acme"), member)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestMembers_ListBySubscription(t *testing.T) {
+	dbStore, db, mock, err := newMockSQLStorage()
+	if err != nil {
+		t.Fatal("can't crate mock storage", err)
+	}
+	defer db.Close()
+	st := NewMembersStore(dbStore)
+
+	userID := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
+	mock.ExpectQuery("SELECT m.* FROM subscription_members AS m JOIN subscriptions AS s ON s.id = m.subscription_id "+
+		"WHERE m.subscription_id = $1 AND s.tenant_id = $2 ORDER BY m.user_id ASC").
+		WithArgs(1, "acme").
+		WillReturnRows(sqlmock.NewRows([]string{"subscription_id", "user_id", "share_percent", "share_amount"}).AddRow(1, userID, nil, 100))
+
+	got, err := st.ListBySubscription(tenant.WithID(t.Context(), "acme"), 1)
+	assert.NoError(t, err)
+	if assert.Len(t, got, 1) {
+		assert.Equal(t, userID, got[0].UserID)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
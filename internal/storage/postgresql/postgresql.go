@@ -1,10 +1,12 @@
 package postgresql
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 
 	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/storage"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/pkg/e"
 
 	"github.com/ikotiki/sqlbuilder"
 	"github.com/ikotiki/sqlbuilder/builder"
@@ -16,6 +18,20 @@ import (
 // Database tables list.
 const (
 	TableSubscriptions string = "subscriptions"
+	TableServices      string = "services"
+	TableServiceKeys   string = "service_keys"
+	TableCategories    string = "categories"
+	TableTags          string = "tags"
+
+	TableSubscriptionTags    string = "subscription_tags"
+	TableSubscriptionMembers string = "subscription_members"
+	TableIdempotencyKeys     string = "idempotency_keys"
+	TableAPIKeys             string = "api_keys"
+	// Versions of applied migrations kept by goose.
+	TableMigrations string = "goose_db_version"
+
+	// View with share of every user in every subscription.
+	ViewSubscriptionShares string = "subscription_shares"
 )
 
 // Mapping for abstract storage.QueryArgs to a table name.
@@ -30,11 +46,31 @@ type Config struct {
 	Password string
 	DBName   string
 	SSLMode  string
+
+	// IsolationLevel of transactions started by WithTx: "read committed",
+	// "repeatable read" or "serializable". Empty means the database default.
+	IsolationLevel string
+	// TxMaxRetries limits reruns of transactions failed to serialize.
+	TxMaxRetries int
+}
+
+// dbtx is a part of sqlx API shared by connections pool and transactions,
+// so stores don't care, whether they run within a transaction.
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	GetContext(ctx context.Context, dest any, query string, args ...any) error
+	SelectContext(ctx context.Context, dest any, query string, args ...any) error
+	QueryRowxContext(ctx context.Context, query string, args ...any) *sqlx.Row
+	QueryxContext(ctx context.Context, query string, args ...any) (*sqlx.Rows, error)
 }
 
 type SQLStorage struct {
 	db      *sqlx.DB
+	tx      *sqlx.Tx
 	builder *builder.SQLBuilder
+
+	isolation    sql.IsolationLevel
+	txMaxRetries int
 }
 
 func NewSQLStorage(cfg Config) (*SQLStorage, error) {
@@ -53,7 +89,35 @@ func NewSQLStorage(cfg Config) (*SQLStorage, error) {
 		return nil, err
 	}
 
-	return &SQLStorage{db: db, builder: builder}, nil
+	isolation, err := parseIsolationLevel(cfg.IsolationLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLStorage{db: db, builder: builder, isolation: isolation, txMaxRetries: cfg.TxMaxRetries}, nil
+}
+
+// NewStorage gathers all stores over the database.
+func NewStorage(s *SQLStorage) storage.Storage {
+	return storage.Storage{
+		Subscriptions:   NewSubscriptionsStore(s),
+		Services:        NewServicesStore(s),
+		Categories:      NewCategoriesStore(s),
+		Tags:            NewTagsStore(s),
+		Members:         NewMembersStore(s),
+		IdempotencyKeys: NewIdempotencyKeysStore(s),
+		APIKeys:         NewAPIKeysStore(s),
+		Transactor:      s,
+	}
+}
+
+// conn returns the transaction, if the storage is bound to one,
+// and the connections pool otherwise.
+func (s *SQLStorage) conn() dbtx {
+	if s.tx != nil {
+		return s.tx
+	}
+	return s.db
 }
 
 func (s *SQLStorage) SQLInstance() *sql.DB {
@@ -64,11 +128,39 @@ func (s *SQLStorage) Close() error {
 	return s.db.Close()
 }
 
-// func NewPostgresStore(db *SQLStorage) *storage.Storage {
-// 	return &storage.Storage{
-// 		Subscriptions: NewSubscriptionsStore(db),
-// 	}
-// }
+// Ping checks the connection to the database.
+func (s *SQLStorage) Ping(ctx context.Context) error {
+	const op = "storage.postgresql.ping"
+	return e.WrapIfErr(op, s.db.PingContext(ctx))
+}
+
+// MigrationVersion returns the version of the last applied migration, 0
+// if none are. Rows of rolled back migrations are skipped the way goose
+// does it.
+func (s *SQLStorage) MigrationVersion(ctx context.Context) (version int64, err error) {
+	const op = "storage.postgresql.migrationversion"
+
+	q := sprintf(`SELECT version_id, is_applied FROM %s ORDER BY id DESC`, TableMigrations)
+	var rows []struct {
+		Version int64 `db:"version_id"`
+		Applied bool  `db:"is_applied"`
+	}
+	if err = s.db.SelectContext(ctx, &rows, q); err != nil {
+		return 0, e.Wrap(op, translateError(err))
+	}
+
+	rolledBack := make(map[int64]bool)
+	for _, row := range rows {
+		if rolledBack[row.Version] {
+			continue
+		}
+		if row.Applied {
+			return row.Version, nil
+		}
+		rolledBack[row.Version] = true
+	}
+	return 0, nil
+}
 
 func sprintf(q string, args ...interface{}) string {
 	return fmt.Sprintf(q, args...)
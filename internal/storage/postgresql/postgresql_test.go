@@ -0,0 +1,53 @@
+package postgresql
+
+import (
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSQLStorage_MigrationVersion(t *testing.T) {
+	dbStore, db, mock, err := newMockSQLStorage()
+	if err != nil {
+		t.Fatal("can't crate mock storage", err)
+	}
+	defer db.Close()
+
+	const q = "SELECT version_id, is_applied FROM goose_db_version ORDER BY id DESC"
+
+	tests := []struct {
+		name    string
+		rows    [][2]any
+		want    int64
+		wantErr bool
+	}{
+		{name: "Ok", rows: [][2]any{{10, true}, {9, true}, {0, true}}, want: 10},
+		{name: "Ok (rolled back)", rows: [][2]any{{10, false}, {10, true}, {9, true}, {0, true}}, want: 9},
+		{name: "Ok (empty)", want: 0},
+		{name: "Error", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.wantErr {
+				mock.ExpectQuery(q).WillReturnError(assert.AnError)
+			} else {
+				rows := sqlmock.NewRows([]string{"version_id", "is_applied"})
+				for _, r := range tt.rows {
+					rows.AddRow(r[0], r[1])
+				}
+				mock.ExpectQuery(q).WillReturnRows(rows)
+			}
+
+			got, err := dbStore.MigrationVersion(t.Context())
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	microservice "github.com/ikotiki/go-rest-api-service-subscriptions"
+	"github.com/rs/zerolog"
+
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/storage"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/pkg/e"
+
+	"github.com/lib/pq"
+)
+
+type ServicesStore struct {
+	db dbtx
+}
+
+func NewServicesStore(store *SQLStorage) *ServicesStore {
+	return &ServicesStore{db: store.conn()}
+}
+
+// serviceRow is a database representation of microservice.Service,
+// because driver can't scan arrays into plain slices.
+type serviceRow struct {
+	ID           microservice.ServiceID   `db:"id"`
+	Name         string                   `db:"name"`
+	NameKey      string                   `db:"name_key"`
+	Aliases      pq.StringArray           `db:"aliases"`
+	AliasKeys    pq.StringArray           `db:"alias_keys"`
+	CategoryID   *microservice.CategoryID `db:"category_id"`
+	DefaultPrice microservice.Price       `db:"default_price"`
+}
+
+func (r *serviceRow) toService() *microservice.Service {
+	return &microservice.Service{
+		ID:           r.ID,
+		Name:         r.Name,
+		NameKey:      r.NameKey,
+		Aliases:      []string(r.Aliases),
+		AliasKeys:    []string(r.AliasKeys),
+		CategoryID:   r.CategoryID,
+		DefaultPrice: r.DefaultPrice,
+	}
+}
+
+func (s *ServicesStore) GetByID(ctx context.Context, id microservice.ServiceID) (svc *microservice.Service, err error) {
+	const op = "storage.postgresql.services.getbyid"
+	row := &serviceRow{}
+
+	q := sprintf(`SELECT * FROM %s WHERE id = $1`, TableServices)
+
+	zerolog.Ctx(ctx).Debug().Str("query", q).Int("id", int(id)).Msg(op)
+
+	err = s.db.GetContext(ctx, row, q, id)
+	if err == sql.ErrNoRows {
+		return nil, storage.ErrNoSuchService
+	}
+	if err != nil {
+		return nil, e.Wrap(op, translateError(err))
+	}
+
+	return row.toService(), nil
+}
+
+func (s *ServicesStore) GetByKey(ctx context.Context, key string) (svc *microservice.Service, err error) {
+	const op = "storage.postgresql.services.getbykey"
+	row := &serviceRow{}
+
+	q := sprintf(`SELECT * FROM %s WHERE name_key = $1 OR $1 = ANY(alias_keys) LIMIT 1`, TableServices)
+
+	zerolog.Ctx(ctx).Debug().Str("query", q).Str("key", key).Msg(op)
+
+	err = s.db.GetContext(ctx, row, q, key)
+	if err == sql.ErrNoRows {
+		return nil, storage.ErrNoSuchService
+	}
+	if err != nil {
+		return nil, e.Wrap(op, translateError(err))
+	}
+
+	return row.toService(), nil
+}
+
+// Create inserts the service together with keys of its name and aliases,
+// which are unique across the catalog.
+func (s *ServicesStore) Create(ctx context.Context, svc *microservice.Service) (id microservice.ServiceID, err error) {
+	const op = "storage.postgresql.services.create"
+	q := sprintf(`
+		WITH service AS (
+			INSERT INTO %s (name, name_key, aliases, alias_keys, category_id, default_price)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id
+		), keys AS (
+			INSERT INTO %s (key, service_id)
+			SELECT k.key, service.id FROM service, unnest($7::text[]) AS k(key)
+		)
+		SELECT id FROM service
+	`, TableServices, TableServiceKeys)
+
+	zerolog.Ctx(ctx).Debug().Str("query", q).Interface("service", svc).Msg(op)
+
+	row := s.db.QueryRowxContext(ctx, q, svc.Name, svc.NameKey, pq.StringArray(svc.Aliases), pq.StringArray(svc.AliasKeys),
+		svc.CategoryID, svc.DefaultPrice, serviceKeys(svc))
+	if err = row.Scan(&id); err != nil {
+		return 0, e.Wrap(op, translateError(err))
+	}
+
+	return id, nil
+}
+
+// Update replaces the service and its keys. Kept keys are not touched, so
+// removed and added keys never collide within the statement.
+func (s *ServicesStore) Update(ctx context.Context, svc *microservice.Service) (err error) {
+	const op = "storage.postgresql.services.update"
+	q := sprintf(`
+		WITH service AS (
+			UPDATE %[1]s SET (name, name_key, aliases, alias_keys, category_id, default_price) = ($2, $3, $4, $5, $6, $7)
+			WHERE id = $1
+			RETURNING id
+		), stale AS (
+			DELETE FROM %[2]s WHERE service_id IN (SELECT id FROM service) AND NOT key = ANY($8::text[])
+		), fresh AS (
+			INSERT INTO %[2]s (key, service_id)
+			SELECT k.key, service.id FROM service, unnest($8::text[]) AS k(key)
+			WHERE NOT EXISTS (SELECT 1 FROM %[2]s kept WHERE kept.key = k.key AND kept.service_id = service.id)
+		)
+		SELECT count(*) FROM service
+	`, TableServices, TableServiceKeys)
+
+	zerolog.Ctx(ctx).Debug().Str("query", q).Interface("service", svc).Msg(op)
+
+	var n int
+	row := s.db.QueryRowxContext(ctx, q, svc.ID, svc.Name, svc.NameKey, pq.StringArray(svc.Aliases), pq.StringArray(svc.AliasKeys),
+		svc.CategoryID, svc.DefaultPrice, serviceKeys(svc))
+	if err = row.Scan(&n); err != nil {
+		return e.Wrap(op, translateError(err))
+	}
+	if n == 0 {
+		return e.Wrap(op, storage.ErrNoSuchService)
+	}
+
+	return nil
+}
+
+// serviceKeys lists lookup keys of the service name and aliases.
+func serviceKeys(svc *microservice.Service) pq.StringArray {
+	return append(pq.StringArray{svc.NameKey}, svc.AliasKeys...)
+}
+
+func (s *ServicesStore) DeleteByID(ctx context.Context, id microservice.ServiceID) (err error) {
+	const op = "storage.postgresql.services.deletebyid"
+	q := sprintf(`
+		DELETE FROM %s WHERE id = $1
+	`, TableServices)
+
+	zerolog.Ctx(ctx).Debug().Str("query", q).Int("id", int(id)).Msg(op)
+
+	res, err := s.db.ExecContext(ctx, q, id)
+	if err != nil {
+		return e.Wrap(op, translateError(err))
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return e.Wrap(fmt.Sprintf("%s.rows_affected", op), err)
+	}
+	if n == 0 {
+		return e.Wrap(op, storage.ErrNoSuchService)
+	}
+
+	return nil
+}
+
+func (s *ServicesStore) List(ctx context.Context) (svcs []*microservice.Service, err error) {
+	const op = "storage.postgresql.services.list"
+	q := sprintf(`SELECT * FROM %s ORDER BY name ASC`, TableServices)
+
+	zerolog.Ctx(ctx).Debug().Str("query", q).Msg(op)
+
+	rows := []*serviceRow{}
+	err = s.db.SelectContext(ctx, &rows, q)
+	if err != nil {
+		return nil, e.Wrap(op, translateError(err))
+	}
+
+	svcs = make([]*microservice.Service, 0, len(rows))
+	for _, row := range rows {
+		svcs = append(svcs, row.toService())
+	}
+	return svcs, nil
+}
@@ -0,0 +1,264 @@
+package postgresql
+
+import (
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/storage"
+)
+
+func TestServices_Create(t *testing.T) {
+	dbStore, db, mock, err := newMockSQLStorage()
+	if err != nil {
+		t.Fatal("can't crate mock storage", err)
+	}
+	defer db.Close()
+	st := NewServicesStore(dbStore)
+
+	categoryID := storage.CategoryID(3)
+	input := &storage.Service{
+		Name:         "Yandex Plus",
+		NameKey:      "yandexplus",
+		Aliases:      []string{"Яндекс Плюс"},
+		AliasKeys:    []string{"яндексплюс"},
+		CategoryID:   &categoryID,
+		DefaultPrice: 399,
+	}
+	const q = `WITH service AS ( INSERT INTO services (name, name_key, aliases, alias_keys, category_id, default_price) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id ), ` +
+		`keys AS ( INSERT INTO service_keys (key, service_id) SELECT k.key, service.id FROM service, unnest($7::text[]) AS k(key) ) SELECT id FROM service`
+
+	tests := []struct {
+		name    string
+		mock    func()
+		input   *storage.Service
+		want    storage.ServiceID
+		wantErr error
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
+				mock.ExpectQuery(q).
+					WithArgs("Yandex Plus", "yandexplus", pq.StringArray{"Яндекс Плюс"}, pq.StringArray{"яндексплюс"}, &categoryID, storage.Price(399),
+						pq.StringArray{"yandexplus", "яндексплюс"}).
+					WillReturnRows(rows)
+			},
+			input: input,
+			want:  1,
+		},
+		{
+			name: "Error (already exists)",
+			mock: func() {
+				mock.ExpectQuery(q).
+					WillReturnError(&pq.Error{Code: codeUniqueViolation, Constraint: "services_name_key_unique"})
+			},
+			input:   input,
+			wantErr: storage.ErrServiceAlreadyExists,
+		},
+		{
+			name: "Error (alias taken)",
+			mock: func() {
+				mock.ExpectQuery(q).
+					WillReturnError(&pq.Error{Code: codeUniqueViolation, Constraint: "service_keys_key_unique"})
+			},
+			input:   input,
+			wantErr: storage.ErrServiceAlreadyExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := st.Create(t.Context(), tt.input)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestServices_Update(t *testing.T) {
+	dbStore, db, mock, err := newMockSQLStorage()
+	if err != nil {
+		t.Fatal("can't crate mock storage", err)
+	}
+	defer db.Close()
+	st := NewServicesStore(dbStore)
+
+	input := &storage.Service{
+		ID:           1,
+		Name:         "Yandex Plus",
+		NameKey:      "yandexplus",
+		Aliases:      []string{"Яндекс Плюс"},
+		AliasKeys:    []string{"яндексплюс"},
+		DefaultPrice: 399,
+	}
+	const q = `WITH service AS ( UPDATE services SET (name, name_key, aliases, alias_keys, category_id, default_price) = ($2, $3, $4, $5, $6, $7) WHERE id = $1 RETURNING id ), ` +
+		`stale AS ( DELETE FROM service_keys WHERE service_id IN (SELECT id FROM service) AND NOT key = ANY($8::text[]) ), ` +
+		`fresh AS ( INSERT INTO service_keys (key, service_id) SELECT k.key, service.id FROM service, unnest($8::text[]) AS k(key) ` +
+		`WHERE NOT EXISTS (SELECT 1 FROM service_keys kept WHERE kept.key = k.key AND kept.service_id = service.id) ) SELECT count(*) FROM service`
+
+	tests := []struct {
+		name    string
+		mock    func()
+		wantErr error
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				mock.ExpectQuery(q).
+					WithArgs(storage.ServiceID(1), "Yandex Plus", "yandexplus", pq.StringArray{"Яндекс Плюс"}, pq.StringArray{"яндексплюс"},
+						(*storage.CategoryID)(nil), storage.Price(399), pq.StringArray{"yandexplus", "яндексплюс"}).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+			},
+		},
+		{
+			name: "Error (not found)",
+			mock: func() {
+				mock.ExpectQuery(q).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+			},
+			wantErr: storage.ErrNoSuchService,
+		},
+		{
+			name: "Error (alias taken)",
+			mock: func() {
+				mock.ExpectQuery(q).
+					WillReturnError(&pq.Error{Code: codeUniqueViolation, Constraint: "service_keys_key_unique"})
+			},
+			wantErr: storage.ErrServiceAlreadyExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err := st.Update(t.Context(), input)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestServices_GetByKey(t *testing.T) {
+	dbStore, db, mock, err := newMockSQLStorage()
+	if err != nil {
+		t.Fatal("can't crate mock storage", err)
+	}
+	defer db.Close()
+	st := NewServicesStore(dbStore)
+
+	categoryID := storage.CategoryID(3)
+	columns := []string{"id", "name", "name_key", "aliases", "alias_keys", "category_id", "default_price"}
+	const q = "SELECT * FROM services WHERE name_key = $1 OR $1 = ANY(alias_keys) LIMIT 1"
+
+	tests := []struct {
+		name    string
+		mock    func()
+		input   string
+		want    *storage.Service
+		wantErr error
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				rows := sqlmock.NewRows(columns).
+					AddRow(1, "Yandex Plus", "yandexplus", `{"Яндекс Плюс"}`, `{"яндексплюс"}`, 3, 399)
+				mock.ExpectQuery(q).WithArgs("яндексплюс").WillReturnRows(rows)
+			},
+			input: "яндексплюс",
+			want: &storage.Service{
+				ID:           1,
+				Name:         "Yandex Plus",
+				NameKey:      "yandexplus",
+				Aliases:      []string{"Яндекс Плюс"},
+				AliasKeys:    []string{"яндексплюс"},
+				CategoryID:   &categoryID,
+				DefaultPrice: 399,
+			},
+		},
+		{
+			name: "Error (not found)",
+			mock: func() {
+				mock.ExpectQuery(q).WithArgs("unknown").WillReturnRows(sqlmock.NewRows(columns))
+			},
+			input:   "unknown",
+			wantErr: storage.ErrNoSuchService,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := st.GetByKey(t.Context(), tt.input)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestServices_DeleteByID(t *testing.T) {
+	dbStore, db, mock, err := newMockSQLStorage()
+	if err != nil {
+		t.Fatal("can't crate mock storage", err)
+	}
+	defer db.Close()
+	st := NewServicesStore(dbStore)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		input   storage.ServiceID
+		wantErr error
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				mock.ExpectExec("DELETE FROM services WHERE id = $1").WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			input: 1,
+		},
+		{
+			name: "Error (not found)",
+			mock: func() {
+				mock.ExpectExec("DELETE FROM services WHERE id = $1").WithArgs(2).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			input:   2,
+			wantErr: storage.ErrNoSuchService,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err := st.DeleteByID(t.Context(), tt.input)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -4,95 +4,211 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	microservice "github.com/ikotiki/go-rest-api-service-subscriptions"
-	"github.com/rs/zerolog/log"
+	"github.com/rs/zerolog"
 
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/metrics"
 	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/storage"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/tenant"
 	"github.com/ikotiki/go-rest-api-service-subscriptions/pkg/e"
 
-	"github.com/jmoiron/sqlx"
-)
-
-const (
-	errStrUserSubscriptionPairAlreadyExists = "duplicate key value violates unique constraint \"subscriptions_user_id_service_name_key\""
+	"github.com/lib/pq"
 )
 
+// SubscriptionsStore isolates subscriptions of tenants: every query is
+// limited to the tenant of the context. Statements are traced.
 type SubscriptionsStore struct {
-	db      *sqlx.DB
+	db      tracedDB
 	builder *postgresSQLBuilder
 }
 
 func NewSubscriptionsStore(store *SQLStorage) *SubscriptionsStore {
-	return &SubscriptionsStore{db: store.db, builder: &postgresSQLBuilder{store.builder}}
+	return &SubscriptionsStore{db: tracedDB{store.conn()}, builder: &postgresSQLBuilder{store.builder}}
 }
 
 func (s *SubscriptionsStore) GetByID(ctx context.Context, id microservice.SubscriptionID) (sub *microservice.Subscription, err error) {
 	const op = "storage.postgresql.subscriptions.getbyid"
+	defer observe(op, time.Now(), &err)
 	sub = &microservice.Subscription{}
 
-	q := sprintf(`SELECT * FROM %s WHERE id = $1`, TableSubscriptions)
+	q := sprintf(`SELECT * FROM %s WHERE id = $1 AND tenant_id = $2`, TableSubscriptions)
 
-	log.Debug().Str("query", q).Int("id", int(id)).Msg(op)
+	zerolog.Ctx(ctx).Debug().Str("query", q).Int("id", int(id)).Msg(op)
 
-	err = s.db.GetContext(ctx, sub, q, id)
+	err = s.db.GetContext(ctx, sub, q, id, tenant.FromContext(ctx))
 	if err == sql.ErrNoRows {
 		return nil, storage.ErrNoSuchSubscription
 	}
+	if err != nil {
+		return nil, e.Wrap(op, translateError(err))
+	}
 
-	return sub, err
+	return sub, nil
+}
+
+func (s *SubscriptionsStore) GetByIDForUpdate(ctx context.Context, id microservice.SubscriptionID) (sub *microservice.Subscription, err error) {
+	const op = "storage.postgresql.subscriptions.getbyidforupdate"
+	defer observe(op, time.Now(), &err)
+	sub = &microservice.Subscription{}
+
+	q := sprintf(`SELECT * FROM %s WHERE id = $1 AND tenant_id = $2 FOR UPDATE`, TableSubscriptions)
+
+	zerolog.Ctx(ctx).Debug().Str("query", q).Int("id", int(id)).Msg(op)
+
+	err = s.db.GetContext(ctx, sub, q, id, tenant.FromContext(ctx))
+	if err == sql.ErrNoRows {
+		return nil, storage.ErrNoSuchSubscription
+	}
+	if err != nil {
+		return nil, e.Wrap(op, translateError(err))
+	}
+
+	return sub, nil
+}
+
+// GetManyForUpdate locks rows in order of ids, so concurrent requests
+// locking the same subscriptions don't deadlock.
+func (s *SubscriptionsStore) GetManyForUpdate(ctx context.Context, ids []microservice.SubscriptionID) (subs []*microservice.Subscription, err error) {
+	const op = "storage.postgresql.subscriptions.getmanyforupdate"
+	defer observe(op, time.Now(), &err)
+
+	q := sprintf(`SELECT * FROM %s WHERE id = ANY($1) AND tenant_id = $2 ORDER BY id FOR UPDATE`, TableSubscriptions)
+
+	zerolog.Ctx(ctx).Debug().Str("query", q).Interface("ids", ids).Msg(op)
+
+	subs = []*microservice.Subscription{}
+	if err = s.db.SelectContext(ctx, &subs, q, pq.Int64Array(ids), tenant.FromContext(ctx)); err != nil {
+		return nil, e.Wrap(op, translateError(err))
+	}
+	return subs, nil
 }
 
 func (s *SubscriptionsStore) Create(ctx context.Context, sub *microservice.Subscription) (id microservice.SubscriptionID, err error) {
 	const op = "storage.postgresql.subscriptions.create"
+	defer observe(op, time.Now(), &err)
 	q := sprintf(`
-		INSERT INTO %s (user_id, service_name, monthly_price, start_date, end_date)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO %s (user_id, service_id, service_name, category_id, monthly_price, start_date, end_date, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING id
 	`, TableSubscriptions)
 
-	log.Debug().Str("query", q).Interface("subscription", sub).Msg(op)
+	zerolog.Ctx(ctx).Debug().Str("query", q).Interface("subscription", sub).Msg(op)
 
-	row := s.db.QueryRowxContext(ctx, q, sub.UserID, sub.ServiceName, sub.MonthlyPrice, sub.StartDate, sub.EndDate)
-	if err = row.Err(); err != nil {
-		if e.HasText(err, errStrUserSubscriptionPairAlreadyExists) {
-			return 0, storage.ErrUserSubscriptionPairAlreadyExists
-		}
-		return 0, e.Wrap(op, err)
+	row := s.db.QueryRowxContext(ctx, q, sub.UserID, sub.ServiceID, sub.ServiceName, sub.CategoryID, sub.MonthlyPrice, sub.StartDate, sub.EndDate,
+		tenant.FromContext(ctx))
+	if err = row.Scan(&id); err != nil {
+		return 0, e.Wrap(op, translateError(err))
 	}
 
-	row.Scan(&id)
-	if id == 0 {
-		return 0, e.Wrap(op, err)
+	return id, nil
+}
+
+// CreateMany inserts subscriptions with a single INSERT of column arrays
+// and returns their ids in the same order. Postgres doesn't keep the order
+// of rows in RETURNING, so ids are taken from the sequence for every item
+// beforehand and ordered by its position.
+func (s *SubscriptionsStore) CreateMany(ctx context.Context, subs []*microservice.Subscription) (ids []microservice.SubscriptionID, err error) {
+	const op = "storage.postgresql.subscriptions.createmany"
+	defer observe(op, time.Now(), &err)
+
+	var (
+		userIDs      = make([]microservice.UserID, len(subs))
+		serviceIDs   = make([]*microservice.ServiceID, len(subs))
+		serviceNames = make([]string, len(subs))
+		categoryIDs  = make([]*microservice.CategoryID, len(subs))
+		prices       = make([]microservice.Price, len(subs))
+		startDates   = make([]microservice.Date, len(subs))
+		endDates     = make([]microservice.Date, len(subs))
+	)
+	for i, sub := range subs {
+		userIDs[i], serviceIDs[i], serviceNames[i], categoryIDs[i] = sub.UserID, sub.ServiceID, sub.ServiceName, sub.CategoryID
+		prices[i], startDates[i], endDates[i] = sub.MonthlyPrice, sub.StartDate, sub.EndDate
 	}
 
-	return id, nil
+	q := sprintf(`
+		WITH items AS (
+			SELECT nextval(pg_get_serial_sequence('%[1]s', 'id')) AS id, t.*
+			FROM unnest($1::uuid[], $2::integer[], $3::text[], $4::integer[], $5::integer[], $6::timestamp[], $7::timestamp[])
+				WITH ORDINALITY AS t(user_id, service_id, service_name, category_id, monthly_price, start_date, end_date, ord)
+		), inserted AS (
+			INSERT INTO %[1]s (id, user_id, service_id, service_name, category_id, monthly_price, start_date, end_date, tenant_id)
+			SELECT id, user_id, service_id, service_name, category_id, monthly_price, start_date, end_date, $8 FROM items
+			RETURNING id
+		)
+		SELECT items.id FROM items JOIN inserted ON inserted.id = items.id ORDER BY items.ord
+	`, TableSubscriptions)
+
+	zerolog.Ctx(ctx).Debug().Str("query", q).Int("rows", len(subs)).Msg(op)
+
+	ids = make([]microservice.SubscriptionID, 0, len(subs))
+	err = s.db.SelectContext(ctx, &ids, q, pq.Array(userIDs), pq.Array(serviceIDs), pq.Array(serviceNames), pq.Array(categoryIDs),
+		pq.Array(prices), pq.Array(startDates), pq.Array(endDates), tenant.FromContext(ctx))
+	if err != nil {
+		return nil, e.Wrap(op, translateError(err))
+	}
+
+	return ids, nil
+}
+
+// Upsert inserts the subscription or updates the open subscription of the
+// same user and service. The subscription with end date conflicts only with
+// the overlapping ones, so it's always inserted.
+func (s *SubscriptionsStore) Upsert(ctx context.Context, sub *microservice.Subscription) (id microservice.SubscriptionID, created bool, err error) {
+	const op = "storage.postgresql.subscriptions.upsert"
+	defer observe(op, time.Now(), &err)
+	// xmax of the freshly inserted row is zero.
+	q := sprintf(`
+		INSERT INTO %s (user_id, service_id, service_name, category_id, monthly_price, start_date, end_date, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (tenant_id, user_id, service_name) WHERE end_date IS NULL DO UPDATE SET
+			(service_id, category_id, monthly_price, start_date, end_date) =
+			(EXCLUDED.service_id, EXCLUDED.category_id, EXCLUDED.monthly_price, EXCLUDED.start_date, EXCLUDED.end_date)
+		RETURNING id, (xmax = 0) AS created
+	`, TableSubscriptions)
+
+	zerolog.Ctx(ctx).Debug().Str("query", q).Interface("subscription", sub).Msg(op)
+
+	row := s.db.QueryRowxContext(ctx, q, sub.UserID, sub.ServiceID, sub.ServiceName, sub.CategoryID, sub.MonthlyPrice, sub.StartDate, sub.EndDate,
+		tenant.FromContext(ctx))
+	if err = row.Scan(&id, &created); err != nil {
+		return 0, false, e.Wrap(op, translateError(err))
+	}
+
+	return id, created, nil
 }
 
 func (s *SubscriptionsStore) Update(ctx context.Context, sub *microservice.Subscription) (err error) {
 	const op = "storage.postgresql.subscriptions.update"
+	defer observe(op, time.Now(), &err)
 	q := sprintf(`
-		UPDATE %s SET (service_name, monthly_price, start_date, end_date) = ($2, $3, $4, $5)
-		WHERE id = $1
+		UPDATE %s SET (service_id, service_name, category_id, monthly_price, start_date, end_date) = ($2, $3, $4, $5, $6, $7)
+		WHERE id = $1 AND tenant_id = $8
 	`, TableSubscriptions)
 
-	log.Debug().Str("query", q).Interface("subscription", sub).Msg(op)
+	zerolog.Ctx(ctx).Debug().Str("query", q).Interface("subscription", sub).Msg(op)
 
-	_, err = s.db.ExecContext(ctx, q, sub.ID, sub.ServiceName, sub.MonthlyPrice, sub.StartDate, sub.EndDate)
-	return e.WrapIfErr(op, err)
+	_, err = s.db.ExecContext(ctx, q, sub.ID, sub.ServiceID, sub.ServiceName, sub.CategoryID, sub.MonthlyPrice, sub.StartDate, sub.EndDate,
+		tenant.FromContext(ctx))
+	if err != nil {
+		return e.Wrap(op, translateError(err))
+	}
+	return nil
 }
 
 func (s *SubscriptionsStore) DeleteByID(ctx context.Context, id microservice.SubscriptionID) (err error) {
 	const op = "storage.postgresql.subscriptions.deletebyid"
+	defer observe(op, time.Now(), &err)
 	q := sprintf(`
-		DELETE FROM %s WHERE id = $1
+		DELETE FROM %s WHERE id = $1 AND tenant_id = $2
 	`, TableSubscriptions)
 
-	log.Debug().Str("query", q).Int("id", int(id)).Msg(op)
+	zerolog.Ctx(ctx).Debug().Str("query", q).Int("id", int(id)).Msg(op)
 
-	res, err := s.db.ExecContext(ctx, q, id)
+	res, err := s.db.ExecContext(ctx, q, id, tenant.FromContext(ctx))
 	if err != nil {
-		return e.Wrap(op, err)
+		return e.Wrap(op, translateError(err))
 	}
 	n, err := res.RowsAffected()
 	if err != nil {
@@ -105,49 +221,168 @@ func (s *SubscriptionsStore) DeleteByID(ctx context.Context, id microservice.Sub
 	return nil
 }
 
+// DeleteMany deletes subscriptions by ids and returns ids of the deleted
+// ones, missing subscriptions are skipped.
+func (s *SubscriptionsStore) DeleteMany(ctx context.Context, ids []microservice.SubscriptionID) (deleted []microservice.SubscriptionID, err error) {
+	const op = "storage.postgresql.subscriptions.deletemany"
+	defer observe(op, time.Now(), &err)
+	q := sprintf(`
+		DELETE FROM %s WHERE id = ANY($1) AND tenant_id = $2 RETURNING id
+	`, TableSubscriptions)
+
+	zerolog.Ctx(ctx).Debug().Str("query", q).Interface("ids", ids).Msg(op)
+
+	deleted = []microservice.SubscriptionID{}
+	if err = s.db.SelectContext(ctx, &deleted, q, pq.Int64Array(ids), tenant.FromContext(ctx)); err != nil {
+		return nil, e.Wrap(op, translateError(err))
+	}
+	return deleted, nil
+}
+
 func (s *SubscriptionsStore) Query(ctx context.Context, args *storage.QueryArgs) (subs []*microservice.Subscription, err error) {
 	const op = "storage.postgresql.subscriptions.query"
-	q := sprintf(`SELECT * FROM %s `, TableSubscriptions)
+	defer observe(op, time.Now(), &err)
+	q, queryArgs := s.buildQuery(ctx, withTenant(ctx, args))
+
+	zerolog.Ctx(ctx).Debug().Str("query", q).Interface("queryArgs", queryArgs).Msg(op)
+
+	err = s.db.SelectContext(ctx, &subs, q, queryArgs...)
+	if err != nil {
+		return nil, e.Wrap(op, translateError(err))
+	}
+	return subs, nil
+}
+
+// QueryEach calls fn for every subscription of the query, while rows are
+// read from the database, so results are not buffered in memory.
+func (s *SubscriptionsStore) QueryEach(ctx context.Context, args *storage.QueryArgs, fn func(sub *microservice.Subscription) error) (err error) {
+	const op = "storage.postgresql.subscriptions.queryeach"
+	defer observe(op, time.Now(), &err)
+	q, queryArgs := s.buildQuery(ctx, withTenant(ctx, args))
+
+	zerolog.Ctx(ctx).Debug().Str("query", q).Interface("queryArgs", queryArgs).Msg(op)
+
+	rows, err := s.db.QueryxContext(ctx, q, queryArgs...)
+	if err != nil {
+		return e.Wrap(op, translateError(err))
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		sub := &microservice.Subscription{}
+		if err = rows.StructScan(sub); err != nil {
+			return e.Wrap(op, translateError(err))
+		}
+		if err = fn(sub); err != nil {
+			return err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return e.Wrap(op, translateError(err))
+	}
+	return nil
+}
+
+func (s *SubscriptionsStore) buildQuery(ctx context.Context, args *storage.QueryArgs) (q string, queryArgs []any) {
+	q = sprintf(`SELECT * FROM %s `, TableSubscriptions)
 
 	// Custom handling for where statement
-	where, queryArgs := s.builder.buildWhere(args)
+	where, queryArgs := s.builder.buildWhere(ctx, args)
 	q += where
 
 	// For other use builder
-	queryEnd, queryArgs2 := s.builder.buildParts([]string{"group_by", "order_by", "limit"}, args, 2)
+	queryEnd, queryArgs2 := s.builder.buildParts(ctx, []string{"group_by", "order_by", "limit"}, args, 2)
 	q += queryEnd
 	queryArgs = append(queryArgs, queryArgs2...)
 
-	log.Debug().Str("query", q).Interface("queryArgs", queryArgs).Msg(op)
-
-	err = s.db.SelectContext(ctx, &subs, q, queryArgs...)
-	if err != nil {
-		return nil, e.Wrap(op, err)
-	}
-	return subs, nil
+	return q, queryArgs
 }
 
 func (s *SubscriptionsStore) Sum(ctx context.Context, args *storage.QueryArgs) (sum microservice.Price, err error) {
 	const op = "storage.postgresql.subscriptions.sum"
-	q := sprintf(`SELECT sum(monthly_price) FROM %s AS sum `, TableSubscriptions)
+	defer observe(op, time.Now(), &err)
+	price, from, args := s.priceSource(withTenant(ctx, args))
+	q := sprintf(`SELECT sum(%s) AS sum FROM %s `, price, from)
 
-	where, queryArgs := s.builder.buildWhere(args)
+	where, queryArgs := s.builder.buildWhere(ctx, args)
 	q += where
 
-	log.Debug().Str("query", q).Interface("queryArgs", queryArgs).Msg(op)
+	zerolog.Ctx(ctx).Debug().Str("query", q).Interface("queryArgs", queryArgs).Msg(op)
 
-	structSum := struct {
-		Sum microservice.Price
-	}{
-		Sum: 0,
+	// Sum of no rows is NULL.
+	var total sql.NullInt64
+	err = s.db.GetContext(ctx, &total, q, queryArgs...)
+	if err != nil {
+		return 0, e.Wrap(op, translateError(err))
 	}
+	if !total.Valid {
+		return 0, storage.ErrNoSuchSubscription
+	}
+	return microservice.Price(total.Int64), nil
+}
+
+func (s *SubscriptionsStore) SumByCategory(ctx context.Context, args *storage.QueryArgs) (sums []*microservice.CategorySum, err error) {
+	const op = "storage.postgresql.subscriptions.sumbycategory"
+	defer observe(op, time.Now(), &err)
+	price, from, args := s.priceSource(withTenant(ctx, args))
+	q := sprintf(`
+		SELECT %[1]s.category_id, COALESCE(%[2]s.name, '') AS category, sum(%[3]s) AS sum
+		FROM %[4]s LEFT JOIN %[2]s ON %[2]s.id = %[1]s.category_id
+	`, TableSubscriptions, TableCategories, price, from)
+
+	where, queryArgs := s.builder.buildWhere(ctx, args)
+	q += where
+	q += sprintf(`GROUP BY %[1]s.category_id, %[2]s.name ORDER BY sum DESC`, TableSubscriptions, TableCategories)
 
-	err = s.db.GetContext(ctx, &structSum, q, queryArgs...)
+	zerolog.Ctx(ctx).Debug().Str("query", q).Interface("queryArgs", queryArgs).Msg(op)
+
+	sums = []*microservice.CategorySum{}
+	err = s.db.SelectContext(ctx, &sums, q, queryArgs...)
 	if err != nil {
-		if e.HasText(err, "converting NULL to int is unsupported") {
-			return 0, storage.ErrNoSuchSubscription
+		return nil, e.Wrap(op, translateError(err))
+	}
+	return sums, nil
+}
+
+// withTenant returns the copy of args limited to the tenant of the context.
+// The column is qualified, as subscriptions may be joined with shares.
+func withTenant(ctx context.Context, args *storage.QueryArgs) *storage.QueryArgs {
+	scoped := storage.QueryArgs{}
+	if args != nil {
+		scoped = *args
+	}
+	scoped.Where = append([]storage.Where{{
+		Column:   TableSubscriptions + ".tenant_id",
+		Operator: storage.OpEqual,
+		Value:    tenant.FromContext(ctx),
+	}}, scoped.Where...)
+	return &scoped
+}
+
+// priceSource returns the price expression and the table to sum prices from.
+// When subscriptions are filtered by user, the user's shares of subscriptions
+// are summed instead of full prices of subscriptions owned by the user, so
+// shared subscriptions are attributed to every member.
+func (s *SubscriptionsStore) priceSource(args *storage.QueryArgs) (price string, from string, sharesArgs *storage.QueryArgs) {
+	if args == nil || !hasColumn(args.Where, "user_id") {
+		return TableSubscriptions + ".monthly_price", TableSubscriptions, args
+	}
+
+	shared := *args
+	shared.Where = make([]storage.Where, 0, len(args.Where))
+	for _, w := range args.Where {
+		if w.Column == "user_id" {
+			w.Column = "share_user_id"
 		}
-		return 0, e.Wrap(op, err)
+		shared.Where = append(shared.Where, w)
 	}
-	return structSum.Sum, nil
+
+	from = sprintf(`%[1]s JOIN %[2]s ON %[2]s.subscription_id = %[1]s.id`, TableSubscriptions, ViewSubscriptionShares)
+	return ViewSubscriptionShares + ".share_amount", from, &shared
+}
+
+// observe records latency and failure of the operation started at start,
+// missing subscriptions are expected and aren't failures.
+func observe(op string, start time.Time, err *error) {
+	metrics.ObserveStorage(op, start, *err, storage.ErrNoSuchSubscription)
 }
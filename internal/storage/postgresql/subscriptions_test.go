@@ -1,6 +1,8 @@
 package postgresql
 
 import (
+	"database/sql"
+	"errors"
 	"testing"
 	"time"
 
@@ -8,9 +10,11 @@ import (
 	"github.com/google/uuid"
 	"github.com/ikotiki/sqlbuilder"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/storage"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/tenant"
 	"github.com/ikotiki/go-rest-api-service-subscriptions/logger"
 )
 
@@ -57,7 +61,7 @@ func TestSubscriptions_Create(t *testing.T) {
 			name: "Ok",
 			mock: func() {
 				rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
-				mock.ExpectQuery("INSERT INTO subscriptions (user_id, service_name, monthly_price, start_date, end_date) VALUES ($1, $2, $3, $4, $5) RETURNING id").
+				mock.ExpectQuery("INSERT INTO subscriptions (user_id, service_id, service_name, category_id, monthly_price, start_date, end_date, tenant_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id").
 					WillReturnRows(rows)
 			},
 			input: &storage.Subscription{
@@ -86,6 +90,122 @@ func TestSubscriptions_Create(t *testing.T) {
 		})
 	}
 }
+func TestSubscriptions_Upsert(t *testing.T) {
+	dbStore, db, mock, err := newMockSQLStorage()
+	if err != nil {
+		t.Fatal("can't crate mock storage", err)
+	}
+	defer db.Close()
+	st := NewSubscriptionsStore(dbStore)
+
+	const q = "INSERT INTO subscriptions (user_id, service_id, service_name, category_id, monthly_price, start_date, end_date, tenant_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) " +
+		"ON CONFLICT (tenant_id, user_id, service_name) WHERE end_date IS NULL DO UPDATE SET (service_id, category_id, monthly_price, start_date, end_date) = " +
+		"(EXCLUDED.service_id, EXCLUDED.category_id, EXCLUDED.monthly_price, EXCLUDED.start_date, EXCLUDED.end_date) RETURNING id, (xmax = 0) AS created"
+
+	tests := []struct {
+		name        string
+		mock        func()
+		want        storage.SubscriptionID
+		wantCreated bool
+		wantErr     error
+	}{
+		{
+			name: "Ok (created)",
+			mock: func() {
+				mock.ExpectQuery(q).WillReturnRows(sqlmock.NewRows([]string{"id", "created"}).AddRow(3, true))
+			},
+			want:        3,
+			wantCreated: true,
+		},
+		{
+			name: "Ok (updated)",
+			mock: func() {
+				mock.ExpectQuery(q).WillReturnRows(sqlmock.NewRows([]string{"id", "created"}).AddRow(1, false))
+			},
+			want: 1,
+		},
+		{
+			name: "Error (overlap)",
+			mock: func() {
+				mock.ExpectQuery(q).WillReturnError(&pq.Error{Code: codeExclusionViolation, Constraint: "subscriptions_period_no_overlap"})
+			},
+			wantErr: storage.ErrUserSubscriptionPairAlreadyExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, created, err := st.Upsert(t.Context(), &storage.Subscription{
+				UserID:       uuid.MustParse("123e4567-e89b-12d3-a456-426614174000"),
+				ServiceName:  "Yandex Taxi",
+				MonthlyPrice: 400,
+				StartDate:    test_time,
+			})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+				assert.Equal(t, tt.wantCreated, created)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSubscriptions_CreateMany(t *testing.T) {
+	dbStore, db, mock, err := newMockSQLStorage()
+	if err != nil {
+		t.Fatal("can't crate mock storage", err)
+	}
+	defer db.Close()
+	st := NewSubscriptionsStore(dbStore)
+
+	sub := &storage.Subscription{
+		UserID:       uuid.MustParse("123e4567-e89b-12d3-a456-426614174000"),
+		ServiceName:  "Yandex Taxi",
+		MonthlyPrice: 400,
+		StartDate:    test_time,
+	}
+
+	rows := sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2)
+	mock.ExpectQuery("WITH items AS ( SELECT nextval(pg_get_serial_sequence('subscriptions', 'id')) AS id, t.* "+
+		"FROM unnest($1::uuid[], $2::integer[], $3::text[], $4::integer[], $5::integer[], $6::timestamp[], $7::timestamp[]) "+
+		"WITH ORDINALITY AS t(user_id, service_id, service_name, category_id, monthly_price, start_date, end_date, ord) "+
+		"), inserted AS ( INSERT INTO subscriptions (id, user_id, service_id, service_name, category_id, monthly_price, start_date, end_date, tenant_id) "+
+		"SELECT id, user_id, service_id, service_name, category_id, monthly_price, start_date, end_date, $8 FROM items RETURNING id ) "+
+		"SELECT items.id FROM items JOIN inserted ON inserted.id = items.id ORDER BY items.ord").
+		WithArgs(`{"123e4567-e89b-12d3-a456-426614174000","123e4567-e89b-12d3-a456-426614174000"}`, "{NULL,NULL}",
+			`{"Yandex Taxi","Yandex Taxi"}`, "{NULL,NULL}", "{400,400}", sqlmock.AnyArg(), "{NULL,NULL}", "default").
+		WillReturnRows(rows)
+
+	got, err := st.CreateMany(t.Context(), []*storage.Subscription{sub, sub})
+	assert.NoError(t, err)
+	assert.Equal(t, []storage.SubscriptionID{1, 2}, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSubscriptions_DeleteMany(t *testing.T) {
+	dbStore, db, mock, err := newMockSQLStorage()
+	if err != nil {
+		t.Fatal("can't crate mock storage", err)
+	}
+	defer db.Close()
+	st := NewSubscriptionsStore(dbStore)
+
+	rows := sqlmock.NewRows([]string{"id"}).AddRow(2)
+	mock.ExpectQuery("DELETE FROM subscriptions WHERE id = ANY($1) AND tenant_id = $2 RETURNING id").
+		WithArgs(pq.Int64Array{1, 2}, "default").
+		WillReturnRows(rows)
+
+	got, err := st.DeleteMany(t.Context(), []storage.SubscriptionID{1, 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []storage.SubscriptionID{2}, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestSubscriptions_GetByID(t *testing.T) {
 	dbStore, db, mock, err := newMockSQLStorage()
 	if err != nil {
@@ -99,7 +219,7 @@ func TestSubscriptions_GetByID(t *testing.T) {
 		mock    func()
 		input   storage.SubscriptionID
 		want    *storage.Subscription
-		wantErr bool
+		wantErr error
 	}{
 		{
 			name: "Ok",
@@ -112,7 +232,7 @@ func TestSubscriptions_GetByID(t *testing.T) {
 					test_time,
 					test_time.Add(time.Hour))
 
-				mock.ExpectQuery("SELECT * FROM subscriptions WHERE id = $1").
+				mock.ExpectQuery("SELECT * FROM subscriptions WHERE id = $1 AND tenant_id = $2").
 					WillReturnRows(rows)
 			},
 			input: 1,
@@ -125,6 +245,24 @@ func TestSubscriptions_GetByID(t *testing.T) {
 				EndDate:      test_time.Add(time.Hour),
 			},
 		},
+		{
+			name: "Error (not found)",
+			mock: func() {
+				mock.ExpectQuery("SELECT * FROM subscriptions WHERE id = $1 AND tenant_id = $2").
+					WillReturnError(sql.ErrNoRows)
+			},
+			input:   2,
+			wantErr: storage.ErrNoSuchSubscription,
+		},
+		{
+			name: "Error (canceled)",
+			mock: func() {
+				mock.ExpectQuery("SELECT * FROM subscriptions WHERE id = $1 AND tenant_id = $2").
+					WillReturnError(&pq.Error{Code: codeQueryCanceled, Message: "canceling statement due to statement timeout"})
+			},
+			input:   3,
+			wantErr: storage.ErrQueryCanceled,
+		},
 	}
 
 	for _, tt := range tests {
@@ -132,8 +270,8 @@ func TestSubscriptions_GetByID(t *testing.T) {
 			tt.mock()
 
 			got, err := st.GetByID(t.Context(), tt.input)
-			if tt.wantErr {
-				assert.Error(t, err)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, tt.want, got)
@@ -143,6 +281,54 @@ func TestSubscriptions_GetByID(t *testing.T) {
 	}
 }
 
+func TestSubscriptions_GetByIDForUpdate(t *testing.T) {
+	dbStore, db, mock, err := newMockSQLStorage()
+	if err != nil {
+		t.Fatal("can't crate mock storage", err)
+	}
+	defer db.Close()
+	st := NewSubscriptionsStore(dbStore)
+
+	rows := sqlmock.NewRows([]string{"id", "user_id", "service_name", "monthly_price", "start_date"}).
+		AddRow(1, uuid.MustParse("123e4567-e89b-12d3-a456-426614174000"), "Yandex Taxi", 400, test_time)
+	mock.ExpectQuery("SELECT * FROM subscriptions WHERE id = $1 AND tenant_id = $2 FOR UPDATE").
+		WithArgs(1, "default").
+		WillReturnRows(rows)
+	mock.ExpectQuery("SELECT * FROM subscriptions WHERE id = $1 AND tenant_id = $2 FOR UPDATE").
+		WithArgs(2, "default").
+		WillReturnError(sql.ErrNoRows)
+
+	got, err := st.GetByIDForUpdate(t.Context(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, storage.SubscriptionID(1), got.ID)
+
+	_, err = st.GetByIDForUpdate(t.Context(), 2)
+	assert.ErrorIs(t, err, storage.ErrNoSuchSubscription)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSubscriptions_GetManyForUpdate(t *testing.T) {
+	dbStore, db, mock, err := newMockSQLStorage()
+	if err != nil {
+		t.Fatal("can't crate mock storage", err)
+	}
+	defer db.Close()
+	st := NewSubscriptionsStore(dbStore)
+
+	rows := sqlmock.NewRows([]string{"id", "user_id", "service_name", "monthly_price", "start_date"}).
+		AddRow(1, uuid.MustParse("123e4567-e89b-12d3-a456-426614174000"), "Yandex Taxi", 400, test_time)
+	mock.ExpectQuery("SELECT * FROM subscriptions WHERE id = ANY($1) AND tenant_id = $2 ORDER BY id FOR UPDATE").
+		WithArgs(pq.Int64Array{1, 2}, "default").
+		WillReturnRows(rows)
+
+	got, err := st.GetManyForUpdate(t.Context(), []storage.SubscriptionID{1, 2})
+	assert.NoError(t, err)
+	if assert.Len(t, got, 1) {
+		assert.Equal(t, storage.SubscriptionID(1), got[0].ID)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestSubscriptions_Update(t *testing.T) {
 	dbStore, db, mock, err := newMockSQLStorage()
 	if err != nil {
@@ -172,7 +358,7 @@ func TestSubscriptions_Update(t *testing.T) {
 				// 	test_time,
 				// 	test_time.Add(4*time.Hour))
 
-				mock.ExpectExec(`UPDATE subscriptions SET (service_name, monthly_price, start_date, end_date) = ($2, $3, $4, $5) WHERE id = $1`).
+				mock.ExpectExec(`UPDATE subscriptions SET (service_id, service_name, category_id, monthly_price, start_date, end_date) = ($2, $3, $4, $5, $6, $7) WHERE id = $1 AND tenant_id = $8`).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 			},
 			input: &storage.Subscription{
@@ -184,6 +370,21 @@ func TestSubscriptions_Update(t *testing.T) {
 				EndDate:      test_time.Add(4 * time.Hour),
 			},
 		},
+		{
+			name: "Error (overlapping period)",
+			mock: func() {
+				mock.ExpectExec(`UPDATE subscriptions SET (service_id, service_name, category_id, monthly_price, start_date, end_date) = ($2, $3, $4, $5, $6, $7) WHERE id = $1 AND tenant_id = $8`).
+					WillReturnError(&pq.Error{Code: codeExclusionViolation, Constraint: "subscriptions_period_no_overlap"})
+			},
+			input: &storage.Subscription{
+				ID:           1,
+				UserID:       uuid.MustParse("123e4567-e89b-12d3-a456-426614174000"),
+				ServiceName:  "Yandex Taxi",
+				MonthlyPrice: 400,
+				StartDate:    test_time.Add(time.Hour),
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -211,9 +412,9 @@ func TestSubscriptions_Query(t *testing.T) {
 
 	time_day := time.Hour * 24
 	subs := []*storage.Subscription{
-		{1, uuid.MustParse("123e4567-e89b-12d3-a456-426614174000"), "Yandex Taxi", 400, test_time, test_time.Add(2 * time_day)},
-		{2, uuid.MustParse("123e4567-e89b-12d3-a456-426614174001"), "Sberbank Shop", 200, test_time.Add(-120 * time_day), test_time.Add(-90 * time_day)},
-		{3, uuid.MustParse("123e4567-e89b-12d3-a456-426614174002"), "Ozon Sales", 360, test_time.Add(-60 * time_day), test_time.Add(-30 * time_day)},
+		{ID: 1, UserID: uuid.MustParse("123e4567-e89b-12d3-a456-426614174000"), ServiceName: "Yandex Taxi", MonthlyPrice: 400, StartDate: test_time, EndDate: test_time.Add(2 * time_day)},
+		{ID: 2, UserID: uuid.MustParse("123e4567-e89b-12d3-a456-426614174001"), ServiceName: "Sberbank Shop", MonthlyPrice: 200, StartDate: test_time.Add(-120 * time_day), EndDate: test_time.Add(-90 * time_day)},
+		{ID: 3, UserID: uuid.MustParse("123e4567-e89b-12d3-a456-426614174002"), ServiceName: "Ozon Sales", MonthlyPrice: 360, StartDate: test_time.Add(-60 * time_day), EndDate: test_time.Add(-30 * time_day)},
 	}
 
 	tests := []struct {
@@ -230,7 +431,8 @@ func TestSubscriptions_Query(t *testing.T) {
 				for _, sub := range subs {
 					rows.AddRow(sub.ID, sub.UserID, sub.ServiceName, sub.MonthlyPrice, sub.StartDate, sub.EndDate)
 				}
-				mock.ExpectQuery("SELECT * FROM subscriptions").
+				mock.ExpectQuery("SELECT * FROM subscriptions WHERE (subscriptions.tenant_id = $1)").
+					WithArgs("default").
 					WillReturnRows(rows)
 			},
 			input: &storage.QueryArgs{},
@@ -243,17 +445,35 @@ func TestSubscriptions_Query(t *testing.T) {
 				sub := subs[0]
 				rows.AddRow(sub.ID, sub.UserID, sub.ServiceName, sub.MonthlyPrice, sub.StartDate, sub.EndDate)
 
-				mock.ExpectQuery("SELECT * FROM subscriptions WHERE (start_date >= $1) AND (end_date IS NULL AND end_date <= $2)").
+				mock.ExpectQuery("SELECT * FROM subscriptions WHERE (subscriptions.tenant_id = $1) AND (start_date >= $2) AND (end_date IS NOT NULL AND end_date <= $3)").
 					WillReturnRows(rows)
 			},
 			input: &storage.QueryArgs{
 				Where: []storage.Where{
-					{"start_date", ">=", test_time.Add(-10 * time_day)},
-					{"end_date", "<=", test_time.Add(10 * time_day)},
+					{Column: "start_date", Operator: ">=", Value: test_time.Add(-10 * time_day)},
+					{Column: "end_date", Operator: "<=", Value: test_time.Add(10 * time_day)},
 				},
 			},
 			want: []*storage.Subscription{subs[0]},
 		},
+		{
+			name: "Ok (In)",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"id", "user_id", "service_name", "monthly_price", "start_date", "end_date"})
+				sub := subs[1]
+				rows.AddRow(sub.ID, sub.UserID, sub.ServiceName, sub.MonthlyPrice, sub.StartDate, sub.EndDate)
+
+				mock.ExpectQuery("SELECT * FROM subscriptions WHERE (subscriptions.tenant_id = $1) AND (service_name = ANY($2))").
+					WithArgs("default", `{"Sberbank Shop","Ozon Sales"}`).
+					WillReturnRows(rows)
+			},
+			input: &storage.QueryArgs{
+				Where: []storage.Where{
+					{Column: "service_name", Operator: storage.OpIn, Value: []string{"Sberbank Shop", "Ozon Sales"}},
+				},
+			},
+			want: []*storage.Subscription{subs[1]},
+		},
 	}
 
 	for _, tt := range tests {
@@ -273,6 +493,36 @@ func TestSubscriptions_Query(t *testing.T) {
 	}
 }
 
+func TestSubscriptions_QueryEach(t *testing.T) {
+	dbStore, db, mock, err := newMockSQLStorage()
+	if err != nil {
+		t.Fatal("can't crate mock storage", err)
+	}
+	defer db.Close()
+	st := NewSubscriptionsStore(dbStore)
+
+	userID := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
+	rows := sqlmock.NewRows([]string{"id", "user_id", "service_name", "monthly_price", "start_date", "end_date"}).
+		AddRow(1, userID, "Yandex Taxi", 400, test_time, nil).
+		AddRow(2, userID, "Ozon Sales", 300, test_time, nil).
+		AddRow(3, userID, "Sberbank Shop", 200, test_time, nil)
+	mock.ExpectQuery("SELECT * FROM subscriptions WHERE (subscriptions.tenant_id = $1)").WithArgs("default").WillReturnRows(rows)
+
+	// The error of the callback stops iteration.
+	stop := errors.New("stop")
+	ids := []storage.SubscriptionID{}
+	err = st.QueryEach(t.Context(), &storage.QueryArgs{}, func(sub *storage.Subscription) error {
+		ids = append(ids, sub.ID)
+		if len(ids) == 2 {
+			return stop
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, []storage.SubscriptionID{1, 2}, ids)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestSubscriptions_Sum(t *testing.T) {
 	dbStore, db, mock, err := newMockSQLStorage()
 	if err != nil {
@@ -301,7 +551,7 @@ func TestSubscriptions_Sum(t *testing.T) {
 				rows := sqlmock.NewRows([]string{"sum"})
 				rows.AddRow(900)
 
-				mock.ExpectQuery("SELECT sum(monthly_price) FROM subscriptions AS sum").
+				mock.ExpectQuery("SELECT sum(subscriptions.monthly_price) AS sum FROM subscriptions WHERE (subscriptions.tenant_id = $1)").
 					WillReturnRows(rows)
 			},
 			input: &storage.QueryArgs{},
@@ -313,35 +563,70 @@ func TestSubscriptions_Sum(t *testing.T) {
 				rows := sqlmock.NewRows([]string{"sum"})
 				rows.AddRow(700)
 
-				mock.ExpectQuery("SELECT sum(monthly_price) FROM subscriptions AS sum WHERE (start_date >= $1) AND (end_date IS NULL AND end_date <= $2)").
+				mock.ExpectQuery("SELECT sum(subscriptions.monthly_price) AS sum FROM subscriptions WHERE (subscriptions.tenant_id = $1) AND (start_date >= $2) AND (end_date IS NOT NULL AND end_date <= $3)").
 					WillReturnRows(rows)
 			},
 			input: &storage.QueryArgs{
 				Where: []storage.Where{
-					{"start_date", ">=", test_time.Add(-100 * time_day)},
-					{"end_date", "<=", test_time.Add(10 * time_day)},
+					{Column: "start_date", Operator: ">=", Value: test_time.Add(-100 * time_day)},
+					{Column: "end_date", Operator: "<=", Value: test_time.Add(10 * time_day)},
 				},
 			},
 			want: 700,
 		},
+		{
+			name: "Ok (User shares)",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"sum"})
+				rows.AddRow(250)
+
+				mock.ExpectQuery("SELECT sum(subscription_shares.share_amount) AS sum "+
+					"FROM subscriptions JOIN subscription_shares ON subscription_shares.subscription_id = subscriptions.id "+
+					"WHERE (subscriptions.tenant_id = $1) AND (share_user_id = $2)").
+					WithArgs("default", "123e4567-e89b-12d3-a456-426614174000").
+					WillReturnRows(rows)
+			},
+			input: &storage.QueryArgs{
+				Where: []storage.Where{
+					{Column: "user_id", Operator: storage.OpEqual, Value: "123e4567-e89b-12d3-a456-426614174000"},
+				},
+			},
+			want: 250,
+		},
 		{
 			name: "Error (Date range)",
 			mock: func() {
 				rows := sqlmock.NewRows([]string{"sum"})
 				rows.AddRow(0)
 
-				mock.ExpectQuery("SELECT sum(monthly_price) FROM subscriptions AS sum WHERE (start_date >= $1) AND (end_date IS NULL AND end_date <= $2)").
+				mock.ExpectQuery("SELECT sum(subscriptions.monthly_price) AS sum FROM subscriptions WHERE (subscriptions.tenant_id = $1) AND (start_date >= $2) AND (end_date IS NOT NULL AND end_date <= $3)").
 					WillReturnRows(rows)
 
 			},
 			input: &storage.QueryArgs{
 				Where: []storage.Where{
-					{"start_date", ">=", test_time.Add(20 * time_day)},
-					{"end_date", "<=", test_time.Add(100 * time_day)},
+					{Column: "start_date", Operator: ">=", Value: test_time.Add(20 * time_day)},
+					{Column: "end_date", Operator: "<=", Value: test_time.Add(100 * time_day)},
 				},
 			},
 			want: 0,
 		},
+		{
+			name: "Error (no subscriptions)",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"sum"})
+				rows.AddRow(nil)
+
+				mock.ExpectQuery("SELECT sum(subscriptions.monthly_price) AS sum FROM subscriptions WHERE (subscriptions.tenant_id = $1) AND (service_name = $2)").
+					WillReturnRows(rows)
+			},
+			input: &storage.QueryArgs{
+				Where: []storage.Where{
+					{Column: "service_name", Operator: storage.OpEqual, Value: "Unknown"},
+				},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -360,3 +645,92 @@ func TestSubscriptions_Sum(t *testing.T) {
 		})
 	}
 }
+
+func TestSubscriptions_SumByCategory(t *testing.T) {
+	dbStore, db, mock, err := newMockSQLStorage()
+	if err != nil {
+		t.Fatal("can't crate mock storage", err)
+	}
+	defer db.Close()
+	st := NewSubscriptionsStore(dbStore)
+
+	streaming := storage.CategoryID(1)
+	const q = "SELECT subscriptions.category_id, COALESCE(categories.name, '') AS category, sum(subscriptions.monthly_price) AS sum " +
+		"FROM subscriptions LEFT JOIN categories ON categories.id = subscriptions.category_id "
+
+	tests := []struct {
+		name    string
+		mock    func()
+		input   *storage.QueryArgs
+		want    []*storage.CategorySum
+		wantErr bool
+	}{
+		{
+			name: "Ok (All)",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"category_id", "category", "sum"}).
+					AddRow(1, "streaming", 900).
+					AddRow(nil, "", 300)
+				mock.ExpectQuery(q + "WHERE (subscriptions.tenant_id = $1) GROUP BY subscriptions.category_id, categories.name ORDER BY sum DESC").
+					WillReturnRows(rows)
+			},
+			input: &storage.QueryArgs{},
+			want: []*storage.CategorySum{
+				{CategoryID: &streaming, Category: "streaming", Sum: 900},
+				{CategoryID: nil, Category: "", Sum: 300},
+			},
+		},
+		{
+			name: "Ok (Tag)",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"category_id", "category", "sum"}).
+					AddRow(1, "streaming", 400)
+				mock.ExpectQuery(q+"WHERE (subscriptions.tenant_id = $1) AND (subscriptions.id IN (SELECT st.subscription_id FROM subscription_tags AS st JOIN tags AS t ON t.id = st.tag_id WHERE t.name = $2)) "+
+					"GROUP BY subscriptions.category_id, categories.name ORDER BY sum DESC").
+					WithArgs("default", "family").
+					WillReturnRows(rows)
+			},
+			input: &storage.QueryArgs{
+				Where: []storage.Where{
+					{Column: storage.ColumnTag, Operator: storage.OpEqual, Value: "family"},
+				},
+			},
+			want: []*storage.CategorySum{
+				{CategoryID: &streaming, Category: "streaming", Sum: 400},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := st.SumByCategory(t.Context(), tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSubscriptions_Tenant(t *testing.T) {
+	dbStore, db, mock, err := newMockSQLStorage()
+	if err != nil {
+		t.Fatal("can't crate mock storage", err)
+	}
+	defer db.Close()
+	st := NewSubscriptionsStore(dbStore)
+
+	// Subscriptions of other tenants are missing.
+	mock.ExpectExec("DELETE FROM subscriptions WHERE id = $1 AND tenant_id = $2").
+		WithArgs(1, "acme").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = st.DeleteByID(tenant.WithID(t.Context(), "acme"), 1)
+	assert.ErrorIs(t, err, storage.ErrNoSuchSubscription)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
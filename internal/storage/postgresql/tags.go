@@ -0,0 +1,112 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+
+	microservice "github.com/ikotiki/go-rest-api-service-subscriptions"
+	"github.com/rs/zerolog"
+
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/storage"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/tenant"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/pkg/e"
+
+	"github.com/lib/pq"
+)
+
+// TagsStore keeps tags per tenant, tags are attached only to subscriptions
+// of the tenant of the context.
+type TagsStore struct {
+	db dbtx
+}
+
+func NewTagsStore(store *SQLStorage) *TagsStore {
+	return &TagsStore{db: store.conn()}
+}
+
+func (s *TagsStore) AddToSubscription(ctx context.Context, id microservice.SubscriptionID, userID microservice.UserID, names []string) (err error) {
+	const op = "storage.postgresql.tags.addtosubscription"
+	// Tags are created and attached within a single statement,
+	// so a tag can't be left detached.
+	q := sprintf(`
+		WITH subscription AS (
+			SELECT id FROM %[3]s WHERE id = $1 AND tenant_id = $4
+		), created AS (
+			INSERT INTO %[1]s (tenant_id, user_id, name) SELECT $4, $2, unnest($3::text[])
+			WHERE EXISTS (SELECT 1 FROM subscription)
+			ON CONFLICT (tenant_id, user_id, name) DO NOTHING
+			RETURNING id
+		)
+		INSERT INTO %[2]s (subscription_id, tag_id)
+		SELECT subscription.id, tags.id FROM subscription, (
+			SELECT id FROM created
+			UNION
+			SELECT id FROM %[1]s WHERE tenant_id = $4 AND user_id = $2 AND name = ANY($3::text[])
+		) AS tags
+		ON CONFLICT DO NOTHING
+	`, TableTags, TableSubscriptionTags, TableSubscriptions)
+
+	zerolog.Ctx(ctx).Debug().Str("query", q).Int("id", int(id)).Strs("tags", names).Msg(op)
+
+	_, err = s.db.ExecContext(ctx, q, id, userID, pq.StringArray(names), tenant.FromContext(ctx))
+	return e.WrapIfErr(op, translateError(err))
+}
+
+func (s *TagsStore) RemoveFromSubscription(ctx context.Context, id microservice.SubscriptionID, name string) (err error) {
+	const op = "storage.postgresql.tags.removefromsubscription"
+	q := sprintf(`
+		DELETE FROM %s WHERE subscription_id = $1
+		AND tag_id IN (SELECT id FROM %s WHERE tenant_id = $3 AND name = $2)
+		AND subscription_id IN (SELECT id FROM %s WHERE tenant_id = $3)
+	`, TableSubscriptionTags, TableTags, TableSubscriptions)
+
+	zerolog.Ctx(ctx).Debug().Str("query", q).Int("id", int(id)).Str("tag", name).Msg(op)
+
+	res, err := s.db.ExecContext(ctx, q, id, name, tenant.FromContext(ctx))
+	if err != nil {
+		return e.Wrap(op, translateError(err))
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return e.Wrap(fmt.Sprintf("%s.rows_affected", op), err)
+	}
+	if n == 0 {
+		return e.Wrap(op, storage.ErrNoSuchTag)
+	}
+
+	return nil
+}
+
+func (s *TagsStore) ListBySubscription(ctx context.Context, id microservice.SubscriptionID) (tags []*microservice.Tag, err error) {
+	const op = "storage.postgresql.tags.listbysubscription"
+	q := sprintf(`
+		SELECT t.id, t.user_id, t.name FROM %s AS t
+		JOIN %s AS st ON st.tag_id = t.id
+		JOIN %s AS s ON s.id = st.subscription_id
+		WHERE st.subscription_id = $1 AND s.tenant_id = $2 AND t.tenant_id = $2
+		ORDER BY t.name ASC
+	`, TableTags, TableSubscriptionTags, TableSubscriptions)
+
+	zerolog.Ctx(ctx).Debug().Str("query", q).Int("id", int(id)).Msg(op)
+
+	tags = []*microservice.Tag{}
+	err = s.db.SelectContext(ctx, &tags, q, id, tenant.FromContext(ctx))
+	if err != nil {
+		return nil, e.Wrap(op, translateError(err))
+	}
+	return tags, nil
+}
+
+func (s *TagsStore) ListByUser(ctx context.Context, userID microservice.UserID) (tags []*microservice.Tag, err error) {
+	const op = "storage.postgresql.tags.listbyuser"
+	q := sprintf(`SELECT id, user_id, name FROM %s WHERE tenant_id = $2 AND user_id = $1 ORDER BY name ASC`, TableTags)
+
+	zerolog.Ctx(ctx).Debug().Str("query", q).Str("user_id", userID.String()).Msg(op)
+
+	tags = []*microservice.Tag{}
+	err = s.db.SelectContext(ctx, &tags, q, userID, tenant.FromContext(ctx))
+	if err != nil {
+		return nil, e.Wrap(op, translateError(err))
+	}
+	return tags, nil
+}
@@ -0,0 +1,116 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/tracing"
+	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// rowsAffectedKey counts rows changed by the statement, returned rows are
+// counted by db.response.returned_rows.
+const rowsAffectedKey = attribute.Key("db.response.rows_affected")
+
+// tracedDB starts a span of every SQL statement with its text. Returned
+// and affected rows are counted, except for cursors, whose rows are read
+// after the span ends. Spans of single rows end, when callers scan them.
+type tracedDB struct {
+	db dbtx
+}
+
+// start starts the span named by the statement keyword, like SELECT. The
+// text is collapsed into a single line.
+func (t tracedDB) start(ctx context.Context, query string) (context.Context, trace.Span) {
+	name := "SQL"
+	fields := strings.Fields(query)
+	if len(fields) > 0 {
+		name = strings.ToUpper(fields[0])
+	}
+	return tracing.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		semconv.DBSystemNamePostgreSQL,
+		semconv.DBOperationName(name),
+		semconv.DBQueryText(strings.Join(fields, " ")),
+	))
+}
+
+// end ends the span like tracing.End, but missing rows aren't failures of
+// statements, they are reported by stores as missing entities.
+func end(span trace.Span, err *error) {
+	if errors.Is(*err, sql.ErrNoRows) {
+		span.SetAttributes(semconv.DBResponseReturnedRows(0))
+		span.End()
+		return
+	}
+	tracing.End(span, err)
+}
+
+func (t tracedDB) ExecContext(ctx context.Context, query string, args ...any) (res sql.Result, err error) {
+	ctx, span := t.start(ctx, query)
+	defer tracing.End(span, &err)
+
+	res, err = t.db.ExecContext(ctx, query, args...)
+	if err == nil {
+		if n, err := res.RowsAffected(); err == nil {
+			span.SetAttributes(rowsAffectedKey.Int64(n))
+		}
+	}
+	return res, err
+}
+
+func (t tracedDB) GetContext(ctx context.Context, dest any, query string, args ...any) (err error) {
+	ctx, span := t.start(ctx, query)
+	defer end(span, &err)
+
+	if err = t.db.GetContext(ctx, dest, query, args...); err == nil {
+		span.SetAttributes(semconv.DBResponseReturnedRows(1))
+	}
+	return err
+}
+
+func (t tracedDB) SelectContext(ctx context.Context, dest any, query string, args ...any) (err error) {
+	ctx, span := t.start(ctx, query)
+	defer tracing.End(span, &err)
+
+	if err = t.db.SelectContext(ctx, dest, query, args...); err == nil {
+		if v := reflect.Indirect(reflect.ValueOf(dest)); v.Kind() == reflect.Slice {
+			span.SetAttributes(semconv.DBResponseReturnedRows(v.Len()))
+		}
+	}
+	return err
+}
+
+// QueryRowxContext returns the row, whose span ends once it's scanned, so
+// errors of the statement, which are returned by Scan, are recorded.
+func (t tracedDB) QueryRowxContext(ctx context.Context, query string, args ...any) *tracedRow {
+	ctx, span := t.start(ctx, query)
+	return &tracedRow{row: t.db.QueryRowxContext(ctx, query, args...), span: span}
+}
+
+func (t tracedDB) QueryxContext(ctx context.Context, query string, args ...any) (rows *sqlx.Rows, err error) {
+	ctx, span := t.start(ctx, query)
+	defer tracing.End(span, &err)
+
+	return t.db.QueryxContext(ctx, query, args...)
+}
+
+// tracedRow is a single row of the statement, which span is still open.
+type tracedRow struct {
+	row  *sqlx.Row
+	span trace.Span
+}
+
+func (r *tracedRow) Scan(dest ...any) (err error) {
+	defer end(r.span, &err)
+
+	if err = r.row.Scan(dest...); err == nil {
+		r.span.SetAttributes(semconv.DBResponseReturnedRows(1))
+	}
+	return err
+}
@@ -0,0 +1,73 @@
+package postgresql
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/storage"
+)
+
+func TestTracedDB(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	dbStore, db, mock, err := newMockSQLStorage()
+	require.NoError(t, err)
+	defer db.Close()
+	st := NewSubscriptionsStore(dbStore)
+
+	const del = "DELETE FROM subscriptions WHERE id = $1 AND tenant_id = $2"
+	mock.ExpectExec(del).WithArgs(1, "default").WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, st.DeleteByID(t.Context(), 1))
+
+	spans := rec.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "DELETE", spans[0].Name())
+	assert.Contains(t, spans[0].Attributes(), semconv.DBQueryText(del))
+	assert.Contains(t, spans[0].Attributes(), rowsAffectedKey.Int64(1))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTracedDB_Row(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	dbStore, db, mock, err := newMockSQLStorage()
+	require.NoError(t, err)
+	defer db.Close()
+	st := NewSubscriptionsStore(dbStore)
+
+	// Errors of statements returning a single row are returned by Scan.
+	mock.ExpectQuery("INSERT INTO subscriptions (user_id, service_id, service_name, category_id, monthly_price, start_date, end_date, tenant_id) " +
+		"VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id").WillReturnError(errors.New("connection reset"))
+	_, err = st.Create(t.Context(), &storage.Subscription{})
+	require.Error(t, err)
+
+	// Missing rows aren't failures of statements.
+	mock.ExpectQuery("SELECT * FROM subscriptions WHERE id = $1 AND tenant_id = $2").WillReturnError(sql.ErrNoRows)
+	_, err = st.GetByID(t.Context(), 1)
+	require.ErrorIs(t, err, storage.ErrNoSuchSubscription)
+
+	spans := rec.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "INSERT", spans[0].Name())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, "SELECT", spans[1].Name())
+	assert.Equal(t, codes.Unset, spans[1].Status().Code)
+	assert.Contains(t, spans[1].Attributes(), semconv.DBResponseReturnedRows(0))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
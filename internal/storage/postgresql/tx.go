@@ -0,0 +1,88 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/storage"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/pkg/e"
+)
+
+// txRetryDelay is a base delay between reruns of failed to serialize
+// transactions, it grows linearly with every attempt.
+const txRetryDelay = 10 * time.Millisecond
+
+// WithTx runs fn within a transaction of the configured isolation level.
+// Transactions failed to serialize are rerun up to TxMaxRetries times,
+// so fn should have no side effects besides the storage. Calls nested
+// into fn join the outer transaction.
+func (s *SQLStorage) WithTx(ctx context.Context, fn func(tx storage.Storage) error) (err error) {
+	if s.tx != nil {
+		return fn(NewStorage(s))
+	}
+
+	for attempt := 0; ; attempt++ {
+		err = s.runTx(ctx, fn)
+		if !errors.Is(err, storage.ErrSerialization) || attempt >= s.txMaxRetries {
+			return err
+		}
+
+		zerolog.Ctx(ctx).Debug().Err(err).Int("attempt", attempt+1).Msg("storage.postgresql.withtx: retrying transaction")
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt+1) * txRetryDelay):
+		}
+	}
+}
+
+func (s *SQLStorage) runTx(ctx context.Context, fn func(tx storage.Storage) error) (err error) {
+	const op = "storage.postgresql.withtx"
+
+	tx, err := s.db.BeginTxx(ctx, &sql.TxOptions{Isolation: s.isolation})
+	if err != nil {
+		return e.Wrap(op, translateError(err))
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	txStorage := &SQLStorage{db: s.db, tx: tx, builder: s.builder, isolation: s.isolation, txMaxRetries: s.txMaxRetries}
+	if err = fn(NewStorage(txStorage)); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			zerolog.Ctx(ctx).Error().Err(rbErr).Msg(op + ": can't rollback transaction")
+		}
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return e.Wrap(op, translateError(err))
+	}
+	return nil
+}
+
+func parseIsolationLevel(level string) (sql.IsolationLevel, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "", "default":
+		return sql.LevelDefault, nil
+	case "read committed":
+		return sql.LevelReadCommitted, nil
+	case "repeatable read":
+		return sql.LevelRepeatableRead, nil
+	case "serializable":
+		return sql.LevelSerializable, nil
+	default:
+		return sql.LevelDefault, fmt.Errorf("unknown transaction isolation level %q", level)
+	}
+}
@@ -0,0 +1,127 @@
+package postgresql
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/storage"
+)
+
+func TestSQLStorage_WithTx(t *testing.T) {
+	dbStore, db, mock, err := newMockSQLStorage()
+	if err != nil {
+		t.Fatal("can't crate mock storage", err)
+	}
+	defer db.Close()
+	dbStore.txMaxRetries = 1
+
+	const q = "DELETE FROM categories WHERE id = $1"
+	errFn := errors.New("fn error")
+
+	tests := []struct {
+		name    string
+		mock    func()
+		fn      func(tx storage.Storage) error
+		wantErr error
+	}{
+		{
+			name: "Ok (commit)",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(q).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			fn: func(tx storage.Storage) error {
+				return tx.Categories.DeleteByID(t.Context(), 1)
+			},
+		},
+		{
+			name: "Error (rollback)",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(q).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectRollback()
+			},
+			fn: func(tx storage.Storage) error {
+				if err := tx.Categories.DeleteByID(t.Context(), 1); err != nil {
+					return err
+				}
+				return errFn
+			},
+			wantErr: errFn,
+		},
+		{
+			name: "Ok (retry after serialization failure)",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(q).WithArgs(1).WillReturnError(&pq.Error{Code: codeSerializationFailure})
+				mock.ExpectRollback()
+				mock.ExpectBegin()
+				mock.ExpectExec(q).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			fn: func(tx storage.Storage) error {
+				return tx.Categories.DeleteByID(t.Context(), 1)
+			},
+		},
+		{
+			name: "Error (retries exhausted)",
+			mock: func() {
+				for range 2 {
+					mock.ExpectBegin()
+					mock.ExpectExec(q).WithArgs(1).WillReturnError(&pq.Error{Code: codeSerializationFailure})
+					mock.ExpectRollback()
+				}
+			},
+			fn: func(tx storage.Storage) error {
+				return tx.Categories.DeleteByID(t.Context(), 1)
+			},
+			wantErr: storage.ErrSerialization,
+		},
+		{
+			name: "Ok (nested joins outer)",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(q).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			fn: func(tx storage.Storage) error {
+				return tx.WithTx(t.Context(), func(tx storage.Storage) error {
+					return tx.Categories.DeleteByID(t.Context(), 1)
+				})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err := dbStore.WithTx(t.Context(), tt.fn)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func Test_parseIsolationLevel(t *testing.T) {
+	level, err := parseIsolationLevel("Serializable")
+	assert.NoError(t, err)
+	assert.Equal(t, sql.LevelSerializable, level)
+
+	level, err = parseIsolationLevel("")
+	assert.NoError(t, err)
+	assert.Equal(t, sql.LevelDefault, level)
+
+	_, err = parseIsolationLevel("snapshot")
+	assert.Error(t, err)
+}
@@ -1,14 +1,16 @@
 package postgresql
 
 import (
+	"context"
 	"regexp"
 	"strconv"
 	"strings"
 
 	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/storage"
-	"github.com/rs/zerolog/log"
+	"github.com/rs/zerolog"
 
 	"github.com/ikotiki/sqlbuilder/builder"
+	"github.com/lib/pq"
 )
 
 // func (s *SQLStorage) Count(ctx context.Context, args *storage.QueryArgs) (n int64, err error) {
@@ -70,32 +72,42 @@ func (s *postgresSQLBuilder) parseQueryArgs(args *storage.QueryArgs) *builder.Se
 	return &selectArgs
 }
 
-func (s *postgresSQLBuilder) buildParts(parts []string, args *storage.QueryArgs, startIndex int) (query string, queryArgs []interface{}) {
-	log.Trace().Msgf("args: %+v\n", args)
+func (s *postgresSQLBuilder) buildParts(ctx context.Context, parts []string, args *storage.QueryArgs, startIndex int) (query string, queryArgs []interface{}) {
+	zerolog.Ctx(ctx).Trace().Msgf("args: %+v\n", args)
 	if args == nil {
 		return "", []interface{}{}
 	}
 	builderArgs := s.parseQueryArgs(args)
-	log.Trace().Msgf("parseQueryArgs: args: %+v\n", builderArgs)
+	zerolog.Ctx(ctx).Trace().Msgf("parseQueryArgs: args: %+v\n", builderArgs)
 	qStr, qArgs := s.Builder.BuildParts(parts, builderArgs)
-	log.Trace().Msgf("query: `%s` args: %+v\n", qStr, qArgs)
+	zerolog.Ctx(ctx).Trace().Msgf("query: `%s` args: %+v\n", qStr, qArgs)
 	qStr = s.replacePlaceholders(qStr, startIndex)
-	log.Trace().Msgf("replaced placeholders: `%s` args: %+v\n", qStr, qArgs)
+	zerolog.Ctx(ctx).Trace().Msgf("replaced placeholders: `%s` args: %+v\n", qStr, qArgs)
 	return qStr, qArgs
 }
 
-func (s *postgresSQLBuilder) buildWhere(args *storage.QueryArgs) (whereStr string, whereArgs []interface{}) {
+func (s *postgresSQLBuilder) buildWhere(ctx context.Context, args *storage.QueryArgs) (whereStr string, whereArgs []interface{}) {
 	// Custom handling for where statement
 	where := []string{}
 	queryArgs := []interface{}{}
 	i := 1
 	for _, w := range args.Where {
-		// log.Debug().Msgf("where: %v", where)
+		// zerolog.Ctx(ctx).Debug().Msgf("where: %v", where)
 		switch w.Column {
 		case "end_date":
 			where = append(where, sprintf(`(end_date IS NOT NULL AND end_date <= $%d)`, i))
-			log.Debug().Msgf("hasColumn(args.Where, end_date): %v", hasColumn(args.Where, "end_date"))
+			zerolog.Ctx(ctx).Debug().Msgf("hasColumn(args.Where, end_date): %v", hasColumn(args.Where, "end_date"))
+		case storage.ColumnTag:
+			where = append(where, sprintf(`(%[1]s.id IN (SELECT st.subscription_id FROM %[2]s AS st JOIN %[3]s AS t ON t.id = st.tag_id WHERE t.name = $%[4]d))`,
+				TableSubscriptions, TableSubscriptionTags, TableTags, i))
 		default:
+			if w.Operator == storage.OpIn {
+				// The value is a slice, passed as a single array.
+				where = append(where, sprintf(`(%s = ANY($%d))`, w.Column, i))
+				queryArgs = append(queryArgs, pq.Array(w.Value))
+				i++
+				continue
+			}
 			where = append(where, sprintf(`(%s %s $%d)`, w.Column, w.Operator, i))
 		}
 		queryArgs = append(queryArgs, w.Value)
@@ -156,7 +156,7 @@ func Test_buildParts(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			parts := []string{"select", "from", "where", "group_by", "order_by", "limit"}
-			query, queryArgs := b.buildParts(parts, tt.input, 1)
+			query, queryArgs := b.buildParts(t.Context(), parts, tt.input, 1)
 			got := &res{
 				Query:     query,
 				QueryArgs: queryArgs,
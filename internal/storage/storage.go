@@ -7,13 +7,107 @@ import (
 type Subscriptions interface {
 	Create(ctx context.Context, sub *Subscription) (id SubscriptionID, err error)
 	GetByID(ctx context.Context, id SubscriptionID) (sub *Subscription, err error)
+	// GetByIDForUpdate returns the subscription and locks it until the end
+	// of the transaction, so checks depending on it aren't raced.
+	GetByIDForUpdate(ctx context.Context, id SubscriptionID) (sub *Subscription, err error)
+	// GetManyForUpdate returns existing subscriptions of the ids and locks
+	// them like GetByIDForUpdate, missing ones are skipped.
+	GetManyForUpdate(ctx context.Context, ids []SubscriptionID) (subs []*Subscription, err error)
 	Update(ctx context.Context, sub *Subscription) (err error)
 	DeleteByID(ctx context.Context, id SubscriptionID) (err error)
+	// CreateMany inserts all subscriptions and returns their ids in order.
+	CreateMany(ctx context.Context, subs []*Subscription) (ids []SubscriptionID, err error)
+	// DeleteMany returns ids of the deleted subscriptions.
+	DeleteMany(ctx context.Context, ids []SubscriptionID) (deleted []SubscriptionID, err error)
+	// Upsert inserts the subscription or updates the open one of the same
+	// user and service, created reports whether the row was inserted.
+	Upsert(ctx context.Context, sub *Subscription) (id SubscriptionID, created bool, err error)
 
 	Query(ctx context.Context, args *QueryArgs) (subs []*Subscription, err error)
+	QueryEach(ctx context.Context, args *QueryArgs, fn func(sub *Subscription) error) (err error)
 	Sum(ctx context.Context, args *QueryArgs) (sum Price, err error)
+	SumByCategory(ctx context.Context, args *QueryArgs) (sums []*CategorySum, err error)
+}
+
+type Services interface {
+	Create(ctx context.Context, svc *Service) (id ServiceID, err error)
+	GetByID(ctx context.Context, id ServiceID) (svc *Service, err error)
+	// GetByKey finds the service whose normalized name or one of normalized
+	// aliases equals to the key.
+	GetByKey(ctx context.Context, key string) (svc *Service, err error)
+	Update(ctx context.Context, svc *Service) (err error)
+	DeleteByID(ctx context.Context, id ServiceID) (err error)
+
+	List(ctx context.Context) (svcs []*Service, err error)
+}
+
+type Categories interface {
+	Create(ctx context.Context, cat *Category) (id CategoryID, err error)
+	GetByID(ctx context.Context, id CategoryID) (cat *Category, err error)
+	Update(ctx context.Context, cat *Category) (err error)
+	DeleteByID(ctx context.Context, id CategoryID) (err error)
+
+	List(ctx context.Context) (cats []*Category, err error)
+}
+
+type Tags interface {
+	// AddToSubscription attaches tags to the subscription, creating
+	// missing tags of the user.
+	AddToSubscription(ctx context.Context, id SubscriptionID, userID UserID, names []string) (err error)
+	RemoveFromSubscription(ctx context.Context, id SubscriptionID, name string) (err error)
+
+	ListBySubscription(ctx context.Context, id SubscriptionID) (tags []*Tag, err error)
+	ListByUser(ctx context.Context, userID UserID) (tags []*Tag, err error)
+}
+
+type Members interface {
+	// Add adds the member to the subscription or updates the share
+	// of the existing member.
+	Add(ctx context.Context, m *Member) (err error)
+	Remove(ctx context.Context, id SubscriptionID, userID UserID) (err error)
+
+	ListBySubscription(ctx context.Context, id SubscriptionID) (members []*Member, err error)
+	// ListBySubscriptions returns members of all the subscriptions at once.
+	ListBySubscriptions(ctx context.Context, ids []SubscriptionID) (members []*Member, err error)
+}
+
+type IdempotencyKeys interface {
+	// Acquire stores the record of the new request in progress. If there
+	// is a live record of the key, it's returned instead, expired records
+	// are replaced.
+	Acquire(ctx context.Context, rec *IdempotencyRecord) (existing *IdempotencyRecord, err error)
+	// Complete saves the response of the request and keeps it until
+	// ExpiresAt of the record.
+	Complete(ctx context.Context, rec *IdempotencyRecord) (err error)
+	// Release removes the record in progress, so the request can be retried.
+	Release(ctx context.Context, scope, key string) (err error)
+	DeleteExpired(ctx context.Context) (n int64, err error)
+}
+
+type APIKeys interface {
+	Create(ctx context.Context, key *APIKey) (id APIKeyID, err error)
+	// GetByHash returns the key of any tenant, unless it's revoked.
+	GetByHash(ctx context.Context, hash string) (key *APIKey, err error)
+	Revoke(ctx context.Context, id APIKeyID) (err error)
+
+	List(ctx context.Context) (keys []*APIKey, err error)
+}
+
+// Transactor runs several storage operations atomically.
+type Transactor interface {
+	// WithTx runs fn within a transaction and gives it the storage bound to
+	// the transaction. The transaction is committed, if fn returns nil,
+	// and rolled back otherwise.
+	WithTx(ctx context.Context, fn func(tx Storage) error) (err error)
 }
 
 type Storage struct {
 	Subscriptions
+	Services
+	Categories
+	Tags
+	Members
+	IdempotencyKeys
+	APIKeys
+	Transactor
 }
@@ -0,0 +1,39 @@
+// Package tenant carries the tenant of the request. Data of tenants is
+// isolated by storages, which read the tenant from the context.
+package tenant
+
+import (
+	"context"
+	"errors"
+	"regexp"
+)
+
+// Default is the tenant of requests, which don't specify one.
+const Default = "default"
+
+var ErrInvalidID = errors.New("tenant id must be 1-64 letters, digits, '-' or '_'")
+
+var idPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// Validate checks the format of the tenant id.
+func Validate(id string) error {
+	if !idPattern.MatchString(id) {
+		return ErrInvalidID
+	}
+	return nil
+}
+
+type ctxKey struct{}
+
+// WithID returns the context of the tenant.
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// FromContext returns the tenant of the context or the Default one.
+func FromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(ctxKey{}).(string); ok && id != "" {
+		return id
+	}
+	return Default
+}
@@ -0,0 +1,75 @@
+package tracing
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+)
+
+func setupRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	rec := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+	return rec
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := setupRecorder(t)
+
+	router := gin.New()
+	router.Use(Middleware())
+	router.GET("/subscription/:id", func(c *gin.Context) {
+		_, span := Start(c.Request.Context(), "SubscriptionService.GetByID")
+		err := errors.New("db is down")
+		End(span, &err)
+		c.Status(http.StatusInternalServerError)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/subscription/1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := rec.Ended()
+	require.Len(t, spans, 2)
+	child, server := spans[0], spans[1]
+
+	assert.Equal(t, "GET /subscription/:id", server.Name())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent().SpanID().String())
+	assert.Contains(t, server.Attributes(), semconv.HTTPRoute("/subscription/:id"))
+	assert.Contains(t, server.Attributes(), semconv.HTTPResponseStatusCode(http.StatusInternalServerError))
+	assert.Equal(t, codes.Error, server.Status().Code)
+
+	assert.Equal(t, "SubscriptionService.GetByID", child.Name())
+	assert.Equal(t, server.SpanContext().SpanID(), child.Parent().SpanID())
+	assert.Equal(t, codes.Error, child.Status().Code)
+	assert.Equal(t, "db is down", child.Status().Description)
+}
+
+func TestSetup(t *testing.T) {
+	shutdown, err := Setup(t.Context(), Config{Exporter: ExporterNone})
+	require.NoError(t, err)
+	assert.NoError(t, shutdown(t.Context()))
+
+	_, err = Setup(t.Context(), Config{Exporter: "zipkin"})
+	assert.ErrorIs(t, err, ErrUnknownExporter)
+}
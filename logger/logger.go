@@ -41,12 +41,14 @@ func InitLoggerByFlag(defLogLevel string, consoleOut bool) *zerolog.Logger {
 
 func initLogger(lvl zerolog.Level, consoleOut bool) *zerolog.Logger {
 	zerolog.SetGlobalLevel(lvl)
+	// Contexts without the request logger log through the global one.
+	zerolog.DefaultContextLogger = &log.Logger
 
 	if consoleOut {
-		log.Logger = log.Output(zerolog.ConsoleWriter{
+		log.Logger = log.Output(NewRedactWriter(zerolog.ConsoleWriter{
 			Out:        os.Stdout,
 			TimeFormat: time.RFC3339,
-		}).With().Timestamp().Logger()
+		})).With().Timestamp().Logger()
 	}
 
 	return &log.Logger
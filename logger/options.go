@@ -0,0 +1,97 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// Output formats of the logger.
+const (
+	FormatConsole = "console"
+	FormatJSON    = "json"
+)
+
+var ErrInvalidLevel = errors.New("invalid log level, expected one of: fatal, panic, error, warn, info, debug, trace")
+
+// Options configure the global logger: the level, console output for
+// people or JSON for log collectors, and an optional file rotated by
+// size and age. Values of RedactKeys fields are masked in all outputs.
+type Options struct {
+	Level  string
+	Format string
+
+	File           string
+	FileMaxSizeMB  int
+	FileMaxBackups int
+	FileMaxAgeDays int
+	FileCompress   bool
+
+	RedactKeys []string
+}
+
+// Init sets up the global logger by options. The returned function
+// closes the log file.
+func Init(opts Options) (logger *zerolog.Logger, closeFile func() error, err error) {
+	lvl, ok := filterStringLogLevel(opts.Level)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %q", ErrInvalidLevel, opts.Level)
+	}
+
+	var out io.Writer
+	switch opts.Format {
+	case "", FormatConsole:
+		out = zerolog.ConsoleWriter{Out: os.Stdout, TimeFormat: time.RFC3339}
+	case FormatJSON:
+		out = os.Stdout
+	default:
+		return nil, nil, fmt.Errorf("unknown log format %q, expected %s or %s", opts.Format, FormatConsole, FormatJSON)
+	}
+
+	closeFile = func() error { return nil }
+	if opts.File != "" {
+		file := &lumberjack.Logger{
+			Filename:   opts.File,
+			MaxSize:    opts.FileMaxSizeMB,
+			MaxBackups: opts.FileMaxBackups,
+			MaxAge:     opts.FileMaxAgeDays,
+			Compress:   opts.FileCompress,
+		}
+		// Files are read by collectors, so they are always JSON.
+		out = zerolog.MultiLevelWriter(out, file)
+		closeFile = file.Close
+	}
+
+	zerolog.SetGlobalLevel(lvl)
+	log.Logger = zerolog.New(NewRedactWriter(out, opts.RedactKeys...)).With().Timestamp().Logger()
+	zerolog.DefaultContextLogger = &log.Logger
+
+	return &log.Logger, closeFile, nil
+}
+
+// IsLevel reports, whether the level is known.
+func IsLevel(level string) bool {
+	_, ok := filterStringLogLevel(level)
+	return ok
+}
+
+// Level returns the current global log level.
+func Level() string {
+	return zerolog.GlobalLevel().String()
+}
+
+// SetLevel changes the global log level at runtime.
+func SetLevel(level string) error {
+	lvl, ok := filterStringLogLevel(level)
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrInvalidLevel, level)
+	}
+	zerolog.SetGlobalLevel(lvl)
+	return nil
+}
@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// Redacted replaces values of sensitive fields in logs.
+const Redacted = "[REDACTED]"
+
+// DefaultRedactKeys are names of fields, whose values are never logged:
+// secrets of the config (DB password, Basic Auth users, JWT secret) and
+// credentials of requests.
+var DefaultRedactKeys = []string{
+	"password", "secret", "users", "token", "authorization", "cookie", "api_key",
+}
+
+// redactWriter masks values of fields with sensitive names, matched case
+// insensitively, in JSON log lines at any depth, e.g. in a logged config.
+type redactWriter struct {
+	w    io.Writer
+	keys map[string]bool
+}
+
+// NewRedactWriter returns the writer masking DefaultRedactKeys and keys
+// in events written to w.
+func NewRedactWriter(w io.Writer, keys ...string) io.Writer {
+	r := &redactWriter{w: w, keys: make(map[string]bool)}
+	for _, k := range append(DefaultRedactKeys, keys...) {
+		r.keys[strings.ToLower(k)] = true
+	}
+	return r
+}
+
+func (r *redactWriter) Write(p []byte) (int, error) {
+	if !r.mayContain(p) {
+		return r.w.Write(p)
+	}
+
+	var event map[string]any
+	dec := json.NewDecoder(bytes.NewReader(p))
+	dec.UseNumber()
+	if err := dec.Decode(&event); err != nil || !r.redact(event) {
+		return r.w.Write(p)
+	}
+	out, err := json.Marshal(event)
+	if err != nil {
+		return r.w.Write(p)
+	}
+	if _, err = r.w.Write(append(out, '\n')); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// mayContain cheaply checks, whether the line has any sensitive name, so
+// most lines are written as is.
+func (r *redactWriter) mayContain(p []byte) bool {
+	lower := bytes.ToLower(p)
+	for k := range r.keys {
+		if bytes.Contains(lower, []byte(`"`+k+`"`)) {
+			return true
+		}
+	}
+	return false
+}
+
+// redact masks sensitive values in place and reports, whether any was.
+func (r *redactWriter) redact(v any) bool {
+	redacted := false
+	switch v := v.(type) {
+	case map[string]any:
+		for k, val := range v {
+			if r.keys[strings.ToLower(k)] {
+				if val != nil {
+					v[k] = Redacted
+				}
+				redacted = true
+				continue
+			}
+			redacted = r.redact(val) || redacted
+		}
+	case []any:
+		for _, val := range v {
+			redacted = r.redact(val) || redacted
+		}
+	}
+	return redacted
+}
@@ -0,0 +1,70 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactWriter(t *testing.T) {
+	type db struct {
+		Host     string
+		Password string
+	}
+	type config struct {
+		DB    db
+		Users []string
+	}
+
+	var buf bytes.Buffer
+	log := zerolog.New(NewRedactWriter(&buf, "card"))
+
+	tests := []struct {
+		name  string
+		event func()
+		want  string
+	}{
+		{
+			name:  "Ok (nothing to redact)",
+			event: func() { log.Info().Str("path", "/api/v1/subscription/").Int("status", 200).Msg("done") },
+			want:  `{"level":"info","path":"/api/v1/subscription/","status":200,"message":"done"}`,
+		},
+		{
+			name: "Ok (config)",
+			event: func() {
+				log.Info().Interface("config", config{DB: db{Host: "db", Password: "pg"}, Users: []string{"admin:secret"}}).Msg("starting")
+			},
+			want: `{"level":"info","config":{"DB":{"Host":"db","Password":"[REDACTED]"},"Users":"[REDACTED]"},"message":"starting"}`,
+		},
+		{
+			name:  "Ok (custom key)",
+			event: func() { log.Info().Str("Card", "4242").Str("authorization", "Basic YTpi").Msg("paid") },
+			want:  `{"level":"info","Card":"[REDACTED]","authorization":"[REDACTED]","message":"paid"}`,
+		},
+		{
+			name:  "Ok (value isn't a key)",
+			event: func() { log.Info().Str("field", "password").Msg("invalid") },
+			want:  `{"level":"info","field":"password","message":"invalid"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			tt.event()
+			assert.JSONEq(t, tt.want, buf.String())
+		})
+	}
+}
+
+func TestSetLevel(t *testing.T) {
+	prev := zerolog.GlobalLevel()
+	defer zerolog.SetGlobalLevel(prev)
+
+	assert.NoError(t, SetLevel("debug"))
+	assert.Equal(t, "debug", Level())
+	assert.ErrorIs(t, SetLevel("verbose"), ErrInvalidLevel)
+	assert.Equal(t, "debug", Level())
+}
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE services (
+    id serial PRIMARY KEY NOT NULL,
+    name varchar(120) NOT NULL,
+    name_key varchar(120) NOT NULL,
+    aliases text[] NOT NULL DEFAULT '{}',
+    alias_keys text[] NOT NULL DEFAULT '{}',
+    category varchar(120) NOT NULL DEFAULT '',
+    default_price integer CHECK (default_price >= 0) NOT NULL DEFAULT 0,
+    CONSTRAINT services_name_key_unique UNIQUE (name_key)
+);
+
+CREATE INDEX idx_services_alias_keys ON services USING gin (alias_keys);
+
+ALTER TABLE subscriptions
+    ADD COLUMN service_id integer REFERENCES services(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_subscriptions_service_id ON subscriptions(service_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_subscriptions_service_id;
+ALTER TABLE subscriptions DROP COLUMN service_id;
+DROP INDEX idx_services_alias_keys;
+DROP TABLE IF EXISTS services;
+-- +goose StatementEnd
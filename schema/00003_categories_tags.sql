@@ -0,0 +1,60 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE categories (
+    id serial PRIMARY KEY NOT NULL,
+    name varchar(120) NOT NULL,
+    CONSTRAINT categories_name_unique UNIQUE (name)
+);
+
+-- Move free-text categories of the catalog to the categories table.
+INSERT INTO categories (name)
+    SELECT DISTINCT category FROM services WHERE category <> '';
+
+ALTER TABLE services
+    ADD COLUMN category_id integer REFERENCES categories(id) ON DELETE SET NULL;
+
+UPDATE services SET category_id = categories.id
+    FROM categories WHERE categories.name = services.category;
+
+ALTER TABLE services DROP COLUMN category;
+
+ALTER TABLE subscriptions
+    ADD COLUMN category_id integer REFERENCES categories(id) ON DELETE SET NULL;
+
+UPDATE subscriptions SET category_id = services.category_id
+    FROM services WHERE services.id = subscriptions.service_id;
+
+CREATE INDEX idx_subscriptions_category_id ON subscriptions(category_id);
+
+CREATE TABLE tags (
+    id serial PRIMARY KEY NOT NULL,
+    user_id UUID NOT NULL,
+    name varchar(64) NOT NULL,
+    CONSTRAINT tags_user_id_name_unique UNIQUE (user_id, name)
+);
+
+CREATE TABLE subscription_tags (
+    subscription_id integer NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    tag_id integer NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (subscription_id, tag_id)
+);
+
+CREATE INDEX idx_subscription_tags_tag_id ON subscription_tags(tag_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_subscription_tags_tag_id;
+DROP TABLE IF EXISTS subscription_tags;
+DROP TABLE IF EXISTS tags;
+
+DROP INDEX idx_subscriptions_category_id;
+ALTER TABLE subscriptions DROP COLUMN category_id;
+
+ALTER TABLE services ADD COLUMN category varchar(120) NOT NULL DEFAULT '';
+UPDATE services SET category = categories.name
+    FROM categories WHERE categories.id = services.category_id;
+ALTER TABLE services DROP COLUMN category_id;
+
+DROP TABLE IF EXISTS categories;
+-- +goose StatementEnd
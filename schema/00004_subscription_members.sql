@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE subscription_members (
+    subscription_id integer NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+    share_percent numeric(5, 2) CHECK (share_percent > 0 AND share_percent <= 100),
+    share_amount integer CHECK (share_amount >= 0),
+    PRIMARY KEY (subscription_id, user_id),
+    CONSTRAINT subscription_members_one_share CHECK ((share_percent IS NULL) <> (share_amount IS NULL))
+);
+
+CREATE INDEX idx_subscription_members_user_id ON subscription_members(user_id);
+
+-- Share of every user in every subscription. Members pay their fixed amount
+-- or percent of the price, the owner pays the rest, unless the owner is
+-- listed as a member too.
+CREATE VIEW subscription_shares AS
+    SELECT m.subscription_id,
+        m.user_id AS share_user_id,
+        COALESCE(m.share_amount, round(s.monthly_price * m.share_percent / 100))::integer AS share_amount
+    FROM subscription_members AS m
+    JOIN subscriptions AS s ON s.id = m.subscription_id
+    UNION ALL
+    SELECT s.id AS subscription_id,
+        s.user_id AS share_user_id,
+        GREATEST(s.monthly_price - COALESCE((
+            SELECT sum(COALESCE(m.share_amount, round(s.monthly_price * m.share_percent / 100)))
+            FROM subscription_members AS m
+            WHERE m.subscription_id = s.id
+        ), 0), 0)::integer AS share_amount
+    FROM subscriptions AS s
+    WHERE NOT EXISTS (
+        SELECT 1 FROM subscription_members AS m
+        WHERE m.subscription_id = s.id AND m.user_id = s.user_id
+    );
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP VIEW IF EXISTS subscription_shares;
+DROP INDEX idx_subscription_members_user_id;
+DROP TABLE IF EXISTS subscription_members;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+-- A user can subscribe to the same service again after cancelling,
+-- only the periods of such subscriptions must not overlap.
+-- Subscription periods are half-open, so a new one may start
+-- on the same day the previous one ends.
+ALTER TABLE subscriptions DROP CONSTRAINT subscriptions_user_id_service_name_key;
+ALTER TABLE subscriptions ADD CONSTRAINT subscriptions_period_no_overlap EXCLUDE USING gist (
+    user_id WITH =,
+    service_name WITH =,
+    tsrange(start_date, end_date) WITH &&
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE subscriptions DROP CONSTRAINT subscriptions_period_no_overlap;
+ALTER TABLE subscriptions ADD CONSTRAINT subscriptions_user_id_service_name_key UNIQUE (user_id, service_name);
+DROP EXTENSION IF EXISTS btree_gist;
+-- +goose StatementEnd
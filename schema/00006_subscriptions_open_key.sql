@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The open subscription (without end date) of a user to a service is unique
+-- already by subscriptions_period_no_overlap, the index makes it an arbiter
+-- for INSERT ... ON CONFLICT, which doesn't support exclusion constraints.
+CREATE UNIQUE INDEX subscriptions_open_key ON subscriptions (user_id, service_name) WHERE end_date IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS subscriptions_open_key;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Responses of mutating requests made with the Idempotency-Key header,
+-- status is NULL while the first request is in progress.
+CREATE TABLE idempotency_keys (
+    scope        TEXT NOT NULL DEFAULT '',
+    key          VARCHAR(255) NOT NULL,
+    method       VARCHAR(16) NOT NULL,
+    path         TEXT NOT NULL,
+    request_hash CHAR(64) NOT NULL,
+    status       INTEGER,
+    content_type TEXT NOT NULL DEFAULT '',
+    body         BYTEA,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at   TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (scope, key)
+);
+CREATE INDEX idempotency_keys_expires_at_idx ON idempotency_keys (expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS idempotency_keys;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Long-lived machine credentials. Only SHA-256 hashes of keys are stored,
+-- prefix identifies the key to people. Keys without user act on any user.
+CREATE TABLE api_keys (
+    id         BIGSERIAL PRIMARY KEY,
+    name       TEXT NOT NULL,
+    prefix     VARCHAR(16) NOT NULL,
+    key_hash   CHAR(64) NOT NULL,
+    user_id    UUID,
+    scopes     TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    revoked_at TIMESTAMPTZ,
+    CONSTRAINT api_keys_key_hash_unique UNIQUE (key_hash)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS api_keys;
+-- +goose StatementEnd
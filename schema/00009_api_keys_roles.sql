@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Roles of keys checked by the access policy, keys without roles get the default one.
+ALTER TABLE api_keys ADD COLUMN roles TEXT[] NOT NULL DEFAULT '{}';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE api_keys DROP COLUMN IF EXISTS roles;
+-- +goose StatementEnd
@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Data of tenants is isolated, existing rows belong to the default tenant.
+-- Uniqueness of subscription periods and keys holds within a tenant.
+ALTER TABLE subscriptions ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+
+ALTER TABLE subscriptions DROP CONSTRAINT subscriptions_period_no_overlap;
+ALTER TABLE subscriptions ADD CONSTRAINT subscriptions_period_no_overlap EXCLUDE USING gist (
+    tenant_id WITH =,
+    user_id WITH =,
+    service_name WITH =,
+    tsrange(start_date, end_date) WITH &&
+);
+
+DROP INDEX subscriptions_open_key;
+CREATE UNIQUE INDEX subscriptions_open_key ON subscriptions (tenant_id, user_id, service_name) WHERE end_date IS NULL;
+
+DROP INDEX idx_subscriptions_user_id_service_name;
+CREATE INDEX idx_subscriptions_tenant_id_user_id_service_name ON subscriptions (tenant_id, user_id, service_name);
+
+ALTER TABLE api_keys ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE api_keys DROP COLUMN IF EXISTS tenant_id;
+
+DROP INDEX idx_subscriptions_tenant_id_user_id_service_name;
+CREATE INDEX idx_subscriptions_user_id_service_name ON subscriptions (user_id, service_name);
+
+DROP INDEX subscriptions_open_key;
+CREATE UNIQUE INDEX subscriptions_open_key ON subscriptions (user_id, service_name) WHERE end_date IS NULL;
+
+ALTER TABLE subscriptions DROP CONSTRAINT subscriptions_period_no_overlap;
+ALTER TABLE subscriptions ADD CONSTRAINT subscriptions_period_no_overlap EXCLUDE USING gist (
+    user_id WITH =,
+    service_name WITH =,
+    tsrange(start_date, end_date) WITH &&
+);
+
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS tenant_id;
+-- +goose StatementEnd
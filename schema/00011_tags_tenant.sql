@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Tags belong to tenants like subscriptions they are attached to, existing
+-- tags belong to the default tenant.
+ALTER TABLE tags ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+
+ALTER TABLE tags DROP CONSTRAINT tags_user_id_name_unique;
+ALTER TABLE tags ADD CONSTRAINT tags_tenant_id_user_id_name_unique UNIQUE (tenant_id, user_id, name);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Tags of the same user and name in different tenants can't be merged back,
+-- so only tags of the default tenant are kept.
+DELETE FROM tags WHERE tenant_id <> 'default';
+
+ALTER TABLE tags DROP CONSTRAINT tags_tenant_id_user_id_name_unique;
+ALTER TABLE tags ADD CONSTRAINT tags_user_id_name_unique UNIQUE (user_id, name);
+
+ALTER TABLE tags DROP COLUMN IF EXISTS tenant_id;
+-- +goose StatementEnd
@@ -0,0 +1,47 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Shares of members exceeding the price are surfaced as a negative share of
+-- the owner, instead of being hidden, so sums of shares always equal prices.
+CREATE OR REPLACE VIEW subscription_shares AS
+    SELECT m.subscription_id,
+        m.user_id AS share_user_id,
+        COALESCE(m.share_amount, round(s.monthly_price * m.share_percent / 100))::integer AS share_amount
+    FROM subscription_members AS m
+    JOIN subscriptions AS s ON s.id = m.subscription_id
+    UNION ALL
+    SELECT s.id AS subscription_id,
+        s.user_id AS share_user_id,
+        (s.monthly_price - COALESCE((
+            SELECT sum(COALESCE(m.share_amount, round(s.monthly_price * m.share_percent / 100)))
+            FROM subscription_members AS m
+            WHERE m.subscription_id = s.id
+        ), 0))::integer AS share_amount
+    FROM subscriptions AS s
+    WHERE NOT EXISTS (
+        SELECT 1 FROM subscription_members AS m
+        WHERE m.subscription_id = s.id AND m.user_id = s.user_id
+    );
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE VIEW subscription_shares AS
+    SELECT m.subscription_id,
+        m.user_id AS share_user_id,
+        COALESCE(m.share_amount, round(s.monthly_price * m.share_percent / 100))::integer AS share_amount
+    FROM subscription_members AS m
+    JOIN subscriptions AS s ON s.id = m.subscription_id
+    UNION ALL
+    SELECT s.id AS subscription_id,
+        s.user_id AS share_user_id,
+        GREATEST(s.monthly_price - COALESCE((
+            SELECT sum(COALESCE(m.share_amount, round(s.monthly_price * m.share_percent / 100)))
+            FROM subscription_members AS m
+            WHERE m.subscription_id = s.id
+        ), 0), 0)::integer AS share_amount
+    FROM subscriptions AS s
+    WHERE NOT EXISTS (
+        SELECT 1 FROM subscription_members AS m
+        WHERE m.subscription_id = s.id AND m.user_id = s.user_id
+    );
+-- +goose StatementEnd
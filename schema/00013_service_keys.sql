@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Lookup keys of names and aliases of all services, so a key can't refer
+-- to more than one service even when services are written concurrently.
+CREATE TABLE service_keys (
+    key text NOT NULL,
+    service_id integer NOT NULL REFERENCES services(id) ON DELETE CASCADE,
+    CONSTRAINT service_keys_key_unique UNIQUE (key)
+);
+
+CREATE INDEX idx_service_keys_service_id ON service_keys(service_id);
+
+-- Keys already shared by several services are kept by the oldest one.
+INSERT INTO service_keys (key, service_id)
+SELECT k.key, services.id
+FROM services, unnest(array_prepend(services.name_key, services.alias_keys)) AS k(key)
+ORDER BY services.id
+ON CONFLICT (key) DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS service_keys;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Keys without user were admins regardless of roles, now admins are keys
+-- with the admin role, so keys, which had no roles, keep acting as admins.
+UPDATE api_keys SET roles = '{admin}' WHERE user_id IS NULL AND roles = '{}';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+UPDATE api_keys SET roles = '{}' WHERE user_id IS NULL AND roles = '{admin}';
+-- +goose StatementEnd
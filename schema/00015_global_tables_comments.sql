@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The catalog and categories are global, shared by all tenants and changed
+-- by admins only. Members and tags of subscriptions are isolated by tenants
+-- of their subscriptions.
+COMMENT ON TABLE services IS 'Catalog of services, global for all tenants';
+COMMENT ON TABLE service_keys IS 'Lookup keys of names and aliases of services, global for all tenants';
+COMMENT ON TABLE categories IS 'Categories of services and subscriptions, global for all tenants';
+COMMENT ON TABLE subscription_members IS 'Members of subscriptions, belong to the tenant of the subscription';
+COMMENT ON TABLE subscription_tags IS 'Tags of subscriptions, belong to the tenant of the subscription';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+COMMENT ON TABLE services IS NULL;
+COMMENT ON TABLE service_keys IS NULL;
+COMMENT ON TABLE categories IS NULL;
+COMMENT ON TABLE subscription_members IS NULL;
+COMMENT ON TABLE subscription_tags IS NULL;
+-- +goose StatementEnd
@@ -8,11 +8,29 @@ import (
 
 type UserID = storage.UserID
 type SubscriptionID = storage.SubscriptionID
+type ServiceID = storage.ServiceID
+type CategoryID = storage.CategoryID
+type TagID = storage.TagID
 type Price = storage.Price
+type APIKeyID = storage.APIKeyID
 
 /* ---- Subscription Type ---- */
 type Subscription = storage.Subscription
 
+/* ---- Service Catalog Type ---- */
+type Service = storage.Service
+
+/* ---- Categories and Tags Types ---- */
+type Category = storage.Category
+type CategorySum = storage.CategorySum
+type Tag = storage.Tag
+
+/* ---- Subscription Member Type ---- */
+type Member = storage.Member
+
+/* ---- API Key Type ---- */
+type APIKey = storage.APIKey
+
 type QueryArgs = storage.QueryArgs
 
 type Date = storage.Date
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/auth"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/config"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/service"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/storage/postgresql"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/internal/tenant"
+	"github.com/ikotiki/go-rest-api-service-subscriptions/logger"
+
+	_ "github.com/joho/godotenv/autoload"
+)
+
+// mappingFlag collects repeated -map field=column flags.
+type mappingFlag map[string]string
+
+func (m mappingFlag) String() string { return "" }
+
+func (m mappingFlag) Set(pair string) error {
+	field, column, ok := strings.Cut(pair, "=")
+	if !ok || field == "" || column == "" {
+		return errors.New("should be field=column")
+	}
+	m[field] = column
+	return nil
+}
+
+// Imports subscriptions from a CSV file, the same way as
+// POST /subscription/import does, and prints the report as JSON.
+//
+//	go run ./tools/import -file subs.csv -map user_id=User -date-format 02.01.2006 -dry-run
+func main() {
+	mapping := mappingFlag{}
+	file := flag.String("file", "-", "CSV file to import, - for stdin")
+	flag.Var(mapping, "map", "column mapping as field=column, can be repeated")
+	dateFormat := flag.String("date-format", service.DefaultImportDateFormat, "Go layout of dates")
+	dryRun := flag.Bool("dry-run", false, "check rows without creating subscriptions")
+	batchSize := flag.Int("batch", service.DefaultImportBatchSize, "rows created within a single transaction")
+	tenantID := flag.String("tenant", tenant.Default, "tenant of imported subscriptions")
+	flag.Parse()
+
+	cfg := config.MustLoad()
+	log := logger.InitLoggerByFlag("info", true)
+
+	var in io.Reader = os.Stdin
+	if *file != "-" {
+		f, err := os.Open(*file)
+		if err != nil {
+			log.Fatal().Err(err).Msg("can't open file")
+		}
+		defer f.Close()
+		in = f
+	}
+
+	pgdb, err := postgresql.NewSQLStorage(postgresql.Config{
+		Host:     cfg.DB.Host,
+		Port:     cfg.DB.Port,
+		Username: cfg.DB.Username,
+		Password: cfg.DB.Password,
+		DBName:   cfg.DB.DBname,
+		SSLMode:  cfg.DB.SSLMode,
+
+		IsolationLevel: cfg.DB.IsolationLevel,
+		TxMaxRetries:   cfg.DB.TxMaxRetries,
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("error connecting to database")
+	}
+	// The tool doesn't issue API keys, so the policy only completes the service.
+	srv := service.NewService(postgresql.NewStorage(pgdb), auth.DefaultPolicy())
+
+	if err = tenant.Validate(*tenantID); err != nil {
+		log.Fatal().Err(err).Msg("invalid tenant")
+	}
+
+	ctx, stop := signal.NotifyContext(tenant.WithID(context.Background(), *tenantID), os.Interrupt)
+	defer stop()
+
+	report, err := srv.Import(ctx, in, &service.ImportOptions{
+		Mapping:    mapping,
+		DateFormat: *dateFormat,
+		DryRun:     *dryRun,
+		BatchSize:  *batchSize,
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("error importing subscriptions")
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err = enc.Encode(report); err != nil {
+		log.Fatal().Err(err).Msg("error writing report")
+	}
+}
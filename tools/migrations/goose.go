@@ -26,7 +26,7 @@ func main() {
 	case "down":
 		err = goose.Down(db, cfg.DB.MigrationDir)
 	default:
-		log.Fatal().Msgf("Available commands: up, down: %s, provided: %s", os.Args[1])
+		log.Fatal().Msgf("Available commands: up, down, provided: %s", os.Args[1])
 	}
 	if err != nil {
 		log.Fatal().Msgf("Error running migration: %s", err)